@@ -0,0 +1,315 @@
+// Package age 实现 age v1 文件格式（age-encryption.org/v1），
+// 支持 X25519 与 scrypt 口令接收方，用于与 age 工具互通
+package age
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Intro age 文件首行（含换行）
+const Intro = "age-encryption.org/v1\n"
+
+const (
+	// fileKeySize 文件密钥长度
+	fileKeySize = 16
+
+	// columnsPerLine 节正文 base64 每行字符数
+	columnsPerLine = 64
+
+	// maxHeaderSize 头部最大长度，防止恶意文件耗尽内存
+	maxHeaderSize = 1 << 20
+)
+
+var b64 = base64.RawStdEncoding.Strict()
+
+// ErrIncorrectIdentity 身份与该节不匹配，继续尝试其他节
+var ErrIncorrectIdentity = errors.New("incorrect identity for recipient block")
+
+// ErrNoIdentityMatch 没有身份能解开任何接收方节
+var ErrNoIdentityMatch = errors.New("no identity matched any of the recipients")
+
+// Stanza 头部中的接收方节
+type Stanza struct {
+	Type string
+	Args []string
+	Body []byte
+}
+
+// Recipient 接收方：用文件密钥生成头部节
+type Recipient interface {
+	Wrap(fileKey []byte) ([]*Stanza, error)
+}
+
+// Identity 身份：从头部节解出文件密钥，不匹配时返回 ErrIncorrectIdentity
+type Identity interface {
+	Unwrap(stanzas []*Stanza) ([]byte, error)
+}
+
+// Encrypt 将 age 头部写入 dst，返回用于写入明文的 WriteCloser，Close 后文件完整
+func Encrypt(dst io.Writer, recipients ...Recipient) (io.WriteCloser, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipients specified")
+	}
+
+	fileKey := make([]byte, fileKeySize)
+	if _, err := rand.Read(fileKey); err != nil {
+		return nil, err
+	}
+
+	var stanzas []*Stanza
+	for _, recipient := range recipients {
+		wrapped, err := recipient.Wrap(fileKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap key for recipient: %w", err)
+		}
+		stanzas = append(stanzas, wrapped...)
+	}
+
+	// scrypt 节必须是唯一的接收方节
+	for _, stanza := range stanzas {
+		if stanza.Type == scryptStanzaType && len(stanzas) != 1 {
+			return nil, errors.New("a passphrase recipient cannot be combined with other recipients")
+		}
+	}
+
+	var header bytes.Buffer
+	header.WriteString(Intro)
+	for _, stanza := range stanzas {
+		if err := writeStanza(&header, stanza); err != nil {
+			return nil, err
+		}
+	}
+	header.WriteString("---")
+	mac, err := headerMAC(fileKey, header.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	header.WriteString(" " + b64.EncodeToString(mac) + "\n")
+
+	if _, err := dst.Write(header.Bytes()); err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, streamNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	if _, err := dst.Write(nonce); err != nil {
+		return nil, err
+	}
+
+	payloadKey, err := hkdf.Key(sha256.New, fileKey, nonce, "payload", chachaKeySize)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamWriter(payloadKey, dst)
+}
+
+// Decrypt 解析 age 头部并用身份解出文件密钥，返回明文流
+func Decrypt(src io.Reader, identities ...Identity) (io.Reader, error) {
+	if len(identities) == 0 {
+		return nil, errors.New("no identities specified")
+	}
+
+	br := bufio.NewReader(src)
+	stanzas, headerNoMAC, mac, err := parseHeader(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	// scrypt 节必须单独出现，避免口令文件被附加其他接收方
+	for _, stanza := range stanzas {
+		if stanza.Type == scryptStanzaType && len(stanzas) != 1 {
+			return nil, errors.New("a scrypt recipient must be the only one in the header")
+		}
+	}
+
+	var fileKey []byte
+	for _, identity := range identities {
+		fileKey, err = identity.Unwrap(stanzas)
+		if errors.Is(err, ErrIncorrectIdentity) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+	if fileKey == nil {
+		return nil, ErrNoIdentityMatch
+	}
+
+	expected, err := headerMAC(fileKey, headerNoMAC)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(expected, mac) {
+		return nil, errors.New("bad header MAC")
+	}
+
+	nonce := make([]byte, streamNonceSize)
+	if _, err := io.ReadFull(br, nonce); err != nil {
+		return nil, fmt.Errorf("failed to read payload nonce: %w", err)
+	}
+	payloadKey, err := hkdf.Key(sha256.New, fileKey, nonce, "payload", chachaKeySize)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamReader(payloadKey, br)
+}
+
+// IsAge 检查数据是否以 age 首行开头
+func IsAge(prefix []byte) bool {
+	return bytes.HasPrefix(prefix, []byte(Intro))
+}
+
+// ReadFileStanzas 读取文件头部的接收方节，不是 age 文件时返回 nil
+func ReadFileStanzas(path string) ([]*Stanza, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	br := bufio.NewReader(file)
+	if prefix, _ := br.Peek(len(Intro)); !IsAge(prefix) {
+		return nil, nil
+	}
+	stanzas, _, _, err := parseHeader(br)
+	return stanzas, err
+}
+
+// headerMAC HMAC-SHA256(HKDF(fileKey, "header"), header)
+func headerMAC(fileKey, header []byte) ([]byte, error) {
+	key, err := hkdf.Key(sha256.New, fileKey, nil, "header", 32)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write(header)
+	return h.Sum(nil), nil
+}
+
+// writeStanza 写入一个接收方节，正文按 64 列换行，最后一行不足 64 列（可为空）
+func writeStanza(w *bytes.Buffer, stanza *Stanza) error {
+	if !isValidArg(stanza.Type) {
+		return fmt.Errorf("invalid stanza type %q", stanza.Type)
+	}
+	w.WriteString("-> " + stanza.Type)
+	for _, arg := range stanza.Args {
+		if !isValidArg(arg) {
+			return fmt.Errorf("invalid stanza argument %q", arg)
+		}
+		w.WriteString(" " + arg)
+	}
+	w.WriteString("\n")
+
+	body := b64.EncodeToString(stanza.Body)
+	for len(body) >= columnsPerLine {
+		w.WriteString(body[:columnsPerLine] + "\n")
+		body = body[columnsPerLine:]
+	}
+	w.WriteString(body + "\n")
+	return nil
+}
+
+// parseHeader 解析头部，返回接收方节、参与 MAC 计算的头部内容和 MAC
+func parseHeader(br *bufio.Reader) ([]*Stanza, []byte, []byte, error) {
+	var raw bytes.Buffer
+	readLine := func() (string, error) {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return "", err
+		}
+		raw.WriteString(line)
+		if raw.Len() > maxHeaderSize {
+			return "", errors.New("header too large")
+		}
+		return strings.TrimSuffix(line, "\n"), nil
+	}
+
+	intro, err := readLine()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if intro+"\n" != Intro {
+		return nil, nil, nil, fmt.Errorf("unexpected intro: %q", intro)
+	}
+
+	var stanzas []*Stanza
+	line, err := readLine()
+	for err == nil {
+		if rest, ok := strings.CutPrefix(line, "--- "); ok {
+			mac, err := b64.DecodeString(rest)
+			if err != nil || len(mac) != sha256.Size {
+				return nil, nil, nil, errors.New("malformed header MAC")
+			}
+			headerNoMAC := raw.Bytes()[:raw.Len()-len(line)-1+len("---")]
+			if len(stanzas) == 0 {
+				return nil, nil, nil, errors.New("no recipient stanzas")
+			}
+			return stanzas, headerNoMAC, mac, nil
+		}
+
+		rest, ok := strings.CutPrefix(line, "-> ")
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("malformed stanza line: %q", line)
+		}
+		args := strings.Split(rest, " ")
+		for _, arg := range args {
+			if !isValidArg(arg) {
+				return nil, nil, nil, fmt.Errorf("malformed stanza line: %q", line)
+			}
+		}
+		stanza := &Stanza{Type: args[0], Args: args[1:]}
+
+		// 正文按 64 列换行，以不足 64 列的行结束
+		for {
+			var bodyLine string
+			if bodyLine, err = readLine(); err != nil {
+				break
+			}
+			decoded, decodeErr := b64.DecodeString(bodyLine)
+			if decodeErr != nil || len(bodyLine) > columnsPerLine {
+				return nil, nil, nil, errors.New("malformed stanza body")
+			}
+			stanza.Body = append(stanza.Body, decoded...)
+			if len(bodyLine) < columnsPerLine {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+		stanzas = append(stanzas, stanza)
+		line, err = readLine()
+	}
+	return nil, nil, nil, err
+}
+
+// isValidArg 参数由非空的可见 ASCII 字符组成
+func isValidArg(arg string) bool {
+	if arg == "" {
+		return false
+	}
+	for i := 0; i < len(arg); i++ {
+		if arg[i] < 33 || arg[i] > 126 {
+			return false
+		}
+	}
+	return true
+}
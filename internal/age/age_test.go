@@ -0,0 +1,234 @@
+package age
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+// testdataPassphrase testdata/scrypt.age 的口令
+const testdataPassphrase = "correct horse battery staple"
+
+func readIdentityFile(t *testing.T, path string) *X25519Identity {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer file.Close()
+	identities, err := ParseIdentities(file)
+	if err != nil || len(identities) != 1 {
+		t.Fatalf("ParseIdentities(%s): %d identities, %v", path, len(identities), err)
+	}
+	return identities[0].(*X25519Identity)
+}
+
+func encryptTest(t *testing.T, plaintext []byte, recipients ...Recipient) []byte {
+	t.Helper()
+
+	var out bytes.Buffer
+	w, err := Encrypt(&out, recipients...)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return out.Bytes()
+}
+
+func decryptTest(ciphertext []byte, identities ...Identity) ([]byte, error) {
+	r, err := Decrypt(bytes.NewReader(ciphertext), identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestX25519RoundTrip(t *testing.T) {
+	identity, err := GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity failed: %v", err)
+	}
+	other, _ := GenerateX25519Identity()
+
+	// 接收方与身份的文本编码可往返解析
+	parsed, err := ParseX25519Identity(identity.String())
+	if err != nil || parsed.Recipient().String() != identity.Recipient().String() {
+		t.Fatalf("identity encoding does not round-trip: %v", err)
+	}
+	if _, err := ParseX25519Recipient(identity.Recipient().String()); err != nil {
+		t.Fatalf("ParseX25519Recipient failed: %v", err)
+	}
+
+	sizes := []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 17}
+	for _, size := range sizes {
+		plaintext := bytes.Repeat([]byte{0xA5}, size)
+		ciphertext := encryptTest(t, plaintext, other.Recipient(), identity.Recipient())
+
+		decrypted, err := decryptTest(ciphertext, identity)
+		if err != nil {
+			t.Fatalf("size %d: decrypt failed: %v", size, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("size %d: plaintext mismatch", size)
+		}
+
+		// 截断的载荷必须被拒绝
+		if size > 0 {
+			if _, err := decryptTest(ciphertext[:len(ciphertext)-1], identity); err == nil {
+				t.Errorf("size %d: truncated payload accepted", size)
+			}
+		}
+	}
+}
+
+func TestScryptRoundTrip(t *testing.T) {
+	recipient, err := NewScryptRecipient([]byte("correct horse"))
+	if err != nil {
+		t.Fatalf("NewScryptRecipient failed: %v", err)
+	}
+	recipient.SetWorkFactor(10)
+	ciphertext := encryptTest(t, []byte("hello age"), recipient)
+
+	identity, _ := NewScryptIdentity([]byte("correct horse"))
+	decrypted, err := decryptTest(ciphertext, identity)
+	if err != nil || string(decrypted) != "hello age" {
+		t.Fatalf("decrypt failed: %v", err)
+	}
+
+	wrong, _ := NewScryptIdentity([]byte("battery staple"))
+	if _, err := decryptTest(ciphertext, wrong); err == nil {
+		t.Error("wrong passphrase accepted")
+	}
+
+	// 口令接收方不能与其他接收方混用
+	identityX, _ := GenerateX25519Identity()
+	if _, err := Encrypt(io.Discard, recipient, identityX.Recipient()); err == nil {
+		t.Error("scrypt recipient combined with X25519 recipient")
+	}
+}
+
+func TestDecryptRejects(t *testing.T) {
+	identity, _ := GenerateX25519Identity()
+	stranger, _ := GenerateX25519Identity()
+	ciphertext := encryptTest(t, []byte("secret"), identity.Recipient())
+
+	// 篡改头部节参数后 MAC 或解封装失败
+	tampered := append([]byte{}, ciphertext...)
+	tampered[len(Intro)+3] ^= 0x01
+
+	testCases := []struct {
+		name       string
+		ciphertext []byte
+		identity   Identity
+		wantErr    error
+	}{
+		{"wrong identity", ciphertext, stranger, ErrNoIdentityMatch},
+		{"tampered header", tampered, identity, nil},
+		{"not age", []byte("HYCRYPT\x00"), identity, nil},
+	}
+	for _, tc := range testCases {
+		_, err := decryptTest(tc.ciphertext, tc.identity)
+		if err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		} else if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: got %v, expected %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestKnownIdentity(t *testing.T) {
+	// age 测试用例中的固定身份（私钥为 32 个 0x42 字节）
+	identity, err := ParseX25519Identity("AGE-SECRET-KEY-1GFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPQ4EGAEX")
+	if err != nil {
+		t.Fatalf("ParseX25519Identity failed: %v", err)
+	}
+	expected := "age1zvkyg2lqzraa2lnjvqej32nkuu0ues2s82hzrye869xeexvn73equnujwj"
+	if got := identity.Recipient().String(); got != expected {
+		t.Errorf("recipient = %s, expected %s", got, expected)
+	}
+}
+
+func TestInteropFixtures(t *testing.T) {
+	// x25519.age 由 age v1.2.1 命令行生成，scrypt.age 由 filippo.io/age v1.2.1 以 log2(N)=10 生成
+	plaintext, err := os.ReadFile("testdata/plaintext.txt")
+	if err != nil {
+		t.Fatalf("read plaintext: %v", err)
+	}
+	scrypt, err := NewScryptIdentity([]byte(testdataPassphrase))
+	if err != nil {
+		t.Fatalf("NewScryptIdentity failed: %v", err)
+	}
+
+	testCases := []struct {
+		file     string
+		identity Identity
+	}{
+		{"testdata/x25519.age", readIdentityFile(t, "testdata/x25519.key")},
+		{"testdata/scrypt.age", scrypt},
+		{"testdata/hycrypt-x25519.age", readIdentityFile(t, "testdata/x25519.key")},
+	}
+	for _, tc := range testCases {
+		ciphertext, err := os.ReadFile(tc.file)
+		if err != nil {
+			t.Fatalf("read %s: %v", tc.file, err)
+		}
+		decrypted, err := decryptTest(ciphertext, tc.identity)
+		if err != nil {
+			t.Fatalf("%s: decrypt failed: %v", tc.file, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("%s: decrypted %q, expected %q", tc.file, decrypted, plaintext)
+		}
+	}
+}
+
+func TestHeaderFixture(t *testing.T) {
+	// hycrypt-x25519.age 由本包生成并经 age v1.2.1 命令行解密验证，
+	// 重新序列化的接收方节和头部 MAC 必须与之逐字节一致
+	ciphertext, err := os.ReadFile("testdata/hycrypt-x25519.age")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	stanzas, headerNoMAC, mac, err := parseHeader(bufio.NewReader(bytes.NewReader(ciphertext)))
+	if err != nil {
+		t.Fatalf("parseHeader failed: %v", err)
+	}
+
+	if len(stanzas) != 1 || stanzas[0].Type != x25519StanzaType || len(stanzas[0].Args) != 1 ||
+		len(stanzas[0].Body) != fileKeySize+tagSize {
+		t.Fatalf("unexpected stanza layout: %+v", stanzas)
+	}
+
+	var header bytes.Buffer
+	header.WriteString(Intro)
+	for _, stanza := range stanzas {
+		if err := writeStanza(&header, stanza); err != nil {
+			t.Fatalf("writeStanza failed: %v", err)
+		}
+	}
+	header.WriteString("---")
+	if !bytes.Equal(header.Bytes(), headerNoMAC) {
+		t.Errorf("serialized header differs from fixture:\n%q\n%q", header.Bytes(), headerNoMAC)
+	}
+
+	fileKey, err := readIdentityFile(t, "testdata/x25519.key").Unwrap(stanzas)
+	if err != nil {
+		t.Fatalf("Unwrap failed: %v", err)
+	}
+	expected, err := headerMAC(fileKey, header.Bytes())
+	if err != nil {
+		t.Fatalf("headerMAC failed: %v", err)
+	}
+	if !bytes.Equal(expected, mac) {
+		t.Errorf("header MAC = %x, fixture has %x", expected, mac)
+	}
+}
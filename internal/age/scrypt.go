@@ -0,0 +1,120 @@
+package age
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	scryptStanzaType = "scrypt"
+	scryptLabel      = "age-encryption.org/v1/scrypt"
+	scryptSaltSize   = 16
+
+	// DefaultScryptWorkFactor 加密时的默认 log2(N)
+	DefaultScryptWorkFactor = 18
+
+	// MaxScryptWorkFactor 解密时接受的最大 log2(N)，防止恶意文件消耗过量内存
+	MaxScryptWorkFactor = 22
+)
+
+// ScryptRecipient 口令接收方，必须是文件的唯一接收方
+type ScryptRecipient struct {
+	passphrase []byte
+	workFactor int
+}
+
+// NewScryptRecipient 创建口令接收方
+func NewScryptRecipient(passphrase []byte) (*ScryptRecipient, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	return &ScryptRecipient{passphrase: passphrase, workFactor: DefaultScryptWorkFactor}, nil
+}
+
+// SetWorkFactor 设置 log2(N)，用于测试或低性能设备
+func (r *ScryptRecipient) SetWorkFactor(logN int) {
+	if logN < 1 || logN > 30 {
+		panic("age: invalid scrypt work factor")
+	}
+	r.workFactor = logN
+}
+
+// Wrap 用 scrypt 派生的密钥封装文件密钥
+func (r *ScryptRecipient) Wrap(fileKey []byte) ([]*Stanza, error) {
+	salt := make([]byte, scryptSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	wrapKey, err := scryptKey(r.passphrase, salt, r.workFactor)
+	if err != nil {
+		return nil, err
+	}
+	body, err := aeadWrap(wrapKey, fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{b64.EncodeToString(salt), strconv.Itoa(r.workFactor)}
+	return []*Stanza{{Type: scryptStanzaType, Args: args, Body: body}}, nil
+}
+
+// ScryptIdentity 口令身份
+type ScryptIdentity struct {
+	passphrase    []byte
+	maxWorkFactor int
+}
+
+// NewScryptIdentity 创建口令身份
+func NewScryptIdentity(passphrase []byte) (*ScryptIdentity, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	return &ScryptIdentity{passphrase: passphrase, maxWorkFactor: MaxScryptWorkFactor}, nil
+}
+
+// Unwrap 解开 scrypt 节，口令错误时返回错误而非 ErrIncorrectIdentity
+func (i *ScryptIdentity) Unwrap(stanzas []*Stanza) ([]byte, error) {
+	for _, stanza := range stanzas {
+		if stanza.Type != scryptStanzaType {
+			continue
+		}
+		if len(stanza.Args) != 2 {
+			return nil, errors.New("invalid scrypt recipient block")
+		}
+		salt, err := b64.DecodeString(stanza.Args[0])
+		if err != nil || len(salt) != scryptSaltSize {
+			return nil, errors.New("invalid scrypt recipient block")
+		}
+		logN, err := strconv.Atoi(stanza.Args[1])
+		if err != nil || strconv.Itoa(logN) != stanza.Args[1] || logN <= 0 {
+			return nil, errors.New("invalid scrypt work factor")
+		}
+		if logN > i.maxWorkFactor {
+			return nil, fmt.Errorf("scrypt work factor too large: %d", logN)
+		}
+		if len(stanza.Body) != fileKeySize+16 {
+			return nil, errors.New("invalid scrypt recipient block")
+		}
+
+		wrapKey, err := scryptKey(i.passphrase, salt, logN)
+		if err != nil {
+			return nil, err
+		}
+		fileKey, err := aeadUnwrap(wrapKey, stanza.Body)
+		if err != nil {
+			return nil, errors.New("incorrect passphrase")
+		}
+		return fileKey, nil
+	}
+	return nil, ErrIncorrectIdentity
+}
+
+func scryptKey(passphrase, salt []byte, logN int) ([]byte, error) {
+	labeled := append([]byte(scryptLabel), salt...)
+	return scrypt.Key(passphrase, labeled, 1<<logN, 8, 1, chachaKeySize)
+}
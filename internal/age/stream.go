@@ -0,0 +1,160 @@
+package age
+
+import (
+	"crypto/cipher"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// STREAM 载荷：64 KiB 分块 ChaCha20-Poly1305，nonce 为 11 字节大端计数器 + 末块标志
+const (
+	chunkSize       = 64 * 1024
+	chachaKeySize   = chacha20poly1305.KeySize
+	streamNonceSize = 16
+	tagSize         = chacha20poly1305.Overhead
+	lastChunkFlag   = 0x01
+)
+
+// chunkNonce 构造第 counter 个分块的 nonce
+func chunkNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	for i := 10; i >= 0; i-- {
+		nonce[i] = byte(counter)
+		counter >>= 8
+	}
+	if last {
+		nonce[11] = lastChunkFlag
+	}
+	return nonce
+}
+
+// streamWriter 分块加密写入器，缓冲区满且还有后续数据时才写出，保证末块非空
+type streamWriter struct {
+	aead    cipher.AEAD
+	dst     io.Writer
+	buf     []byte
+	counter uint64
+	closed  bool
+}
+
+func newStreamWriter(key []byte, dst io.Writer) (*streamWriter, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return &streamWriter{aead: aead, dst: dst, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed age writer")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		if len(w.buf) == chunkSize {
+			if err := w.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close 写出末块，不关闭底层 Writer
+func (w *streamWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.flush(true)
+}
+
+func (w *streamWriter) flush(last bool) error {
+	if w.counter == ^uint64(0) {
+		return errors.New("too many chunks")
+	}
+	sealed := w.aead.Seal(nil, chunkNonce(w.counter, last), w.buf, nil)
+	w.counter++
+	w.buf = w.buf[:0]
+	_, err := w.dst.Write(sealed)
+	return err
+}
+
+// streamReader 分块解密读取器，读到末块后拒绝尾随数据
+type streamReader struct {
+	aead    cipher.AEAD
+	src     io.Reader
+	encBuf  []byte
+	plain   []byte
+	counter uint64
+	done    bool
+	err     error
+}
+
+func newStreamReader(key []byte, src io.Reader) (*streamReader, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return &streamReader{aead: aead, src: src, encBuf: make([]byte, chunkSize+tagSize+1)}, nil
+}
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.readChunk()
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// readChunk 读取并解密一个分块；多读 1 字节判断是否为末块
+func (r *streamReader) readChunk() error {
+	// 上一轮多读的 1 字节保存在 encBuf[0]
+	start := 0
+	if r.counter > 0 {
+		start = 1
+	}
+	n, err := io.ReadFull(r.src, r.encBuf[start:])
+	n += start
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+
+	last := n <= chunkSize+tagSize
+	chunk := r.encBuf[:min(n, chunkSize+tagSize)]
+	if len(chunk) < tagSize {
+		return errors.New("truncated age payload")
+	}
+
+	plain, openErr := r.aead.Open(nil, chunkNonce(r.counter, last), chunk, nil)
+	if openErr != nil {
+		return errors.New("failed to decrypt and authenticate payload chunk")
+	}
+	if last && len(plain) == 0 && r.counter > 0 {
+		return errors.New("last chunk is empty")
+	}
+
+	r.counter++
+	r.plain = plain
+	if last {
+		r.done = true
+	} else {
+		r.encBuf[0] = r.encBuf[chunkSize+tagSize]
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package age
+
+import (
+	"bufio"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	x25519StanzaType = "X25519"
+	x25519Label      = "age-encryption.org/v1/X25519"
+
+	recipientHRP = "age"
+	identityHRP  = "AGE-SECRET-KEY-"
+)
+
+// X25519Recipient age1... 公钥接收方
+type X25519Recipient struct {
+	publicKey *ecdh.PublicKey
+}
+
+// NewX25519Recipient 由 X25519 公钥创建接收方
+func NewX25519Recipient(publicKey *ecdh.PublicKey) *X25519Recipient {
+	return &X25519Recipient{publicKey: publicKey}
+}
+
+// ParseX25519Recipient 解析 age1... 形式的接收方
+func ParseX25519Recipient(s string) (*X25519Recipient, error) {
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed recipient %q: %w", s, err)
+	}
+	if hrp != recipientHRP {
+		return nil, fmt.Errorf("malformed recipient %q: invalid type %q", s, hrp)
+	}
+	publicKey, err := ecdh.X25519().NewPublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("malformed recipient %q: %w", s, err)
+	}
+	return NewX25519Recipient(publicKey), nil
+}
+
+// String 返回 age1... 编码
+func (r *X25519Recipient) String() string {
+	s, _ := bech32Encode(recipientHRP, r.publicKey.Bytes())
+	return s
+}
+
+// Wrap 临时密钥协商后用 ChaCha20-Poly1305 封装文件密钥
+func (r *X25519Recipient) Wrap(fileKey []byte) ([]*Stanza, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := ephemeral.ECDH(r.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	share := ephemeral.PublicKey().Bytes()
+	salt := append(append([]byte{}, share...), r.publicKey.Bytes()...)
+	wrapKey, err := hkdf.Key(sha256.New, shared, salt, x25519Label, chachaKeySize)
+	if err != nil {
+		return nil, err
+	}
+	body, err := aeadWrap(wrapKey, fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*Stanza{{Type: x25519StanzaType, Args: []string{b64.EncodeToString(share)}, Body: body}}, nil
+}
+
+// X25519Identity AGE-SECRET-KEY-1... 私钥身份
+type X25519Identity struct {
+	privateKey *ecdh.PrivateKey
+}
+
+// NewX25519Identity 由 X25519 私钥创建身份
+func NewX25519Identity(privateKey *ecdh.PrivateKey) *X25519Identity {
+	return &X25519Identity{privateKey: privateKey}
+}
+
+// GenerateX25519Identity 生成新的 X25519 身份
+func GenerateX25519Identity() (*X25519Identity, error) {
+	privateKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewX25519Identity(privateKey), nil
+}
+
+// ParseX25519Identity 解析 AGE-SECRET-KEY-1... 形式的身份
+func ParseX25519Identity(s string) (*X25519Identity, error) {
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed secret key: %w", err)
+	}
+	if hrp != strings.ToLower(identityHRP) {
+		return nil, fmt.Errorf("malformed secret key: unknown type %q", hrp)
+	}
+	privateKey, err := ecdh.X25519().NewPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("malformed secret key: %w", err)
+	}
+	return NewX25519Identity(privateKey), nil
+}
+
+// String 返回 AGE-SECRET-KEY-1... 编码
+func (i *X25519Identity) String() string {
+	s, _ := bech32Encode(identityHRP, i.privateKey.Bytes())
+	return strings.ToUpper(s)
+}
+
+// Recipient 返回对应的接收方
+func (i *X25519Identity) Recipient() *X25519Recipient {
+	return NewX25519Recipient(i.privateKey.PublicKey())
+}
+
+// Unwrap 依次尝试 X25519 节
+func (i *X25519Identity) Unwrap(stanzas []*Stanza) ([]byte, error) {
+	for _, stanza := range stanzas {
+		if stanza.Type != x25519StanzaType {
+			continue
+		}
+		fileKey, err := i.unwrap(stanza)
+		if errors.Is(err, ErrIncorrectIdentity) {
+			continue
+		}
+		return fileKey, err
+	}
+	return nil, ErrIncorrectIdentity
+}
+
+func (i *X25519Identity) unwrap(stanza *Stanza) ([]byte, error) {
+	if len(stanza.Args) != 1 {
+		return nil, errors.New("invalid X25519 recipient block")
+	}
+	share, err := b64.DecodeString(stanza.Args[0])
+	if err != nil || len(share) != 32 {
+		return nil, errors.New("invalid X25519 recipient block")
+	}
+	if len(stanza.Body) != fileKeySize+chacha20poly1305.Overhead {
+		return nil, errors.New("invalid X25519 recipient block")
+	}
+
+	ephemeral, err := ecdh.X25519().NewPublicKey(share)
+	if err != nil {
+		return nil, errors.New("invalid X25519 recipient block")
+	}
+	shared, err := i.privateKey.ECDH(ephemeral)
+	if err != nil {
+		// 低阶点导致共享密钥全零
+		return nil, errors.New("invalid X25519 recipient block")
+	}
+
+	salt := append(append([]byte{}, share...), i.privateKey.PublicKey().Bytes()...)
+	wrapKey, err := hkdf.Key(sha256.New, shared, salt, x25519Label, chachaKeySize)
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := aeadUnwrap(wrapKey, stanza.Body)
+	if err != nil {
+		return nil, ErrIncorrectIdentity
+	}
+	return fileKey, nil
+}
+
+// ParseRecipients 解析接收方文件：每行一个 age1... 接收方，忽略空行和 # 注释
+func ParseRecipients(r io.Reader) ([]Recipient, error) {
+	var recipients []Recipient
+	err := scanKeyLines(r, func(line string) error {
+		recipient, err := ParseX25519Recipient(line)
+		if err != nil {
+			return err
+		}
+		recipients = append(recipients, recipient)
+		return nil
+	})
+	return recipients, err
+}
+
+// ParseIdentities 解析身份文件：每行一个 AGE-SECRET-KEY-1... 私钥，忽略空行和 # 注释
+func ParseIdentities(r io.Reader) ([]Identity, error) {
+	var identities []Identity
+	err := scanKeyLines(r, func(line string) error {
+		identity, err := ParseX25519Identity(line)
+		if err != nil {
+			return err
+		}
+		identities = append(identities, identity)
+		return nil
+	})
+	if err == nil && len(identities) == 0 {
+		return nil, errors.New("no secret keys found")
+	}
+	return identities, err
+}
+
+func scanKeyLines(r io.Reader, parse func(line string) error) error {
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := parse(line); err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// aeadWrap 用零 nonce 的 ChaCha20-Poly1305 封装文件密钥（每个封装密钥只使用一次）
+func aeadWrap(key, fileKey []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), fileKey, nil), nil
+}
+
+func aeadUnwrap(key, body []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), body, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(fileKey) != fileKeySize {
+		return nil, errors.New("invalid file key size")
+	}
+	return fileKey, nil
+}
@@ -0,0 +1,18 @@
+package app
+
+import (
+	"hycrypt/internal/config"
+	"hycrypt/internal/crypto"
+)
+
+// ageConfig 根据配置构建 age 互通参数，未配置额外接收方和身份时返回 nil
+func ageConfig(cfg *config.Config) *crypto.AgeConfig {
+	if len(cfg.Keys.AgeRecipients) == 0 && len(cfg.Keys.AgeIdentities) == 0 {
+		return nil
+	}
+
+	return &crypto.AgeConfig{
+		Recipients:    cfg.GetAgeRecipients(),
+		IdentityPaths: cfg.GetAgeIdentityPaths(),
+	}
+}
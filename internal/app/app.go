@@ -2,12 +2,13 @@ package app
 
 import (
 	"context"
-	"crypto/rand"
 	"fmt"
 	"hycrypt/internal/config"
-	"hycrypt/internal/constants"
 	"hycrypt/internal/crypto"
+	"hycrypt/internal/datasink"
+	"hycrypt/internal/datasource"
 	"hycrypt/internal/domain"
+	"hycrypt/internal/format"
 	interactivecli "hycrypt/internal/interactive-cli"
 	"hycrypt/internal/output"
 	"os"
@@ -31,8 +32,11 @@ type Options struct {
 	OutputFormat string
 	InputFormat  string
 	Method       string
+	Key          string // 命名密钥，为空时加密使用默认密钥，解密按文件头部指纹自动选择
 	Decrypt      bool
 	Verbose      bool
+	Offset       int64 // cat 命令读取的明文起始偏移
+	Length       int64 // cat 命令读取的明文字节数，负数表示读到结尾
 }
 
 // New 创建新的应用程序实例
@@ -47,31 +51,9 @@ func WithOutputMode(cfg *config.Config, mode output.OutputMode) (*App, error) {
 		return nil, fmt.Errorf("failed to initialize keys: %w", err)
 	}
 
-	// 构建处理器配置
-	processorConfig := &crypto.ProcessorConfig{}
-
-	// 配置RSA - 仅当指定使用RSA方法时
-	if cfg.Encryption.Method == constants.AlgorithmRSA {
-		processorConfig.RSAConfig = &crypto.RSAConfig{
-			PublicKeyPath:  cfg.GetPublicKeyPath(),
-			PrivateKeyPath: cfg.GetPrivateKeyPath(),
-			KeySize:        cfg.Encryption.RSAKeySize,
-			AESKeySize:     cfg.Encryption.AESKeySize,
-		}
-	}
-
-	// 配置KMAC - 仅当指定使用KMAC方法时
-	if cfg.Encryption.Method == constants.AlgorithmKMAC {
-		kmacKey, err := cfg.LoadKMACKey()
-		if err != nil {
-			return nil, fmt.Errorf("加载 KMAC 密钥失败: %w", err)
-		}
-
-		processorConfig.KMACConfig = &crypto.KMACConfig{
-			Key:        kmacKey,
-			KeySize:    cfg.Encryption.KMACKeySize,
-			AESKeySize: cfg.Encryption.AESKeySize,
-		}
+	processorConfig, err := buildProcessorConfig(cfg, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	processor, err := crypto.NewUnifiedProcessor(processorConfig)
@@ -101,6 +83,25 @@ func WithOutputMode(cfg *config.Config, mode output.OutputMode) (*App, error) {
 	}, nil
 }
 
+// buildProcessorConfig 按当前配置的算法和选择的密钥构建处理器配置，password 非空时配置口令算法
+func buildProcessorConfig(cfg *config.Config, password []byte) (*crypto.ProcessorConfig, error) {
+	processorConfig := &crypto.ProcessorConfig{
+		AEAD:             cfg.Encryption.AEAD,
+		Padding:          cfg.Encryption.Padding,
+		Compression:      cfg.Encryption.Compression,
+		CompressionLevel: cfg.Encryption.CompressionLevel,
+		Workers:          cfg.Encryption.Workers,
+		SigningConfig:    signingConfig(cfg),
+		AgeConfig:        ageConfig(cfg),
+	}
+
+	if err := cfg.ConfigureAlgorithms(processorConfig, keyPassphrase(cfg), password, false); err != nil {
+		return nil, fmt.Errorf("加载密钥失败: %w", err)
+	}
+
+	return processorConfig, nil
+}
+
 // RunCLI 运行命令行模式
 func (a *App) RunCLI(ctx context.Context, opts *Options) error {
 	// 验证输入参数
@@ -115,6 +116,28 @@ func (a *App) RunCLI(ctx context.Context, opts *Options) error {
 			fmt.Printf("🔍 自动检测算法: %s\n", detected)
 		}
 		opts.Method = detected
+
+		// 未指定密钥时按文件头部记录的接收方指纹选择密钥环中的密钥
+		keyChanged := false
+		if opts.Key == "" {
+			if method, name, ok := a.config.MatchingKeyForFile(opts.FilePath); ok && method == detected && name != a.config.KeyName(method) {
+				if opts.Verbose {
+					fmt.Printf("🔍 自动选择密钥: %s\n", name)
+				}
+				a.config.SelectKey(method, name)
+				keyChanged = true
+			}
+		}
+
+		// 检测到的算法与配置不同或密钥变化时，重新创建对应的处理器
+		if keyChanged {
+			a.config.Encryption.Method = detected
+			if err := a.rebuildProcessor(); err != nil {
+				return err
+			}
+		} else if err := a.ensureProcessorFor(detected); err != nil {
+			return err
+		}
 	}
 
 	// 如果仍未指定方法，使用配置中的默认方法
@@ -122,6 +145,13 @@ func (a *App) RunCLI(ctx context.Context, opts *Options) error {
 		opts.Method = a.config.Encryption.Method
 	}
 
+	// 口令类算法没有密钥文件，需要在处理前读取口令（加密时要求确认）
+	if algorithm, ok := crypto.LookupAlgorithm(opts.Method); ok && algorithm.NeedsPassword {
+		if err := a.usePassword(opts.Method, !opts.Decrypt); err != nil {
+			return err
+		}
+	}
+
 	// 构建加密选项
 	cryptoOpts := domain.CryptoOptions{
 		Method:       opts.Method,
@@ -182,18 +212,30 @@ func (a *App) validateCLIOptions(opts *Options) error {
 		return fmt.Errorf("-f and -t options cannot be used together")
 	}
 
-	if opts.TextMode && opts.Decrypt && opts.InputFormat != "hex" {
-		return fmt.Errorf("text mode decryption requires hex input format")
+	if opts.TextMode && opts.Decrypt && opts.InputFormat != "hex" && opts.InputFormat != "jwe" {
+		return fmt.Errorf("text mode decryption requires hex or jwe input format")
 	}
 
 	if opts.OutputFormat == "hex" && (!opts.TextMode || opts.Decrypt) {
 		return fmt.Errorf("hex output format only supports text encryption mode")
 	}
 
+	if opts.OutputFormat == "age" && opts.Decrypt {
+		return fmt.Errorf("age output format only supports encryption, age files are detected automatically when decrypting")
+	}
+
 	if opts.InputFormat == "hex" && (!opts.TextMode || !opts.Decrypt) {
 		return fmt.Errorf("hex input format only supports text decryption mode")
 	}
 
+	if (opts.OutputFormat == "jwe" || opts.OutputFormat == "jwe-json") && (!opts.TextMode || opts.Decrypt) {
+		return fmt.Errorf("jwe output format only supports text encryption mode")
+	}
+
+	if opts.InputFormat == "jwe" && (!opts.TextMode || !opts.Decrypt) {
+		return fmt.Errorf("jwe input format only supports text decryption mode")
+	}
+
 	return nil
 }
 
@@ -224,6 +266,11 @@ func (a *App) processTextInput(ctx context.Context, outputDir string, isDecrypt
 		return fmt.Errorf("input is empty")
 	}
 
+	// JWE 是文本格式，直接在内存中处理
+	if opts.OutputFormat == domain.OutputJWE || opts.OutputFormat == domain.OutputJWEJSON || opts.InputFormat == domain.InputJWE {
+		return a.processTextJWE(ctx, input, isDecrypt, opts, startTime)
+	}
+
 	// 创建临时文件进行处理
 	tempFile, err := a.createTempFile(input)
 	if err != nil {
@@ -244,7 +291,7 @@ func (a *App) processTextInput(ctx context.Context, outputDir string, isDecrypt
 	}
 
 	// 普通文件处理
-	_, err = a.processor.ProcessFile(ctx, tempFile, outputDir, !isDecrypt, opts)
+	cryptoResult, err := a.processor.ProcessFile(ctx, tempFile, outputDir, !isDecrypt, opts)
 	if err != nil {
 		result := output.ErrorResult(err)
 		a.outputMgr.PrintResult(result)
@@ -257,9 +304,14 @@ func (a *App) processTextInput(ctx context.Context, outputDir string, isDecrypt
 	var result *output.OperationResult
 	if isDecrypt {
 		result = output.SmartDecryptionResult(tempFile, outputDir, strings.ToUpper(opts.Method), int64(len(input)), processTime)
+		result.Details.Signer = cryptoResult.Signer
 	} else {
 		result = output.SmartEncryptionResult(tempFile, outputDir, opts.Method, int64(len(input)), processTime)
+		if opts.OutputFormat == domain.OutputAge {
+			result.Details.FileName = filepath.Base(cryptoResult.OutputPath)
+		}
 	}
+	result.Details.Compression = cryptoResult.Compression
 
 	a.outputMgr.PrintResult(result)
 	return nil
@@ -277,7 +329,7 @@ func (a *App) processFileInput(ctx context.Context, filePath, outputDir string,
 	}
 
 	// 处理文件
-	_, err = a.processor.ProcessFile(ctx, filePath, outputDir, !isDecrypt, opts)
+	cryptoResult, err := a.processor.ProcessFile(ctx, filePath, outputDir, !isDecrypt, opts)
 	if err != nil {
 		result := output.ErrorResult(err)
 		a.outputMgr.PrintResult(result)
@@ -291,9 +343,14 @@ func (a *App) processFileInput(ctx context.Context, filePath, outputDir string,
 	if isDecrypt {
 		detectedAlgorithm := strings.ToUpper(opts.Method)
 		result = output.SmartDecryptionResult(filePath, outputDir, detectedAlgorithm, fileInfo.Size(), processTime)
+		result.Details.Signer = cryptoResult.Signer
 	} else {
 		result = output.SmartEncryptionResult(filePath, outputDir, opts.Method, fileInfo.Size(), processTime)
+		if opts.OutputFormat == domain.OutputAge {
+			result.Details.FileName = filepath.Base(cryptoResult.OutputPath)
+		}
 	}
+	result.Details.Compression = cryptoResult.Compression
 
 	a.outputMgr.PrintResult(result)
 	return nil
@@ -313,8 +370,39 @@ func (a *App) createTempFile(content string) (string, error) {
 	return tempFile, err
 }
 
+// ensureProcessorFor 确保处理器已加载指定算法的服务
+func (a *App) ensureProcessorFor(method string) error {
+	if method == a.config.Encryption.Method {
+		return nil
+	}
+
+	a.config.Encryption.Method = method
+	return a.rebuildProcessor()
+}
+
+// rebuildProcessor 按当前配置（算法与选择的密钥）重新创建处理器
+func (a *App) rebuildProcessor() error {
+	rebuilt, err := WithOutputMode(a.config, output.ModeCLI)
+	if err != nil {
+		return err
+	}
+
+	a.processor = rebuilt.processor
+	return nil
+}
+
 func (a *App) detectMethodFromFile(filePath string) string {
-	// 首先尝试使用配置中的现代算法检测器
+	// 首先读取文件头部，头部中的算法标识最可靠
+	if detected := format.DetectAlgorithm(filePath); detected != "" {
+		return detected
+	}
+
+	// age 文件根据接收方节推断对应的方法
+	if detected := crypto.DetectAgeMethod(filePath); detected != "" {
+		return detected
+	}
+
+	// 其次尝试使用配置中的现代算法检测器
 	detected := a.config.DetectAlgorithmFromPath(filePath)
 	if detected != "" && a.config.IsAlgorithmSupported(detected) {
 		return detected
@@ -322,12 +410,10 @@ func (a *App) detectMethodFromFile(filePath string) string {
 
 	// 回退到简单的文件名检测
 	fileName := filepath.Base(filePath)
-
-	if strings.Contains(fileName, "-rsa.") || strings.Contains(fileName, ".rsa.") {
-		return constants.AlgorithmRSA
-	}
-	if strings.Contains(fileName, "-kmac.") || strings.Contains(fileName, ".kmac.") {
-		return constants.AlgorithmKMAC
+	for _, name := range crypto.AlgorithmNames() {
+		if strings.Contains(fileName, "-"+name+".") || strings.Contains(fileName, "."+name+".") {
+			return name
+		}
 	}
 
 	return a.config.Encryption.Method // 默认使用配置中的方法
@@ -342,10 +428,46 @@ func (a *App) processTextHexOutput(ctx context.Context, text string, opts domain
 	return fmt.Errorf("text hex output not implemented yet")
 }
 
+// processTextJWE 文本加密为 JWE，或解密 JWE 文本，结果输出到控制台
+func (a *App) processTextJWE(ctx context.Context, text string, isDecrypt bool, opts domain.CryptoOptions, startTime time.Time) error {
+	source := datasource.TextSource([]byte(text), "text")
+
+	var cryptoResult *domain.CryptoResult
+	var err error
+	if isDecrypt {
+		// 根据 JWE 的 alg 选择解密方法
+		if method := crypto.DetectJWEMethod(text); method != "" {
+			opts.Method = method
+			if err := a.ensureProcessorFor(method); err != nil {
+				return err
+			}
+		}
+		cryptoResult, err = a.processor.Decrypt(ctx, source, datasink.CreateConsoleSink(), opts)
+	} else {
+		cryptoResult, err = a.processor.Encrypt(ctx, source, datasink.CreateJWESink(), opts)
+	}
+	if err != nil {
+		result := output.ErrorResult(err)
+		a.outputMgr.PrintResult(result)
+		return err
+	}
+
+	if opts.Verbose {
+		fmt.Printf("⏱️ %s %s\n", strings.ToUpper(cryptoResult.Method), time.Since(startTime))
+	}
+	return nil
+}
+
 func parseOutputFormat(format string) domain.OutputFormat {
 	switch format {
 	case "hex":
 		return domain.OutputHex
+	case "age":
+		return domain.OutputAge
+	case "jwe":
+		return domain.OutputJWE
+	case "jwe-json":
+		return domain.OutputJWEJSON
 	default:
 		return domain.OutputFile
 	}
@@ -357,6 +479,8 @@ func parseInputFormat(format string) domain.InputFormat {
 		return domain.InputHex
 	case "text":
 		return domain.InputText
+	case "jwe":
+		return domain.InputJWE
 	default:
 		return domain.InputFile
 	}
@@ -364,41 +488,20 @@ func parseInputFormat(format string) domain.InputFormat {
 
 // ensureKeysInitialized 确保所需的密钥已初始化
 func ensureKeysInitialized(cfg *config.Config) error {
-	// 根据当前方法检查并初始化相应的密钥
-	switch cfg.Encryption.Method {
-	case constants.AlgorithmRSA:
-		// 检查RSA密钥是否存在
-		publicKeyPath := cfg.GetPublicKeyPath()
-		privateKeyPath := cfg.GetPrivateKeyPath()
-
-		_, pubErr := os.Stat(publicKeyPath)
-		_, privErr := os.Stat(privateKeyPath)
-
-		if os.IsNotExist(pubErr) || os.IsNotExist(privErr) {
-			// RSA密钥不存在，调用全局配置初始化来生成
-			fmt.Println("🔧 检测到RSA密钥缺失, 正在初始化...")
-			if err := config.InitializeGlobalConfig(); err != nil {
-				return fmt.Errorf("failed to initialize RSA keys: %w", err)
-			}
+	// 根据当前方法检查并生成注册表中声明的密钥文件
+	algorithm, ok := crypto.LookupAlgorithm(cfg.Encryption.Method)
+	if ok && algorithm.GenerateKeys != nil && !cfg.CheckAlgorithmKeysExist(algorithm) {
+		// 只自动生成默认密钥，命名密钥需显式生成
+		if name := cfg.KeyName(algorithm.Name); name != config.DefaultKeyName {
+			return fmt.Errorf("%s key %q not found, generate it with: hycrypt keys generate %s %s", algorithm.DisplayName, name, algorithm.Name, name)
 		}
-
-	case constants.AlgorithmKMAC:
-		// 检查KMAC密钥是否存在
-		if !cfg.CheckKMACKeyExists() {
-			// KMAC密钥不存在，生成新的密钥
-			fmt.Println("🔧 检测到KMAC密钥缺失, 正在生成...")
-			keyBytes := make([]byte, cfg.Encryption.KMACKeySize)
-			if _, err := rand.Read(keyBytes); err != nil {
-				return fmt.Errorf("failed to generate KMAC key: %w", err)
-			}
-
-			if err := cfg.SaveKMACKey(keyBytes); err != nil {
-				return fmt.Errorf("failed to save KMAC key: %w", err)
-			}
-
-			fmt.Printf("✅ KMAC 密钥已生成并保存到: %s\n", cfg.GetKMACKeyPath())
+		fmt.Printf("🔧 检测到%s密钥缺失, 正在生成...\n", algorithm.DisplayName)
+		paths, err := cfg.GenerateAlgorithmKeys(algorithm)
+		if err != nil {
+			return err
 		}
+		fmt.Printf("✅ %s 密钥已生成并保存到: %s\n", algorithm.DisplayName, strings.Join(paths, ", "))
 	}
 
-	return nil
+	return ensureSigningKey(cfg)
 }
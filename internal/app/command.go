@@ -0,0 +1,54 @@
+package app
+
+import (
+	"fmt"
+	"hycrypt/internal/config"
+	"hycrypt/internal/errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RunCommand 运行子命令，args[0] 为命令名，opts 携带 -output 等通用选项
+func RunCommand(cfg *config.Config, opts *Options, args []string) error {
+	switch args[0] {
+	case "keys":
+		return RunKeysCommand(cfg, opts, args[1:])
+	case "rekey":
+		return RunRekeyCommand(cfg, opts, args[1:])
+	case "verify":
+		return RunVerifyCommand(cfg, opts, args[1:])
+	case "cat":
+		return RunCatCommand(cfg, opts, args[1:])
+	default:
+		return fmt.Errorf("未知命令: %s（运行 hycrypt -help 查看用法）", args[0])
+	}
+}
+
+// forEachEncryptedFile 对 path 调用 visit，目录递归处理其中带加密扩展名的文件，无法访问的路径交给 fail
+func forEachEncryptedFile(cfg *config.Config, path string, visit func(string), fail func(string, error)) {
+	info, err := os.Stat(path)
+	if err != nil {
+		fail(path, errors.FileNotFound(path))
+		return
+	}
+	if !info.IsDir() {
+		visit(path)
+		return
+	}
+
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			fail(file, err)
+			return nil
+		}
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), cfg.Encryption.FileExtension) {
+			visit(file)
+		}
+		return nil
+	})
+	if err != nil {
+		fail(path, err)
+	}
+}
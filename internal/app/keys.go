@@ -0,0 +1,338 @@
+package app
+
+import (
+	"fmt"
+	"hycrypt/internal/config"
+	"hycrypt/internal/crypto"
+	"hycrypt/internal/mnemonic"
+	"hycrypt/internal/shamir"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// NewKeyPassphraseEnvVar 非交互场景下为密钥文件设置新口令的环境变量
+const NewKeyPassphraseEnvVar = "HYCRYPT_NEW_KEY_PASSPHRASE"
+
+// keysUsage 返回 keys 子命令的用法，可用的算法名称来自已注册的算法
+func keysUsage() string {
+	var generate, share, symmetric []string
+	for _, algorithm := range crypto.Algorithms() {
+		if algorithm.GenerateKeys != nil {
+			generate = append(generate, algorithm.Name)
+		}
+		if _, ok := algorithm.ShareKeyFile(); ok {
+			share = append(share, algorithm.Name)
+		}
+		if algorithm.SymmetricKey != nil {
+			symmetric = append(symmetric, algorithm.Name)
+		}
+	}
+	targets := strings.Join(append(share, "密钥文件路径"), "|")
+
+	return fmt.Sprintf(`用法:
+  hycrypt keys list
+  hycrypt keys generate <%s> <名称>
+  hycrypt keys <protect|passwd|unprotect> <%s>
+  hycrypt keys split <%s> <门限> <份数> [-output=目录]
+  hycrypt keys combine <%s> [分片文件...]
+  hycrypt keys <export-mnemonic|import-mnemonic> <%s>`,
+		strings.Join(generate, "|"), targets, targets, targets, strings.Join(symmetric, "|"))
+}
+
+// RunKeysCommand 管理密钥文件：列出密钥指纹，在密钥环中生成命名密钥，设置、修改、移除口令，Shamir 分片拆分与合并，以及对称密钥的助记词备份
+func RunKeysCommand(cfg *config.Config, opts *Options, args []string) error {
+	if len(args) == 1 && args[0] == "list" {
+		return listKeys(cfg)
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("%s", keysUsage())
+	}
+
+	switch args[0] {
+	case "generate":
+		if len(args) != 3 {
+			return fmt.Errorf("%s", keysUsage())
+		}
+		return generateNamedKey(cfg, args[1], args[2])
+	case "protect", "passwd", "unprotect":
+		if len(args) != 2 {
+			return fmt.Errorf("%s", keysUsage())
+		}
+		return changeKeyPassphrase(cfg, args[0], resolveKeyTarget(cfg, args[1]))
+	case "split":
+		if len(args) != 4 {
+			return fmt.Errorf("%s", keysUsage())
+		}
+		return splitKey(resolveKeyTarget(cfg, args[1]), args[2], args[3], opts.OutputDir)
+	case "combine":
+		return combineKey(args[1], resolveKeyTarget(cfg, args[1]), args[2:])
+	case "export-mnemonic", "import-mnemonic":
+		// 助记词只用于对称密钥
+		algorithm, ok := crypto.LookupAlgorithm(args[1])
+		if len(args) != 2 || !ok || algorithm.SymmetricKey == nil {
+			return fmt.Errorf("%s\n助记词只支持对称密钥", keysUsage())
+		}
+		if args[0] == "export-mnemonic" {
+			return exportMnemonic(cfg, algorithm)
+		}
+		return importMnemonic(cfg, algorithm)
+	default:
+		return fmt.Errorf("未知的 keys 子命令: %s\n%s", args[0], keysUsage())
+	}
+}
+
+// listKeys 列出已配置的密钥文件及其指纹，加密文件头部记录的接收方指纹与此一致
+// 密钥环中的每个命名密钥各占一行，当前使用的密钥标记为（当前）
+func listKeys(cfg *config.Config) error {
+	fmt.Println("🔑 密钥指纹:")
+	for _, algorithm := range crypto.Algorithms() {
+		if algorithm.Fingerprint == nil || !cfg.IsAlgorithmSupported(algorithm.Name) {
+			continue
+		}
+
+		current := cfg.KeyName(algorithm.Name)
+		names := cfg.KeyNames(algorithm)
+		if len(names) == 0 {
+			path := cfg.GetKeyFilePath(algorithm.KeyFiles[0].Name)
+			fmt.Printf("  %-16s  %s %s: %s（未生成）\n", "-", algorithm.DisplayName, current, path)
+			continue
+		}
+		for _, name := range names {
+			fingerprint, err := cfg.NamedKeyFingerprint(algorithm, name, keyPassphrase(cfg))
+			if err != nil {
+				return fmt.Errorf("读取 %s 密钥 %s 失败: %w", algorithm.DisplayName, name, err)
+			}
+			label := name
+			if name == current {
+				label += "（当前）"
+			}
+			path := cfg.NamedKeyPaths(algorithm, name)[algorithm.KeyFiles[0].Name]
+			fmt.Printf("  %-16s  %s %s: %s\n", crypto.FormatFingerprint(fingerprint), algorithm.DisplayName, label, path)
+		}
+	}
+
+	for _, path := range cfg.GetRSARecipientPaths() {
+		fingerprints, err := crypto.RSAPublicKeyFingerprints(path)
+		if err != nil {
+			return fmt.Errorf("读取 RSA 接收方公钥失败: %w", err)
+		}
+		for _, fingerprint := range fingerprints {
+			fmt.Printf("  %-16s  RSA 接收方: %s\n", crypto.FormatFingerprint(fingerprint), path)
+		}
+	}
+
+	if path := cfg.GetSigningPublicKeyPath(); path != "" {
+		if fingerprint, err := crypto.SigningKeyFingerprint(path); err == nil {
+			fmt.Printf("  %-16s  Ed25519 签名: %s\n", crypto.FormatFingerprint(fingerprint), path)
+		}
+	}
+	return nil
+}
+
+// generateNamedKey 在密钥环中生成命名密钥，已存在的密钥不会被覆盖
+func generateNamedKey(cfg *config.Config, method, name string) error {
+	algorithm, ok := crypto.LookupAlgorithm(method)
+	if !ok || algorithm.GenerateKeys == nil {
+		return fmt.Errorf("%s 算法不使用密钥文件\n%s", method, keysUsage())
+	}
+	if err := cfg.SelectKey(algorithm.Name, name); err != nil {
+		return err
+	}
+	if cfg.CheckAlgorithmKeysExist(algorithm) {
+		return fmt.Errorf("%s 密钥 %s 已存在: %s", algorithm.DisplayName, name, cfg.GetKeyFilePath(algorithm.KeyFiles[0].Name))
+	}
+
+	paths, err := cfg.GenerateAlgorithmKeys(algorithm)
+	if err != nil {
+		return err
+	}
+	fingerprint, err := cfg.KeyFingerprint(algorithm, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ %s 密钥 %s 已生成（指纹 %s）:\n%s\n", algorithm.DisplayName, name, crypto.FormatFingerprint(fingerprint), strings.Join(paths, "\n"))
+	return nil
+}
+
+// changeKeyPassphrase protect 设置口令、passwd 修改口令、unprotect 移除口令
+func changeKeyPassphrase(cfg *config.Config, action, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("密钥文件不存在: %s", path)
+	}
+	if err := crypto.CheckProtectableKeyFile(path); err != nil {
+		return err
+	}
+	protected := crypto.IsProtectedKeyFile(path)
+
+	var newPassphrase []byte
+	switch action {
+	case "protect":
+		if protected {
+			return fmt.Errorf("密钥文件已受口令保护，修改口令请使用 keys passwd: %s", path)
+		}
+	case "passwd", "unprotect":
+		if !protected {
+			return fmt.Errorf("密钥文件未受口令保护: %s", path)
+		}
+	}
+
+	if action != "unprotect" {
+		var err error
+		if newPassphrase, err = readNewKeyPassphrase(); err != nil {
+			return err
+		}
+		defer clear(newPassphrase)
+	}
+
+	if err := crypto.ChangeKeyFilePassphrase(path, keyPassphrase(cfg), newPassphrase); err != nil {
+		return err
+	}
+
+	switch action {
+	case "protect":
+		fmt.Printf("✅ 已为密钥文件设置口令: %s\n", path)
+	case "passwd":
+		fmt.Printf("✅ 已修改密钥文件口令: %s\n", path)
+	case "unprotect":
+		fmt.Printf("✅ 已移除密钥文件口令: %s\n", path)
+	}
+	return nil
+}
+
+// splitKey 将密钥文件拆分为 total 个可打印分片，写入 outputDir（默认当前目录）
+func splitKey(path, thresholdArg, totalArg, outputDir string) error {
+	threshold, err := strconv.Atoi(thresholdArg)
+	if err != nil {
+		return fmt.Errorf("无效的门限: %s", thresholdArg)
+	}
+	total, err := strconv.Atoi(totalArg)
+	if err != nil {
+		return fmt.Errorf("无效的份数: %s", totalArg)
+	}
+
+	shares, err := crypto.SplitKeyFile(path, threshold, total)
+	if err != nil {
+		return err
+	}
+
+	paths, err := crypto.WriteKeyShares(shares, outputDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ 已将 %s 拆分为 %d 个分片，任意 %d 个可恢复（分片 ID: %s）:\n", path, total, threshold, shares[0].ID)
+	for _, sharePath := range paths {
+		fmt.Printf("   %s\n", sharePath)
+	}
+	fmt.Println("⚠️  请将分片分别交给不同的保管人，并删除本地的分片副本")
+	return nil
+}
+
+// combineKey 从分片文件（未指定时从标准输入读取）恢复密钥文件到 path
+func combineKey(target, path string, files []string) error {
+	var text []byte
+	if len(files) == 0 {
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			fmt.Fprintln(os.Stderr, "📋 请粘贴分片，完成后按 Ctrl+D:")
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("读取分片失败: %w", err)
+		}
+		text = data
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("读取分片文件失败: %w", err)
+		}
+		text = append(append(text, data...), '\n')
+	}
+
+	shares, err := shamir.ParseShares(text)
+	if err != nil {
+		return err
+	}
+	if len(shares) == 0 {
+		return fmt.Errorf("未找到分片")
+	}
+
+	// 算法名称目标需要与分片中记录的密钥类型一致
+	if algorithm, ok := crypto.LookupAlgorithm(target); ok {
+		if keyFile, ok := algorithm.ShareKeyFile(); ok && shares[0].Kind != keyFile.ShareKind {
+			return fmt.Errorf("分片中的密钥类型为 %s，不能恢复为 %s 密钥", shares[0].Kind, target)
+		}
+	}
+
+	keyData, err := crypto.CombineKeyShares(shares)
+	if err != nil {
+		return err
+	}
+	defer clear(keyData)
+
+	if err := crypto.WriteRecoveredKeyFile(path, keyData); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已从 %d 个分片恢复密钥文件: %s\n", len(shares), path)
+	return nil
+}
+
+// exportMnemonic 以带序号的助记词输出对称密钥
+func exportMnemonic(cfg *config.Config, algorithm *crypto.Algorithm) error {
+	words, err := cfg.KeyMnemonic(algorithm, keyPassphrase(cfg))
+	if err != nil {
+		return err
+	}
+
+	path := cfg.GetKeyFilePath(algorithm.KeyFiles[0].Name)
+	fmt.Fprintf(os.Stderr, "🔑 %s 密钥助记词（%d 个词，%s）:\n\n", algorithm.DisplayName, len(strings.Fields(words)), path)
+	fmt.Print(mnemonic.Format(words, 6))
+	fmt.Fprintln(os.Stderr, "\n⚠️  助记词即密钥本身，请抄写在纸上离线保存，不要拍照或存入联网设备")
+	return nil
+}
+
+// importMnemonic 从标准输入读取助记词，校验通过后保存为对称密钥
+// 设置了 HYCRYPT_NEW_KEY_PASSPHRASE 时以该口令加密保存
+func importMnemonic(cfg *config.Config, algorithm *crypto.Algorithm) error {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintln(os.Stderr, "📝 请输入助记词（可只写每个词的前 4 个字母），完成后按 Ctrl+D:")
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("读取助记词失败: %w", err)
+	}
+	defer clear(data)
+
+	passphrase := []byte(os.Getenv(NewKeyPassphraseEnvVar))
+	if err := cfg.RestoreKeyFromMnemonic(algorithm, string(data), passphrase); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ 已从助记词恢复 %s 密钥: %s\n", algorithm.DisplayName, cfg.GetKeyFilePath(algorithm.KeyFiles[0].Name))
+	if len(passphrase) == 0 {
+		fmt.Printf("💡 可使用 hycrypt keys protect %s 为密钥设置口令\n", algorithm.Name)
+	}
+	return nil
+}
+
+// resolveKeyTarget 将算法名称解析为配置中支持拆分的私钥文件，其他参数视为文件路径
+func resolveKeyTarget(cfg *config.Config, target string) string {
+	if algorithm, ok := crypto.LookupAlgorithm(target); ok {
+		if keyFile, ok := algorithm.ShareKeyFile(); ok {
+			return cfg.GetKeyFilePath(keyFile.Name)
+		}
+	}
+	return target
+}
+
+// readNewKeyPassphrase 读取新的密钥文件口令，终端输入时要求确认
+func readNewKeyPassphrase() ([]byte, error) {
+	if env := os.Getenv(NewKeyPassphraseEnvVar); env != "" {
+		return []byte(env), nil
+	}
+	return promptSecret(NewKeyPassphraseEnvVar, "🔑 请输入新的密钥口令: ", true)
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"hycrypt/internal/config"
+	"hycrypt/internal/crypto"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// PasswordEnvVar 非交互场景（脚本、CI）下提供口令的环境变量
+const PasswordEnvVar = "HYCRYPT_PASSWORD"
+
+// KeyPassphraseEnvVar 非交互场景下提供私钥文件口令的环境变量
+const KeyPassphraseEnvVar = config.KeyPassphraseEnvVar
+
+// readPassword 从终端读取口令（不回显），confirm 为 true 时要求输入两次
+func readPassword(confirm bool) ([]byte, error) {
+	if env := os.Getenv(PasswordEnvVar); env != "" {
+		return []byte(env), nil
+	}
+	return promptSecret(PasswordEnvVar, "🔑 请输入口令: ", confirm)
+}
+
+// promptKeyPassphrase 从终端读取受口令保护的密钥文件（加密 PKCS#8、KMAC 或 OpenSSH 私钥）的口令
+func promptKeyPassphrase(path string) ([]byte, error) {
+	return promptSecret(KeyPassphraseEnvVar, fmt.Sprintf("🔑 请输入私钥口令 (%s): ", path), false)
+}
+
+// keyPassphrase 按配置的来源（环境变量、passphrase_command、终端）读取密钥文件口令
+func keyPassphrase(cfg *config.Config) crypto.PassphraseFunc {
+	return cfg.KeyPassphraseSource(promptKeyPassphrase)
+}
+
+// promptSecret 从终端读取不回显的输入，无法打开终端时提示使用 envVar 环境变量
+func promptSecret(envVar, label string, confirm bool) ([]byte, error) {
+	// 标准输入可能被管道占用（文本模式），优先直接打开终端
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return nil, fmt.Errorf("无法打开终端读取口令，请设置 %s 环境变量", envVar)
+		}
+		tty = os.Stdin
+	} else {
+		defer tty.Close()
+	}
+
+	prompt := func(label string) ([]byte, error) {
+		fmt.Fprint(tty, label)
+		password, err := term.ReadPassword(int(tty.Fd()))
+		fmt.Fprintln(tty)
+		if err != nil {
+			return nil, fmt.Errorf("读取口令失败: %w", err)
+		}
+		return password, nil
+	}
+
+	password, err := prompt(label)
+	if err != nil {
+		return nil, err
+	}
+	if len(password) == 0 {
+		return nil, fmt.Errorf("口令不能为空")
+	}
+
+	if confirm {
+		again, err := prompt("🔑 请再次输入口令: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(password, again) {
+			return nil, fmt.Errorf("两次输入的口令不一致")
+		}
+	}
+
+	return password, nil
+}
+
+// usePassword 读取口令并重建只包含 method 口令服务的处理器，不解锁配置中其他算法的受保护私钥
+func (a *App) usePassword(method string, confirm bool) error {
+	password, err := readPassword(confirm)
+	if err != nil {
+		return err
+	}
+
+	previousMethod := a.config.Encryption.Method
+	a.config.Encryption.Method = method
+	processorConfig, err := buildProcessorConfig(a.config, password)
+	a.config.Encryption.Method = previousMethod
+	if err != nil {
+		return err
+	}
+
+	processor, err := crypto.NewUnifiedProcessor(processorConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create processor: %w", err)
+	}
+
+	a.processor = processor
+	return nil
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"hycrypt/internal/config"
+	"hycrypt/internal/crypto"
+	"hycrypt/internal/format"
+	"os"
+)
+
+// processorCache 按文件选择算法和密钥构建处理器，按算法和密钥名称缓存，口令只需输入一次
+type processorCache struct {
+	cfg        *config.Config
+	opts       *Options
+	processors map[string]*crypto.UnifiedProcessor
+	password   []byte
+}
+
+// newProcessorCache 创建处理器缓存
+func newProcessorCache(cfg *config.Config, opts *Options) *processorCache {
+	return &processorCache{cfg: cfg, opts: opts, processors: make(map[string]*crypto.UnifiedProcessor)}
+}
+
+// forFile 按文件头部选择算法和密钥，返回对应的处理器和算法名称
+func (c *processorCache) forFile(path string) (*crypto.UnifiedProcessor, string, error) {
+	method := c.detectMethod(path)
+	if method == "" {
+		return nil, "", fmt.Errorf("无法识别加密算法")
+	}
+
+	name := c.cfg.KeyName(method)
+	if c.opts.Key == "" {
+		if matched, matchedName, ok := c.cfg.MatchingKeyForFile(path); ok && matched == method {
+			name = matchedName
+		}
+	}
+	if c.opts.Verbose {
+		fmt.Fprintf(os.Stderr, "🔍 %s: 算法 %s，密钥 %s\n", path, method, name)
+	}
+
+	processor, err := c.processor(method, name)
+	if err != nil {
+		return nil, "", err
+	}
+	return processor, method, nil
+}
+
+// detectMethod 优先使用文件头部记录的算法，其次识别 age 文件和旧格式文件名
+func (c *processorCache) detectMethod(path string) string {
+	if method := format.DetectAlgorithm(path); method != "" {
+		return method
+	}
+	if method := crypto.DetectAgeMethod(path); method != "" {
+		return method
+	}
+	if method := c.cfg.DetectAlgorithmFromPath(path); method != "" && c.cfg.IsAlgorithmSupported(method) {
+		return method
+	}
+	return ""
+}
+
+// processor 返回使用指定算法和命名密钥的处理器，缺少密钥时报错而不自动生成
+func (c *processorCache) processor(method, name string) (*crypto.UnifiedProcessor, error) {
+	cacheKey := method + "/" + name
+	if processor, ok := c.processors[cacheKey]; ok {
+		return processor, nil
+	}
+
+	algorithm, ok := crypto.LookupAlgorithm(method)
+	if !ok {
+		return nil, fmt.Errorf("不支持的算法: %s", method)
+	}
+
+	// 按文件选择的密钥构建处理器后恢复原来的算法与密钥选择
+	previousMethod, previousKey := c.cfg.Encryption.Method, c.cfg.KeyName(method)
+	c.cfg.Encryption.Method = method
+	c.cfg.SelectKey(method, name)
+	defer func() {
+		c.cfg.Encryption.Method = previousMethod
+		c.cfg.SelectKey(method, previousKey)
+	}()
+
+	if len(algorithm.KeyFiles) > 0 && !c.cfg.CheckAlgorithmKeysExist(algorithm) {
+		return nil, fmt.Errorf("%s 密钥 %s 不存在", algorithm.DisplayName, name)
+	}
+
+	if algorithm.NeedsPassword && c.password == nil {
+		password, err := readPassword(false)
+		if err != nil {
+			return nil, err
+		}
+		c.password = password
+	}
+
+	processorConfig, err := buildProcessorConfig(c.cfg, c.password)
+	if err != nil {
+		return nil, err
+	}
+
+	processor, err := crypto.NewUnifiedProcessor(processorConfig)
+	if err != nil {
+		return nil, err
+	}
+	c.processors[cacheKey] = processor
+	return processor, nil
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"fmt"
+	"hycrypt/internal/config"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/crypto"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"os"
+)
+
+const rekeyUsage = `用法:
+  hycrypt rekey <文件或目录...> [-key=新密钥] [-recipient=公钥文件]`
+
+// RunRekeyCommand 轮换 RSA 加密文件的接收方：按头部指纹找到旧密钥解开数据密钥，
+// 再为当前密钥及接收方重新封装，加密载荷保持不变
+func RunRekeyCommand(cfg *config.Config, opts *Options, args []string) error {
+	targets := args
+	if opts.FilePath != "" {
+		targets = append(targets, opts.FilePath)
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("%s", rekeyUsage)
+	}
+
+	algorithm, _ := crypto.LookupAlgorithm(constants.AlgorithmRSA)
+	if !cfg.CheckAlgorithmKeysExist(algorithm) {
+		name := cfg.KeyName(algorithm.Name)
+		return fmt.Errorf("RSA 密钥 %s 不存在，请先运行 hycrypt keys generate rsa %s", name, name)
+	}
+	target, err := crypto.RSAService(&crypto.RSAConfig{
+		PublicKeyPath:  cfg.GetPublicKeyPath(),
+		PrivateKeyPath: cfg.GetPrivateKeyPath(),
+		RecipientPaths: cfg.GetRSARecipientPaths(),
+		Passphrase:     keyPassphrase(cfg),
+	})
+	if err != nil {
+		return err
+	}
+
+	r := &rekeyer{cfg: cfg, algorithm: algorithm, target: target, identities: make(map[string]*crypto.RSAServiceInterface)}
+	for _, path := range targets {
+		forEachEncryptedFile(cfg, path, r.rekeyFile, r.fail)
+	}
+
+	fmt.Printf("\n🔁 换钥完成: 已轮换 %d 个，跳过 %d 个，失败 %d 个\n", r.rotated, r.skipped, r.failed)
+	if r.failed > 0 {
+		return fmt.Errorf("%d 个文件换钥失败", r.failed)
+	}
+	return nil
+}
+
+// rekeyer 逐个文件换钥并统计结果，旧密钥按名称缓存，受保护的私钥只需输入一次口令
+type rekeyer struct {
+	cfg        *config.Config
+	algorithm  *crypto.Algorithm
+	target     *crypto.RSAServiceInterface
+	identities map[string]*crypto.RSAServiceInterface
+
+	rotated, skipped, failed int
+}
+
+// rekeyFile 依次尝试可能的旧密钥：头部记录了指纹时只用匹配的密钥，旧文件没有指纹时尝试密钥环中的全部 RSA 密钥
+func (r *rekeyer) rekeyFile(path string) {
+	var err error
+	for _, name := range r.candidateKeys(path) {
+		var identity *crypto.RSAServiceInterface
+		if identity, err = r.identity(name); err != nil {
+			continue
+		}
+		err = r.target.RekeyFile(path, identity)
+		if cryptoErr, ok := err.(*errors.CryptoErrorInterface); !ok || (cryptoErr.Code != errors.ErrWrongKey && cryptoErr.Code != errors.ErrDecryptionFailed) {
+			break
+		}
+	}
+
+	switch e := err.(type) {
+	case nil:
+		r.rotated++
+		fmt.Printf("✅ %s\n", path)
+	case *crypto.RekeySkipped:
+		r.skipped++
+		fmt.Printf("⏭️  %s: %s\n", path, e.Reason)
+	default:
+		r.fail(path, err)
+	}
+}
+
+// candidateKeys 返回可能解开文件的本地 RSA 密钥名称
+func (r *rekeyer) candidateKeys(path string) []string {
+	current := r.cfg.KeyName(r.algorithm.Name)
+	header, err := format.ReadFile(path)
+	if err != nil || header == nil {
+		return []string{current}
+	}
+
+	fingerprints := header.GetAll(format.TagKeyFingerprint)
+	if len(fingerprints) == 0 {
+		if names := r.cfg.KeyNames(r.algorithm); len(names) > 0 {
+			return names
+		}
+		return []string{current}
+	}
+	if name, ok := r.cfg.MatchingKey(r.algorithm, fingerprints, nil); ok {
+		return []string{name}
+	}
+	// 没有匹配的密钥时仍用当前密钥尝试，以便报告文件对应的密钥指纹
+	return []string{current}
+}
+
+// identity 加载命名密钥作为旧密钥
+func (r *rekeyer) identity(name string) (*crypto.RSAServiceInterface, error) {
+	if identity, ok := r.identities[name]; ok {
+		return identity, nil
+	}
+
+	paths := r.cfg.NamedKeyPaths(r.algorithm, name)
+	identity, err := crypto.RSAService(&crypto.RSAConfig{
+		PublicKeyPath:  paths["public_key"],
+		PrivateKeyPath: paths["private_key"],
+		Passphrase:     keyPassphrase(r.cfg),
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.identities[name] = identity
+	return identity, nil
+}
+
+// fail 记录失败的文件
+func (r *rekeyer) fail(path string, err error) {
+	r.failed++
+	fmt.Fprintf(os.Stderr, "❌ %s: %v\n", path, err)
+}
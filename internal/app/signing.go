@@ -0,0 +1,45 @@
+package app
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"hycrypt/internal/config"
+	"hycrypt/internal/crypto"
+)
+
+// signingConfig 根据配置构建签名参数，未启用签名且无受信任签名者时返回 nil
+func signingConfig(cfg *config.Config) *crypto.SigningConfig {
+	if !cfg.Encryption.Sign && !cfg.Encryption.RequireSignature &&
+		len(cfg.Keys.TrustedSigners) == 0 && !cfg.CheckSigningKeyExists() {
+		return nil
+	}
+
+	return &crypto.SigningConfig{
+		PrivateKeyPath:   cfg.GetSigningKeyPath(),
+		Sign:             cfg.Encryption.Sign,
+		TrustedKeyPaths:  cfg.GetTrustedSignerPaths(),
+		RequireSignature: cfg.Encryption.RequireSignature,
+	}
+}
+
+// ensureSigningKey 启用签名且签名密钥缺失时生成新的 Ed25519 密钥对
+func ensureSigningKey(cfg *config.Config) error {
+	if !cfg.Encryption.Sign || cfg.CheckSigningKeyExists() {
+		return nil
+	}
+
+	fmt.Println("🔧 检测到签名密钥缺失, 正在生成...")
+	privateKey, err := crypto.GenerateEd25519Key()
+	if err != nil {
+		return fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	publicText := crypto.EncodeEd25519PublicKey(privateKey.Public().(ed25519.PublicKey))
+	if err := cfg.SaveSigningKeys(publicText, crypto.EncodeEd25519PrivateKey(privateKey)); err != nil {
+		return fmt.Errorf("failed to save signing keys: %w", err)
+	}
+
+	fmt.Printf("✅ Ed25519 签名密钥已生成并保存到: %s\n", cfg.GetKeyDirPath())
+	fmt.Printf("✍️  签名公钥: %s\n", publicText)
+	return nil
+}
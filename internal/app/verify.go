@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"hycrypt/internal/config"
+	"hycrypt/internal/domain"
+	"os"
+)
+
+const verifyUsage = `用法:
+  hycrypt verify <文件或目录...>
+  hycrypt verify -f=<文件或目录>`
+
+// RunVerifyCommand 校验加密文件：用本地密钥完整认证每个文件（含签名），明文直接丢弃不写入磁盘
+func RunVerifyCommand(cfg *config.Config, opts *Options, args []string) error {
+	targets := args
+	if opts.FilePath != "" {
+		targets = append(targets, opts.FilePath)
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("%s", verifyUsage)
+	}
+
+	v := &verifier{processorCache: newProcessorCache(cfg, opts)}
+	for _, path := range targets {
+		forEachEncryptedFile(cfg, path, v.verifyFile, v.fail)
+	}
+
+	fmt.Printf("\n🔎 校验完成: 通过 %d 个，失败 %d 个\n", v.passed, v.failed)
+	if v.failed > 0 {
+		return fmt.Errorf("%d 个文件校验失败", v.failed)
+	}
+	return nil
+}
+
+// verifier 逐个文件校验并统计结果
+type verifier struct {
+	*processorCache
+
+	passed, failed int
+}
+
+// verifyFile 按文件头部选择算法和密钥，解密到丢弃输出
+func (v *verifier) verifyFile(path string) {
+	processor, method, err := v.forFile(path)
+	if err != nil {
+		v.fail(path, err)
+		return
+	}
+	result, err := processor.VerifyFile(context.Background(), path, domain.CryptoOptions{Method: method})
+	if err != nil {
+		v.fail(path, err)
+		return
+	}
+
+	v.passed++
+	if result.Signer != "" {
+		fmt.Printf("✅ %s（签名者 %s）\n", path, result.Signer)
+		return
+	}
+	fmt.Printf("✅ %s\n", path)
+}
+
+// fail 记录校验失败的文件
+func (v *verifier) fail(path string, err error) {
+	v.failed++
+	fmt.Fprintf(os.Stderr, "❌ %s: %v\n", path, err)
+}
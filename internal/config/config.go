@@ -1,13 +1,15 @@
 package config
 
 import (
-	"encoding/hex"
 	"fmt"
 	"hycrypt/internal/constants"
+	"hycrypt/internal/crypto"
+	"hycrypt/internal/format"
 	"os"
 	"os/exec"
 	"os/user"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -20,6 +22,9 @@ type Config struct {
 	Directories DirConfig        `yaml:"directories"`
 	Encryption  EncryptionConfig `yaml:"encryption"`
 	Output      OutputConfig     `yaml:"output"`
+
+	// selectedKeys 本次运行中选择的密钥名称（算法 → 名称），不写入配置文件
+	selectedKeys map[string]string
 }
 
 // KeyConfig 密钥相关配置
@@ -28,6 +33,39 @@ type KeyConfig struct {
 	PublicKey  string `yaml:"public_key"`
 	PrivateKey string `yaml:"private_key"`
 	KMACKey    string `yaml:"kmac_key"`
+
+	// RSARecipients 额外接收方的 RSA 公钥文件（PEM 或 authorized_keys 格式），相对路径基于密钥目录
+	RSARecipients []string `yaml:"rsa_recipients,omitempty"`
+
+	// X25519 密钥文件名，公钥为可分享的短文本
+	X25519PublicKey  string `yaml:"x25519_public_key"`
+	X25519PrivateKey string `yaml:"x25519_private_key"`
+
+	// ML-KEM-768 + X25519 抗量子混合密钥文件名（PEM）
+	MLKEMPublicKey  string `yaml:"mlkem_public_key"`
+	MLKEMPrivateKey string `yaml:"mlkem_private_key"`
+
+	// Ed25519 签名密钥文件名，公钥可分享给接收方用于验签
+	SigningKey       string `yaml:"signing_key"`
+	SigningPublicKey string `yaml:"signing_public_key"`
+
+	// TrustedSigners 受信任签名者公钥文件（每行一个），相对路径基于密钥目录
+	TrustedSigners []string `yaml:"trusted_signers,omitempty"`
+
+	// AgeRecipients age 输出的额外接收方：age1... 公钥或接收方文件，相对路径基于密钥目录
+	AgeRecipients []string `yaml:"age_recipients,omitempty"`
+
+	// AgeIdentities age 身份文件（AGE-SECRET-KEY-1...），相对路径基于密钥目录
+	AgeIdentities []string `yaml:"age_identities,omitempty"`
+
+	// KeyringDir 密钥环目录，每个命名密钥一个子目录，相对路径基于密钥目录
+	KeyringDir string `yaml:"keyring_dir,omitempty"`
+
+	// DefaultKeys 各算法默认使用的命名密钥（算法 → 名称），未配置时使用密钥目录顶层的密钥文件
+	DefaultKeys map[string]string `yaml:"default_keys,omitempty"`
+
+	// PassphraseCommand 输出受保护密钥文件口令的命令（经 sh -c 执行，密钥路径通过 HYCRYPT_KEY_FILE 传入）
+	PassphraseCommand string `yaml:"passphrase_command,omitempty"`
 }
 
 // DirConfig 目录相关配置
@@ -44,6 +82,30 @@ type EncryptionConfig struct {
 	AESKeySize       int      `yaml:"aes_key_size"`
 	KMACKeySize      int      `yaml:"kmac_key_size"`
 	FileExtension    string   `yaml:"file_extension"`
+
+	// AEAD 数据加密算法: aes-gcm、chacha20-poly1305 或 xchacha20-poly1305，记录在文件头部
+	AEAD string `yaml:"aead"`
+
+	// Padding 长度隐藏填充方案: none、padme 或 bucket，记录在文件头部，解密时自动去除
+	Padding string `yaml:"padding"`
+
+	// Compression 加密前压缩: none、gzip 或 zstd，记录在文件头部，解密时自动解压；启用填充时不压缩
+	Compression string `yaml:"compression"`
+	// CompressionLevel 压缩级别（gzip 1-9，zstd 1-22），0 为编码默认级别
+	CompressionLevel int `yaml:"compression_level"`
+
+	// Workers 分块并发加解密的块数，0 为 CPU 核数；密文与并发数无关
+	Workers int `yaml:"workers"`
+
+	// Argon2id 口令派生参数（password 算法），加密时写入文件头部
+	Argon2Time      uint32 `yaml:"argon2_time"`
+	Argon2MemoryKiB uint32 `yaml:"argon2_memory_kib"`
+	Argon2Threads   uint8  `yaml:"argon2_threads"`
+
+	// Sign 加密时使用 Ed25519 签名密钥签名
+	Sign bool `yaml:"sign"`
+	// RequireSignature 解密时拒绝未签名或签名者不受信任的文件
+	RequireSignature bool `yaml:"require_signature"`
 }
 
 // OutputConfig 输出相关配置
@@ -62,18 +124,35 @@ func Default() *Config {
 			PublicKey:  "public.pem",
 			PrivateKey: "private.pem",
 			KMACKey:    "kmac.key", // 默认 KMAC 密钥文件名
+
+			X25519PublicKey:  "x25519.pub",
+			X25519PrivateKey: "x25519.key",
+
+			MLKEMPublicKey:  "mlkem-public.pem",
+			MLKEMPrivateKey: "mlkem-private.pem",
+
+			SigningKey:       "signing.key",
+			SigningPublicKey: "signing.pub",
+
+			KeyringDir: "keyring",
 		},
 		Directories: DirConfig{
 			EncryptedDir: "encrypted",
 			DecryptedDir: "decrypted",
 		},
 		Encryption: EncryptionConfig{
-			Method:           constants.AlgorithmRSA,        // 默认使用 RSA
-			SupportedMethods: constants.SupportedAlgorithms, // 支持的算法列表
-			RSAKeySize:       4096,                          // RSA-4096
-			AESKeySize:       32,                            // AES-256
-			KMACKeySize:      32,                            // KMAC 密钥 256 位
+			Method:           constants.AlgorithmRSA,  // 默认使用 RSA
+			SupportedMethods: crypto.AlgorithmNames(), // 已注册的全部算法
+			RSAKeySize:       4096,                    // RSA-4096
+			AESKeySize:       32,                      // AES-256
+			KMACKeySize:      32,                      // KMAC 密钥 256 位
 			FileExtension:    ".hycrypt",
+			AEAD:             constants.AEADAESGCM,
+			Padding:          constants.PaddingNone,
+			Compression:      constants.CompressionNone,
+			Argon2Time:       3,         // Argon2id 迭代次数
+			Argon2MemoryKiB:  64 * 1024, // 64 MiB
+			Argon2Threads:    4,
 		},
 		Output: OutputConfig{
 			Verbose:       false,
@@ -130,17 +209,86 @@ func Save(config *Config, configPath string) error {
 
 // GetPublicKeyPath 获取公钥文件完整路径
 func (c *Config) GetPublicKeyPath() string {
-	return filepath.Join(c.GetKeyDirPath(), c.Keys.PublicKey)
+	return c.GetKeyFilePath("public_key")
 }
 
 // GetPrivateKeyPath 获取私钥文件完整路径
 func (c *Config) GetPrivateKeyPath() string {
-	return filepath.Join(c.GetKeyDirPath(), c.Keys.PrivateKey)
+	return c.GetKeyFilePath("private_key")
+}
+
+// GetRSARecipientPaths 获取额外 RSA 接收方公钥文件完整路径
+func (c *Config) GetRSARecipientPaths() []string {
+	paths := make([]string, 0, len(c.Keys.RSARecipients))
+	for _, recipient := range c.Keys.RSARecipients {
+		paths = append(paths, c.resolveKeyPath(recipient))
+	}
+	return paths
 }
 
 // GetKMACKeyPath 获取 KMAC 密钥文件完整路径
 func (c *Config) GetKMACKeyPath() string {
-	return filepath.Join(c.GetKeyDirPath(), c.Keys.KMACKey)
+	return c.GetKeyFilePath("kmac_key")
+}
+
+// GetX25519PublicKeyPath 获取 X25519 公钥文件完整路径
+func (c *Config) GetX25519PublicKeyPath() string {
+	return c.GetKeyFilePath("x25519_public_key")
+}
+
+// GetX25519PrivateKeyPath 获取 X25519 私钥文件完整路径
+func (c *Config) GetX25519PrivateKeyPath() string {
+	return c.GetKeyFilePath("x25519_private_key")
+}
+
+// GetMLKEMPublicKeyPath 获取 ML-KEM 混合公钥文件完整路径
+func (c *Config) GetMLKEMPublicKeyPath() string {
+	return c.GetKeyFilePath("mlkem_public_key")
+}
+
+// GetMLKEMPrivateKeyPath 获取 ML-KEM 混合私钥文件完整路径
+func (c *Config) GetMLKEMPrivateKeyPath() string {
+	return c.GetKeyFilePath("mlkem_private_key")
+}
+
+// GetSigningKeyPath 获取 Ed25519 签名私钥文件完整路径
+func (c *Config) GetSigningKeyPath() string {
+	return c.resolveKeyPath(c.Keys.SigningKey)
+}
+
+// GetSigningPublicKeyPath 获取 Ed25519 签名公钥文件完整路径
+func (c *Config) GetSigningPublicKeyPath() string {
+	return c.resolveKeyPath(c.Keys.SigningPublicKey)
+}
+
+// GetTrustedSignerPaths 获取受信任签名者公钥文件完整路径
+func (c *Config) GetTrustedSignerPaths() []string {
+	paths := make([]string, 0, len(c.Keys.TrustedSigners))
+	for _, signer := range c.Keys.TrustedSigners {
+		paths = append(paths, c.resolveKeyPath(signer))
+	}
+	return paths
+}
+
+// GetAgeRecipients 获取 age 额外接收方，文件路径转换为完整路径
+func (c *Config) GetAgeRecipients() []string {
+	recipients := make([]string, 0, len(c.Keys.AgeRecipients))
+	for _, recipient := range c.Keys.AgeRecipients {
+		if !crypto.IsInlineAgeRecipient(recipient) {
+			recipient = c.resolveKeyPath(recipient)
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients
+}
+
+// GetAgeIdentityPaths 获取 age 身份文件完整路径
+func (c *Config) GetAgeIdentityPaths() []string {
+	paths := make([]string, 0, len(c.Keys.AgeIdentities))
+	for _, identity := range c.Keys.AgeIdentities {
+		paths = append(paths, c.resolveKeyPath(identity))
+	}
+	return paths
 }
 
 // Validate 验证配置的有效性
@@ -167,28 +315,29 @@ func (c *Config) Validate() error {
 			c.Encryption.Method, c.Encryption.SupportedMethods)
 	}
 
-	// 根据加密方法验证相关配置
-	if c.Encryption.Method == constants.AlgorithmRSA {
-		if c.Keys.PublicKey == "" {
-			return fmt.Errorf("public key filename cannot be empty in RSA mode")
-		}
-		if c.Keys.PrivateKey == "" {
-			return fmt.Errorf("private key filename cannot be empty in RSA mode")
-		}
-		if c.Encryption.RSAKeySize != 2048 && c.Encryption.RSAKeySize != 3072 && c.Encryption.RSAKeySize != 4096 {
-			return fmt.Errorf("RSA key size must be 2048, 3072 or 4096 bits")
+	// 根据注册表验证加密方法所需的密钥文件和参数
+	algorithm, ok := crypto.LookupAlgorithm(c.Encryption.Method)
+	if !ok {
+		return fmt.Errorf("unsupported encryption method: %s", c.Encryption.Method)
+	}
+	for _, keyFile := range algorithm.KeyFiles {
+		if c.keyFileName(keyFile.Name) == "" {
+			return fmt.Errorf("%s filename cannot be empty in %s mode", keyFile.Name, algorithm.DisplayName)
 		}
-	} else if c.Encryption.Method == constants.AlgorithmKMAC {
-		if c.Keys.KMACKey == "" {
-			return fmt.Errorf("KMAC key filename cannot be empty in KMAC mode")
+	}
+	if algorithm.Validate != nil {
+		if err := algorithm.Validate(c.AlgorithmParameters()); err != nil {
+			return err
 		}
-		// 检查 KMAC 密钥文件是否存在
-		kmacKeyPath := c.GetKMACKeyPath()
-		if _, err := os.Stat(kmacKeyPath); os.IsNotExist(err) {
-			return fmt.Errorf("KMAC key file not found: %s", kmacKeyPath)
+	}
+
+	// 验证各算法的默认命名密钥
+	for method, name := range c.Keys.DefaultKeys {
+		if !crypto.IsRegisteredAlgorithm(method) {
+			return fmt.Errorf("default_keys: unsupported encryption method: %s", method)
 		}
-		if c.Encryption.KMACKeySize != 16 && c.Encryption.KMACKeySize != 32 && c.Encryption.KMACKeySize != 64 {
-			return fmt.Errorf("KMAC key size must be 16, 32 or 64 bytes")
+		if err := ValidateKeyName(name); err != nil {
+			return fmt.Errorf("default_keys: %w", err)
 		}
 	}
 
@@ -208,6 +357,22 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("file extension cannot be empty")
 	}
 
+	if !constants.IsValidAEAD(c.Encryption.AEAD) {
+		return fmt.Errorf("aead must be one of %v, got '%s'", constants.SupportedAEADs, c.Encryption.AEAD)
+	}
+
+	if !constants.IsValidPadding(c.Encryption.Padding) {
+		return fmt.Errorf("padding must be one of %v, got '%s'", constants.SupportedPaddings, c.Encryption.Padding)
+	}
+
+	if err := crypto.ValidateCompression(c.Encryption.Compression, c.Encryption.CompressionLevel); err != nil {
+		return err
+	}
+
+	if c.Encryption.Workers < 0 || c.Encryption.Workers > crypto.MaxWorkers {
+		return fmt.Errorf("workers must be between 0 and %d, got %d", crypto.MaxWorkers, c.Encryption.Workers)
+	}
+
 	return nil
 }
 
@@ -215,6 +380,16 @@ func (c *Config) Validate() error {
 type CLIOptions interface {
 	GetKeyDir() string
 	GetMethod() string
+	GetKey() string
+	GetAEAD() string
+	GetPadding() string
+	GetCompression() string
+	GetCompressionLevel() int
+	GetWorkers() int
+	GetRecipients() []string
+	GetAgeRecipients() []string
+	GetAgeIdentities() []string
+	GetSign() bool
 	GetVerbose() bool
 }
 
@@ -225,6 +400,53 @@ func (c *Config) ApplyOverrides(opts CLIOptions) {
 	if method := opts.GetMethod(); method != "" {
 		c.Encryption.Method = method
 	}
+	if aead := opts.GetAEAD(); aead != "" {
+		c.Encryption.AEAD = aead
+	}
+	if padding := opts.GetPadding(); padding != "" {
+		c.Encryption.Padding = padding
+	}
+	if compression := opts.GetCompression(); compression != "" {
+		c.Encryption.Compression = compression
+	}
+	if level := opts.GetCompressionLevel(); level != 0 {
+		c.Encryption.CompressionLevel = level
+	}
+	if workers := opts.GetWorkers(); workers != 0 {
+		c.Encryption.Workers = workers
+	}
+	// 命名密钥对所有使用密钥文件的算法生效，名称已由调用方校验
+	if key := opts.GetKey(); key != "" {
+		for _, algorithm := range crypto.Algorithms() {
+			if len(algorithm.KeyFiles) > 0 {
+				c.SelectKey(algorithm.Name, key)
+			}
+		}
+	}
+	// 命令行指定的接收方相对当前目录解析
+	for _, recipient := range opts.GetRecipients() {
+		if abs, err := filepath.Abs(recipient); err == nil {
+			recipient = abs
+		}
+		c.Keys.RSARecipients = append(c.Keys.RSARecipients, recipient)
+	}
+	for _, recipient := range opts.GetAgeRecipients() {
+		if !crypto.IsInlineAgeRecipient(recipient) {
+			if abs, err := filepath.Abs(recipient); err == nil {
+				recipient = abs
+			}
+		}
+		c.Keys.AgeRecipients = append(c.Keys.AgeRecipients, recipient)
+	}
+	for _, identity := range opts.GetAgeIdentities() {
+		if abs, err := filepath.Abs(identity); err == nil {
+			identity = abs
+		}
+		c.Keys.AgeIdentities = append(c.Keys.AgeIdentities, identity)
+	}
+	if opts.GetSign() {
+		c.Encryption.Sign = true
+	}
 	if opts.GetVerbose() {
 		c.Output.Verbose = true
 	}
@@ -255,7 +477,19 @@ func CreateStandardDetector(supportedAlgorithms []string, fileExtension string)
 }
 
 // DetectFromPath 从文件路径检测算法
+// 优先读取文件头部（含 age 格式），仅对没有头部的旧格式文件回退到文件名匹配
 func (d *StandardAlgorithmDetector) DetectFromPath(filePath string) string {
+	algorithm := format.DetectAlgorithm(filePath)
+	if algorithm == "" {
+		algorithm = crypto.DetectAgeMethod(filePath)
+	}
+	if algorithm != "" {
+		if d.IsSupported(algorithm) {
+			return algorithm
+		}
+		return ""
+	}
+
 	fileName := filepath.Base(filePath)
 
 	// 构建支持的算法列表的正则表达式，使用简单的 | 分隔符
@@ -530,6 +764,20 @@ func (c *Config) GetKeyDirPath() string {
 	return keyDir
 }
 
+// resolveKeyPath 解析密钥文件路径：绝对路径和 ~/ 开头的路径原样使用（如 ~/.ssh/id_ed25519），
+// 其余基于密钥目录
+func (c *Config) resolveKeyPath(name string) string {
+	if rest, ok := strings.CutPrefix(name, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(c.GetKeyDirPath(), name)
+}
+
 // GetEncryptedDirPath 获取加密输出目录完整路径
 func (c *Config) GetEncryptedDirPath() string {
 	dirPath := c.Directories.EncryptedDir
@@ -554,58 +802,245 @@ func (c *Config) GetDecryptedDirPath() string {
 	return dirPath
 }
 
-// LoadKMACKey 从独立文件加载 KMAC 密钥
+// LoadKMACKey 从独立文件加载未受口令保护的 KMAC 密钥
 func (c *Config) LoadKMACKey() ([]byte, error) {
+	return c.LoadKMACKeyWithPassphrase(nil)
+}
+
+// LoadKMACKeyWithPassphrase 从独立文件加载 KMAC 密钥，受口令保护时通过 passphrase 获取口令
+func (c *Config) LoadKMACKeyWithPassphrase(passphrase crypto.PassphraseFunc) ([]byte, error) {
+	return crypto.LoadKMACKey(c.GetKMACKeyPath(), c.Encryption.KMACKeySize, passphrase)
+}
+
+// SaveKMACKey 保存 KMAC 密钥到独立文件，passphrase 非空时加密保存
+func (c *Config) SaveKMACKey(keyBytes, passphrase []byte) error {
 	kmacKeyPath := c.GetKMACKeyPath()
 
-	// 读取密钥文件
-	keyData, err := os.ReadFile(kmacKeyPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read KMAC key file: %w", err)
+	// 确保密钥所在目录存在（命名密钥位于密钥环子目录）
+	if err := os.MkdirAll(filepath.Dir(kmacKeyPath), 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
 	}
 
-	// 去除可能的换行符
-	keyStr := string(keyData)
-	keyStr = strings.TrimSpace(keyStr)
-
-	// 验证十六进制格式
-	keyBytes, err := hex.DecodeString(keyStr)
+	// 无口令时为十六进制字符串
+	keyData, err := crypto.EncodeKMACKey(keyBytes, passphrase)
 	if err != nil {
-		return nil, fmt.Errorf("invalid KMAC key format: %w", err)
+		return err
 	}
 
-	// 验证密钥长度
-	if len(keyBytes) != c.Encryption.KMACKeySize {
-		return nil, fmt.Errorf("KMAC key length mismatch: expected %d bytes, got %d", c.Encryption.KMACKeySize, len(keyBytes))
+	// 写入密钥文件
+	if err := os.WriteFile(kmacKeyPath, keyData, 0600); err != nil {
+		return fmt.Errorf("failed to write KMAC key file: %w", err)
 	}
 
-	return keyBytes, nil
+	return nil
 }
 
-// SaveKMACKey 保存 KMAC 密钥到独立文件
-func (c *Config) SaveKMACKey(keyBytes []byte) error {
+// CheckKMACKeyExists 检查 KMAC 密钥文件是否存在
+func (c *Config) CheckKMACKeyExists() bool {
 	kmacKeyPath := c.GetKMACKeyPath()
+	_, err := os.Stat(kmacKeyPath)
+	return err == nil
+}
 
+// IsKMACKeyProtected 检查 KMAC 密钥文件是否受口令保护
+func (c *Config) IsKMACKeyProtected() bool {
+	return crypto.IsProtectedKeyFile(c.GetKMACKeyPath())
+}
+
+// SaveX25519Keys 保存文本编码的 X25519 密钥对
+func (c *Config) SaveX25519Keys(publicKey, privateKey string) error {
 	// 确保密钥目录存在
 	keyDir := c.GetKeyDirPath()
 	if err := os.MkdirAll(keyDir, 0700); err != nil {
 		return fmt.Errorf("failed to create key directory: %w", err)
 	}
 
-	// 将密钥转换为十六进制字符串
-	keyStr := hex.EncodeToString(keyBytes)
+	if err := os.WriteFile(c.GetX25519PrivateKeyPath(), []byte(privateKey+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write X25519 private key file: %w", err)
+	}
 
-	// 写入密钥文件
-	if err := os.WriteFile(kmacKeyPath, []byte(keyStr), 0600); err != nil {
-		return fmt.Errorf("failed to write KMAC key file: %w", err)
+	if err := os.WriteFile(c.GetX25519PublicKeyPath(), []byte(publicKey+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write X25519 public key file: %w", err)
 	}
 
 	return nil
 }
 
-// CheckKMACKeyExists 检查 KMAC 密钥文件是否存在
-func (c *Config) CheckKMACKeyExists() bool {
-	kmacKeyPath := c.GetKMACKeyPath()
-	_, err := os.Stat(kmacKeyPath)
+// CheckX25519KeysExist 检查 X25519 密钥文件是否存在
+func (c *Config) CheckX25519KeysExist() bool {
+	_, pubErr := os.Stat(c.GetX25519PublicKeyPath())
+	_, privErr := os.Stat(c.GetX25519PrivateKeyPath())
+	return pubErr == nil || privErr == nil
+}
+
+// SaveMLKEMKeys 保存 PEM 编码的 ML-KEM 混合密钥对
+func (c *Config) SaveMLKEMKeys(publicPEM, privatePEM []byte) error {
+	// 确保密钥目录存在
+	keyDir := c.GetKeyDirPath()
+	if err := os.MkdirAll(keyDir, 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	if err := os.WriteFile(c.GetMLKEMPrivateKeyPath(), privatePEM, 0600); err != nil {
+		return fmt.Errorf("failed to write ML-KEM private key file: %w", err)
+	}
+
+	if err := os.WriteFile(c.GetMLKEMPublicKeyPath(), publicPEM, 0644); err != nil {
+		return fmt.Errorf("failed to write ML-KEM public key file: %w", err)
+	}
+
+	return nil
+}
+
+// CheckMLKEMKeysExist 检查 ML-KEM 混合密钥文件是否存在
+func (c *Config) CheckMLKEMKeysExist() bool {
+	_, pubErr := os.Stat(c.GetMLKEMPublicKeyPath())
+	_, privErr := os.Stat(c.GetMLKEMPrivateKeyPath())
+	return pubErr == nil || privErr == nil
+}
+
+// SaveSigningKeys 保存文本编码的 Ed25519 签名密钥对
+func (c *Config) SaveSigningKeys(publicKey, privateKey string) error {
+	// 确保密钥目录存在
+	keyDir := c.GetKeyDirPath()
+	if err := os.MkdirAll(keyDir, 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	if err := os.WriteFile(c.GetSigningKeyPath(), []byte(privateKey+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write signing key file: %w", err)
+	}
+
+	if err := os.WriteFile(c.GetSigningPublicKeyPath(), []byte(publicKey+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write signing public key file: %w", err)
+	}
+
+	return nil
+}
+
+// CheckSigningKeyExists 检查 Ed25519 签名私钥文件是否存在
+func (c *Config) CheckSigningKeyExists() bool {
+	_, err := os.Stat(c.GetSigningKeyPath())
 	return err == nil
 }
+
+// keyFileName 按 yaml 键名返回 keys 配置中的密钥文件名，未知键返回空字符串
+func (c *Config) keyFileName(name string) string {
+	keys := reflect.ValueOf(c.Keys)
+	for i := 0; i < keys.NumField(); i++ {
+		field := keys.Type().Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if tag == name && field.Type.Kind() == reflect.String {
+			return keys.Field(i).String()
+		}
+	}
+	return ""
+}
+
+// GetKeyFilePath 按 yaml 键名获取密钥文件完整路径，如 "public_key"
+// 算法密钥文件按该算法当前选择的命名密钥解析
+func (c *Config) GetKeyFilePath(name string) string {
+	fileName := c.keyFileName(name)
+	if fileName == "" {
+		return ""
+	}
+	if algorithm := keyFileAlgorithm(name); algorithm != "" {
+		return c.namedKeyFilePath(c.KeyName(algorithm), fileName)
+	}
+	return c.resolveKeyPath(fileName)
+}
+
+// AlgorithmParameters 返回算法校验和密钥生成使用的参数
+func (c *Config) AlgorithmParameters() crypto.Parameters {
+	return crypto.Parameters{
+		AESKeySize:      c.Encryption.AESKeySize,
+		RSAKeySize:      c.Encryption.RSAKeySize,
+		KMACKeySize:     c.Encryption.KMACKeySize,
+		Argon2Time:      c.Encryption.Argon2Time,
+		Argon2MemoryKiB: c.Encryption.Argon2MemoryKiB,
+		Argon2Threads:   c.Encryption.Argon2Threads,
+	}
+}
+
+// CheckAlgorithmKeysExist 检查算法的任一密钥文件是否存在
+func (c *Config) CheckAlgorithmKeysExist(algorithm *crypto.Algorithm) bool {
+	for _, keyFile := range algorithm.KeyFiles {
+		if _, err := os.Stat(c.GetKeyFilePath(keyFile.Name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigureAlgorithms 由各算法按当前配置构建算法配置并存入处理器配置，受保护的私钥通过 passphrase 获取口令
+// 始终配置当前选择的算法，提供 password 时配置口令算法；withExistingKeys 为 true 时同时配置密钥文件已存在的其他算法
+func (c *Config) ConfigureAlgorithms(processorConfig *crypto.ProcessorConfig, passphrase crypto.PassphraseFunc, password []byte, withExistingKeys bool) error {
+	for _, algorithm := range crypto.Algorithms() {
+		if algorithm.Configure == nil {
+			continue
+		}
+		selected := algorithm.Name == c.Encryption.Method
+		withPassword := algorithm.NeedsPassword && len(password) > 0
+		if !selected && !withPassword && (!withExistingKeys || !c.CheckAlgorithmKeysExist(algorithm)) {
+			continue
+		}
+
+		algorithmConfig, err := algorithm.Configure(c, crypto.ConfigureOptions{
+			Selected:   selected,
+			Passphrase: passphrase,
+			Password:   password,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to load %s keys: %w", algorithm.DisplayName, err)
+		}
+		if algorithmConfig == nil {
+			continue
+		}
+		if processorConfig.Algorithms == nil {
+			processorConfig.Algorithms = make(map[string]any)
+		}
+		processorConfig.Algorithms[algorithm.Name] = algorithmConfig
+	}
+	return nil
+}
+
+// KeyFingerprint 读取算法的密钥文件计算密钥指纹，受口令保护时通过 passphrase 获取口令
+func (c *Config) KeyFingerprint(algorithm *crypto.Algorithm, passphrase crypto.PassphraseFunc) ([]byte, error) {
+	return c.NamedKeyFingerprint(algorithm, c.KeyName(algorithm.Name), passphrase)
+}
+
+// GenerateAlgorithmKeys 生成并保存算法所需的密钥文件，返回写入的文件路径
+func (c *Config) GenerateAlgorithmKeys(algorithm *crypto.Algorithm) ([]string, error) {
+	if algorithm.GenerateKeys == nil {
+		return nil, fmt.Errorf("%s does not use key files", algorithm.DisplayName)
+	}
+
+	files, err := algorithm.GenerateKeys(c.AlgorithmParameters())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s keys: %w", algorithm.DisplayName, err)
+	}
+
+	paths := make([]string, 0, len(algorithm.KeyFiles))
+	for _, keyFile := range algorithm.KeyFiles {
+		path := c.GetKeyFilePath(keyFile.Name)
+		if path == "" {
+			return nil, fmt.Errorf("%s filename is not configured", keyFile.Name)
+		}
+
+		// 确保密钥所在目录存在（命名密钥位于密钥环子目录）
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create key directory: %w", err)
+		}
+
+		perm := os.FileMode(0644)
+		if keyFile.Private {
+			perm = 0600
+		}
+		if err := os.WriteFile(path, files[keyFile.Name], perm); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", keyFile.Name, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
@@ -4,11 +4,12 @@ import (
 	"testing"
 
 	"hycrypt/internal/constants"
+	"hycrypt/internal/crypto"
 )
 
 func TestStandardAlgorithmDetector(t *testing.T) {
 	// 创建支持 RSA 和 KMAC 的检测器
-	detector := CreateStandardDetector(constants.SupportedAlgorithms, ".encrypted")
+	detector := CreateStandardDetector(crypto.AlgorithmNames(), ".encrypted")
 
 	tests := []struct {
 		name     string
@@ -78,7 +79,7 @@ func TestConfigAlgorithmDetection(t *testing.T) {
 
 	// 测试默认配置支持的算法
 	supportedAlgs := config.GetSupportedAlgorithms()
-	expectedAlgs := constants.SupportedAlgorithms
+	expectedAlgs := crypto.AlgorithmNames()
 
 	if len(supportedAlgs) != len(expectedAlgs) {
 		t.Errorf("Expected %d supported algorithms, got %d", len(expectedAlgs), len(supportedAlgs))
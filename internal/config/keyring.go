@@ -0,0 +1,178 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"hycrypt/internal/crypto"
+	"hycrypt/internal/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// DefaultKeyName 密钥目录顶层的密钥文件（keys 配置中的文件名）对应的密钥名称
+const DefaultKeyName = "default"
+
+// keyNamePattern 密钥名称只允许字母、数字、点、下划线和连字符，且不能以符号开头
+var keyNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateKeyName 校验密钥名称，名称用作密钥环下的目录名
+func ValidateKeyName(name string) error {
+	if !keyNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid key name %q: use letters, digits, '.', '_' or '-'", name)
+	}
+	return nil
+}
+
+// GetKeyringDirPath 获取密钥环目录完整路径，每个命名密钥占用其中一个子目录
+func (c *Config) GetKeyringDirPath() string {
+	return c.resolveKeyPath(c.Keys.KeyringDir)
+}
+
+// KeyName 获取算法当前使用的密钥名称：命令行或界面选择的密钥优先，其次为 default_keys 配置
+func (c *Config) KeyName(algorithm string) string {
+	if name := c.selectedKeys[algorithm]; name != "" {
+		return name
+	}
+	if name := c.Keys.DefaultKeys[algorithm]; name != "" {
+		return name
+	}
+	return DefaultKeyName
+}
+
+// SelectKey 为算法选择密钥，仅在本次运行中生效；name 为空时恢复默认密钥
+func (c *Config) SelectKey(algorithm, name string) error {
+	if name == "" {
+		delete(c.selectedKeys, algorithm)
+		return nil
+	}
+	if err := ValidateKeyName(name); err != nil {
+		return err
+	}
+	if c.selectedKeys == nil {
+		c.selectedKeys = make(map[string]string)
+	}
+	c.selectedKeys[algorithm] = name
+	return nil
+}
+
+// keyFileAlgorithm 返回拥有该密钥文件（yaml 键名）的算法名称，非算法密钥文件返回空字符串
+func keyFileAlgorithm(name string) string {
+	for _, algorithm := range crypto.Algorithms() {
+		for _, keyFile := range algorithm.KeyFiles {
+			if keyFile.Name == name {
+				return algorithm.Name
+			}
+		}
+	}
+	return ""
+}
+
+// namedKeyFilePath 获取命名密钥的密钥文件路径：默认密钥使用 keys 配置中的路径，
+// 其余密钥位于密钥环子目录中，文件名与默认密钥相同
+func (c *Config) namedKeyFilePath(keyName, fileName string) string {
+	if keyName == DefaultKeyName {
+		return c.resolveKeyPath(fileName)
+	}
+	return filepath.Join(c.GetKeyringDirPath(), keyName, filepath.Base(fileName))
+}
+
+// NamedKeyPaths 获取命名密钥的全部密钥文件路径，键为 yaml 键名（如 "public_key"）
+func (c *Config) NamedKeyPaths(algorithm *crypto.Algorithm, keyName string) map[string]string {
+	paths := make(map[string]string, len(algorithm.KeyFiles))
+	for _, keyFile := range algorithm.KeyFiles {
+		if fileName := c.keyFileName(keyFile.Name); fileName != "" {
+			paths[keyFile.Name] = c.namedKeyFilePath(keyName, fileName)
+		}
+	}
+	return paths
+}
+
+// namedKeyExists 检查命名密钥的任一密钥文件是否存在
+func (c *Config) namedKeyExists(algorithm *crypto.Algorithm, keyName string) bool {
+	for _, path := range c.NamedKeyPaths(algorithm, keyName) {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyNames 列出算法已存在的密钥名称，默认密钥在前，密钥环中的密钥按名称排序
+func (c *Config) KeyNames(algorithm *crypto.Algorithm) []string {
+	var names []string
+	if c.namedKeyExists(algorithm, DefaultKeyName) {
+		names = append(names, DefaultKeyName)
+	}
+
+	entries, err := os.ReadDir(c.GetKeyringDirPath())
+	if err != nil {
+		return names
+	}
+	var named []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || name == DefaultKeyName || ValidateKeyName(name) != nil {
+			continue
+		}
+		if c.namedKeyExists(algorithm, name) {
+			named = append(named, name)
+		}
+	}
+	sort.Strings(named)
+	return append(names, named...)
+}
+
+// NamedKeyFingerprint 计算命名密钥的指纹，受口令保护时通过 passphrase 获取口令
+func (c *Config) NamedKeyFingerprint(algorithm *crypto.Algorithm, keyName string, passphrase crypto.PassphraseFunc) ([]byte, error) {
+	if algorithm.Fingerprint == nil {
+		return nil, fmt.Errorf("%s does not use key files", algorithm.DisplayName)
+	}
+	return algorithm.Fingerprint(c.NamedKeyPaths(algorithm, keyName), passphrase)
+}
+
+// MatchingKey 在算法的全部密钥中查找指纹与 fingerprints 之一相符的密钥，当前密钥优先；
+// 无法读取的密钥（如缺少口令）跳过
+func (c *Config) MatchingKey(algorithm *crypto.Algorithm, fingerprints [][]byte, passphrase crypto.PassphraseFunc) (string, bool) {
+	current := c.KeyName(algorithm.Name)
+	names := []string{current}
+	for _, name := range c.KeyNames(algorithm) {
+		if name != current {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		fingerprint, err := c.NamedKeyFingerprint(algorithm, name, passphrase)
+		if err != nil {
+			continue
+		}
+		for _, recipient := range fingerprints {
+			if bytes.Equal(recipient, fingerprint) {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// MatchingKeyForFile 按加密文件头部记录的算法和接收方指纹查找本地密钥，返回算法与密钥名称
+// 未记录指纹的文件或没有匹配的密钥时返回 false
+func (c *Config) MatchingKeyForFile(path string) (string, string, bool) {
+	header, err := format.ReadFile(path)
+	if err != nil || header == nil {
+		return "", "", false
+	}
+	algorithm, ok := crypto.LookupAlgorithm(header.Algorithm.String())
+	if !ok || algorithm.Fingerprint == nil {
+		return "", "", false
+	}
+	fingerprints := header.GetAll(format.TagKeyFingerprint)
+	if len(fingerprints) == 0 {
+		return "", "", false
+	}
+
+	name, ok := c.MatchingKey(algorithm, fingerprints, c.KeyPassphraseSource(nil))
+	return algorithm.Name, name, ok
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"hycrypt/internal/constants"
+	"hycrypt/internal/crypto"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestKeyringSelection(t *testing.T) {
+	cfg := Default()
+	cfg.Keys.KeyDir = t.TempDir()
+	algorithm, _ := crypto.LookupAlgorithm(constants.AlgorithmX25519)
+
+	// 默认密钥位于密钥目录顶层，命名密钥位于密钥环子目录
+	if _, err := cfg.GenerateAlgorithmKeys(algorithm); err != nil {
+		t.Fatalf("GenerateAlgorithmKeys failed: %v", err)
+	}
+	for _, name := range []string{"work", "archive"} {
+		if err := cfg.SelectKey(algorithm.Name, name); err != nil {
+			t.Fatalf("SelectKey failed: %v", err)
+		}
+		if _, err := cfg.GenerateAlgorithmKeys(algorithm); err != nil {
+			t.Fatalf("GenerateAlgorithmKeys failed: %v", err)
+		}
+	}
+
+	if got := cfg.GetX25519PublicKeyPath(); got != filepath.Join(cfg.GetKeyringDirPath(), "archive", "x25519.pub") {
+		t.Errorf("selected key path = %s", got)
+	}
+	if got := cfg.GetKMACKeyPath(); got != filepath.Join(cfg.Keys.KeyDir, "kmac.key") {
+		t.Errorf("selection leaked to another algorithm: %s", got)
+	}
+	if names := cfg.KeyNames(algorithm); !reflect.DeepEqual(names, []string{DefaultKeyName, "archive", "work"}) {
+		t.Errorf("KeyNames = %v", names)
+	}
+
+	// 解密时按指纹找到对应的密钥，与当前选择无关
+	work, err := cfg.NamedKeyFingerprint(algorithm, "work", nil)
+	if err != nil {
+		t.Fatalf("NamedKeyFingerprint failed: %v", err)
+	}
+	if name, ok := cfg.MatchingKey(algorithm, [][]byte{work}, nil); !ok || name != "work" {
+		t.Errorf("MatchingKey = %q, %v", name, ok)
+	}
+	if _, ok := cfg.MatchingKey(algorithm, [][]byte{make([]byte, crypto.FingerprintSize)}, nil); ok {
+		t.Error("MatchingKey matched an unknown fingerprint")
+	}
+
+	// 清除选择后回到 default_keys 配置，未配置时为顶层密钥
+	cfg.SelectKey(algorithm.Name, "")
+	cfg.Keys.DefaultKeys = map[string]string{algorithm.Name: "work"}
+	if got := cfg.KeyName(algorithm.Name); got != "work" {
+		t.Errorf("KeyName = %q, expected default_keys entry", got)
+	}
+	cfg.Keys.DefaultKeys = nil
+	if got := cfg.GetX25519PublicKeyPath(); got != filepath.Join(cfg.Keys.KeyDir, "x25519.pub") {
+		t.Errorf("default key path = %s", got)
+	}
+
+	for _, name := range []string{"../escape", ".hidden", "a/b", ""} {
+		if ValidateKeyName(name) == nil {
+			t.Errorf("ValidateKeyName(%q) accepted invalid name", name)
+		}
+	}
+}
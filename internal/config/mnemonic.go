@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"hycrypt/internal/crypto"
+	"hycrypt/internal/mnemonic"
+	"os"
+	"path/filepath"
+)
+
+// KeyMnemonic 读取对称密钥并编码为 BIP39 助记词，受口令保护时通过 passphrase 获取口令
+func (c *Config) KeyMnemonic(algorithm *crypto.Algorithm, passphrase crypto.PassphraseFunc) (string, error) {
+	if algorithm.SymmetricKey == nil {
+		return "", fmt.Errorf("%s does not use a symmetric key", algorithm.DisplayName)
+	}
+
+	path := c.GetKeyFilePath(algorithm.KeyFiles[0].Name)
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s key: %w", algorithm.DisplayName, err)
+	}
+	key, err := algorithm.SymmetricKey.Decode(keyData, path, passphrase)
+	if err != nil {
+		return "", err
+	}
+	defer clear(key)
+
+	if size := algorithm.SymmetricKey.Size(c.AlgorithmParameters()); len(key) != size {
+		return "", fmt.Errorf("%s key length mismatch: expected %d bytes, got %d", algorithm.DisplayName, size, len(key))
+	}
+	return mnemonic.Encode(key)
+}
+
+// RestoreKeyFromMnemonic 校验助记词的校验和与密钥长度后保存为对称密钥，passphrase 非空时加密保存
+// 已存在的密钥文件不会被覆盖
+func (c *Config) RestoreKeyFromMnemonic(algorithm *crypto.Algorithm, words string, passphrase []byte) error {
+	if algorithm.SymmetricKey == nil {
+		return fmt.Errorf("%s does not use a symmetric key", algorithm.DisplayName)
+	}
+
+	key, err := mnemonic.Decode(words)
+	if err != nil {
+		return err
+	}
+	defer clear(key)
+
+	if size := algorithm.SymmetricKey.Size(c.AlgorithmParameters()); len(key) != size {
+		return fmt.Errorf("%s key length mismatch: mnemonic encodes %d bytes, expected %d", algorithm.DisplayName, len(key), size)
+	}
+	path := c.GetKeyFilePath(algorithm.KeyFiles[0].Name)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s key file already exists: %s", algorithm.DisplayName, path)
+	}
+
+	keyData, err := algorithm.SymmetricKey.Encode(key, passphrase)
+	if err != nil {
+		return err
+	}
+	defer clear(keyData)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+	if err := os.WriteFile(path, keyData, 0600); err != nil {
+		return fmt.Errorf("failed to write %s key file: %w", algorithm.DisplayName, err)
+	}
+	return nil
+}
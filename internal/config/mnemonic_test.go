@@ -0,0 +1,53 @@
+package config
+
+import (
+	"bytes"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/crypto"
+	"strings"
+	"testing"
+)
+
+func TestKeyMnemonicRoundTrip(t *testing.T) {
+	kmac, _ := crypto.LookupAlgorithm(constants.AlgorithmKMAC)
+	cfg := Default()
+	cfg.Keys.KeyDir = t.TempDir()
+
+	key := bytes.Repeat([]byte{0xa5}, cfg.Encryption.KMACKeySize)
+	if err := cfg.SaveKMACKey(key, nil); err != nil {
+		t.Fatalf("SaveKMACKey failed: %v", err)
+	}
+	words, err := cfg.KeyMnemonic(kmac, nil)
+	if err != nil {
+		t.Fatalf("KeyMnemonic failed: %v", err)
+	}
+	if n := len(strings.Fields(words)); n != 24 {
+		t.Errorf("got %d words, expected 24", n)
+	}
+
+	// 不覆盖现有密钥
+	if err := cfg.RestoreKeyFromMnemonic(kmac, words, nil); err == nil {
+		t.Error("RestoreKeyFromMnemonic overwrote existing key")
+	}
+
+	restored := Default()
+	restored.Keys.KeyDir = t.TempDir()
+
+	// 校验和错误时不写入密钥文件
+	fields := strings.Fields(words)
+	fields[0], fields[1] = fields[1], fields[0]
+	if err := restored.RestoreKeyFromMnemonic(kmac, strings.Join(fields, " "), nil); err == nil {
+		t.Error("RestoreKeyFromMnemonic accepted swapped words")
+	}
+	if restored.CheckKMACKeyExists() {
+		t.Error("key file written for invalid mnemonic")
+	}
+
+	if err := restored.RestoreKeyFromMnemonic(kmac, words, nil); err != nil {
+		t.Fatalf("RestoreKeyFromMnemonic failed: %v", err)
+	}
+	loaded, err := restored.LoadKMACKey()
+	if err != nil || !bytes.Equal(loaded, key) {
+		t.Errorf("restored key = %x, %v", loaded, err)
+	}
+}
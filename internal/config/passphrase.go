@@ -0,0 +1,52 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"hycrypt/internal/crypto"
+	"os"
+	"os/exec"
+)
+
+// KeyPassphraseEnvVar 非交互场景下提供私钥文件口令的环境变量
+const KeyPassphraseEnvVar = "HYCRYPT_KEY_PASSPHRASE"
+
+// HasKeyPassphraseSource 是否配置了无需交互的密钥口令来源（环境变量或 passphrase_command）
+func (c *Config) HasKeyPassphraseSource() bool {
+	return os.Getenv(KeyPassphraseEnvVar) != "" || c.Keys.PassphraseCommand != ""
+}
+
+// KeyPassphraseSource 返回受保护密钥文件的口令来源，依次尝试环境变量、passphrase_command，最后调用 prompt
+// prompt 为空时不交互，未配置其他来源则返回错误
+func (c *Config) KeyPassphraseSource(prompt crypto.PassphraseFunc) crypto.PassphraseFunc {
+	return func(path string) ([]byte, error) {
+		if env := os.Getenv(KeyPassphraseEnvVar); env != "" {
+			return []byte(env), nil
+		}
+		if c.Keys.PassphraseCommand != "" {
+			return runPassphraseCommand(c.Keys.PassphraseCommand, path)
+		}
+		if prompt == nil {
+			return nil, fmt.Errorf("密钥文件受口令保护，请设置 %s 环境变量或 keys.passphrase_command: %s", KeyPassphraseEnvVar, path)
+		}
+		return prompt(path)
+	}
+}
+
+// runPassphraseCommand 执行口令命令，输出去掉末尾换行后作为口令
+func runPassphraseCommand(command, path string) ([]byte, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(), "HYCRYPT_KEY_FILE="+path)
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("passphrase_command 执行失败: %w", err)
+	}
+
+	passphrase := bytes.TrimRight(output, "\r\n")
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase_command 未输出口令")
+	}
+	return passphrase, nil
+}
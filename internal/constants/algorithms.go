@@ -7,18 +7,98 @@ const (
 
 	// AlgorithmKMAC KMAC 算法标识
 	AlgorithmKMAC = "kmac"
+
+	// AlgorithmPassword 口令加密算法标识（Argon2id 派生密钥）
+	AlgorithmPassword = "password"
+
+	// AlgorithmX25519 X25519 混合加密算法标识（临时 ECDH + HKDF）
+	AlgorithmX25519 = "x25519"
+
+	// AlgorithmMLKEM ML-KEM-768 + X25519 抗量子混合加密算法标识
+	AlgorithmMLKEM = "mlkem"
+)
+
+// AEAD constants - 认证加密算法常量
+const (
+	// AEADAESGCM AES-GCM（默认）
+	AEADAESGCM = "aes-gcm"
+
+	// AEADChaCha20Poly1305 ChaCha20-Poly1305，适合没有 AES 硬件加速的设备
+	AEADChaCha20Poly1305 = "chacha20-poly1305"
+
+	// AEADXChaCha20Poly1305 XChaCha20-Poly1305，192 位 nonce
+	AEADXChaCha20Poly1305 = "xchacha20-poly1305"
+)
+
+// SupportedAEADs 支持的 AEAD 列表
+var SupportedAEADs = []string{
+	AEADAESGCM,
+	AEADChaCha20Poly1305,
+	AEADXChaCha20Poly1305,
+}
+
+// IsValidAEAD 检查 AEAD 是否有效
+func IsValidAEAD(aead string) bool {
+	for _, supported := range SupportedAEADs {
+		if aead == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Padding constants - 长度隐藏填充方案常量
+const (
+	// PaddingNone 不填充（默认），密文长度直接反映明文长度
+	PaddingNone = "none"
+
+	// PaddingPadme PADMÉ 填充，额外开销不超过 12%，只泄露长度的数量级
+	PaddingPadme = "padme"
+
+	// PaddingBucket 填充到 2 的幂次（至少 1 KiB），短小秘密的密文长度完全相同
+	PaddingBucket = "bucket"
+)
+
+// SupportedPaddings 支持的填充方案列表
+var SupportedPaddings = []string{
+	PaddingNone,
+	PaddingPadme,
+	PaddingBucket,
+}
+
+// IsValidPadding 检查填充方案是否有效
+func IsValidPadding(padding string) bool {
+	for _, supported := range SupportedPaddings {
+		if padding == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Compression constants - 加密前压缩编码常量
+const (
+	// CompressionNone 不压缩（默认）
+	CompressionNone = "none"
+
+	// CompressionGzip gzip，压缩级别 1-9
+	CompressionGzip = "gzip"
+
+	// CompressionZstd Zstandard，压缩级别 1-22
+	CompressionZstd = "zstd"
 )
 
-// SupportedAlgorithms 支持的算法列表
-var SupportedAlgorithms = []string{
-	AlgorithmRSA,
-	AlgorithmKMAC,
+// SupportedCompressions 支持的压缩编码列表
+var SupportedCompressions = []string{
+	CompressionNone,
+	CompressionGzip,
+	CompressionZstd,
 }
 
-// IsValidAlgorithm 检查算法是否有效
-func IsValidAlgorithm(algorithm string) bool {
-	for _, supported := range SupportedAlgorithms {
-		if algorithm == supported {
+// IsValidCompression 检查压缩编码是否有效
+func IsValidCompression(compression string) bool {
+	for _, supported := range SupportedCompressions {
+		if compression == supported {
 			return true
 		}
 	}
@@ -11,56 +11,16 @@ func TestAlgorithmConstants(t *testing.T) {
 	if AlgorithmKMAC != "kmac" {
 		t.Errorf("Expected AlgorithmKMAC to be 'kmac', got %s", AlgorithmKMAC)
 	}
-}
-
-func TestSupportedAlgorithms(t *testing.T) {
-	// 测试支持的算法列表
-	if len(SupportedAlgorithms) != 2 {
-		t.Errorf("Expected 2 supported algorithms, got %d", len(SupportedAlgorithms))
-	}
-
-	// 检查RSA算法是否在列表中
-	found := false
-	for _, alg := range SupportedAlgorithms {
-		if alg == AlgorithmRSA {
-			found = true
-			break
-		}
-	}
-	if !found {
-		t.Error("RSA algorithm not found in SupportedAlgorithms")
-	}
 
-	// 检查KMAC算法是否在列表中
-	found = false
-	for _, alg := range SupportedAlgorithms {
-		if alg == AlgorithmKMAC {
-			found = true
-			break
-		}
+	if AlgorithmPassword != "password" {
+		t.Errorf("Expected AlgorithmPassword to be 'password', got %s", AlgorithmPassword)
 	}
-	if !found {
-		t.Error("KMAC algorithm not found in SupportedAlgorithms")
-	}
-}
 
-func TestIsValidAlgorithm(t *testing.T) {
-	// 测试有效算法检查
-	testCases := []struct {
-		algorithm string
-		expected  bool
-	}{
-		{AlgorithmRSA, true},
-		{AlgorithmKMAC, true},
-		{"aes", false},
-		{"unknown", false},
-		{"", false},
+	if AlgorithmX25519 != "x25519" {
+		t.Errorf("Expected AlgorithmX25519 to be 'x25519', got %s", AlgorithmX25519)
 	}
 
-	for _, tc := range testCases {
-		result := IsValidAlgorithm(tc.algorithm)
-		if result != tc.expected {
-			t.Errorf("IsValidAlgorithm(%s) = %v, expected %v", tc.algorithm, result, tc.expected)
-		}
+	if AlgorithmMLKEM != "mlkem" {
+		t.Errorf("Expected AlgorithmMLKEM to be 'mlkem', got %s", AlgorithmMLKEM)
 	}
 }
@@ -5,9 +5,61 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"fmt"
+	"hycrypt/internal/format"
 	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
 )
 
+// newAEAD 根据 AEAD 标识创建实例
+func newAEAD(id format.AEADID, key []byte) (cipher.AEAD, error) {
+	switch id {
+	case format.AEADAESGCM:
+		return newAESGCM(key)
+	case format.AEADChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	case format.AEADXChaCha20Poly1305:
+		return chacha20poly1305.NewX(key)
+	default:
+		return nil, fmt.Errorf("unsupported aead: %s", id)
+	}
+}
+
+// isSupportedAEAD 检查头部中的 AEAD 标识是否受支持
+func isSupportedAEAD(id format.AEADID) bool {
+	switch id {
+	case format.AEADAESGCM, format.AEADChaCha20Poly1305, format.AEADXChaCha20Poly1305:
+		return true
+	}
+	return false
+}
+
+// selectAEAD 确定头部使用的 AEAD（未指定时默认 AES-GCM），返回所需的数据密钥长度
+// ChaCha20 系列固定使用 256 位密钥
+func selectAEAD(header *format.Header, aesKeySize int) int {
+	if header.AEAD == format.AEADNone {
+		header.AEAD = format.AEADAESGCM
+	}
+	if header.AEAD == format.AEADAESGCM {
+		return aesKeySize
+	}
+	return chacha20poly1305.KeySize
+}
+
+// newAESGCM 创建AES-GCM实例
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM mode: %w", err)
+	}
+	return gcm, nil
+}
+
 // EncryptAESGCM 使用AES-GCM模式加密数据
 func EncryptAESGCM(key, plaintext []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
@@ -0,0 +1,250 @@
+package crypto
+
+import (
+	"context"
+	"fmt"
+	"hycrypt/internal/age"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/datasink"
+	"hycrypt/internal/domain"
+	"hycrypt/internal/errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// AgeExtension age 文件扩展名
+const AgeExtension = ".age"
+
+// AgeConfig age 格式互通配置
+type AgeConfig struct {
+	// Recipients 额外接收方：age1... 字符串、hycrypt X25519 公钥或接收方文件路径
+	Recipients []string
+	// IdentityPaths age 身份文件（AGE-SECRET-KEY-1...），解密时与本地 X25519 私钥一起尝试
+	IdentityPaths []string
+}
+
+// DetectAgeMethod 根据 age 头部的接收方节推断对应的加密方法，不是 age 文件时返回空
+func DetectAgeMethod(path string) string {
+	stanzas, err := age.ReadFileStanzas(path)
+	if err != nil || stanzas == nil {
+		return ""
+	}
+	for _, stanza := range stanzas {
+		if stanza.Type == "scrypt" {
+			return constants.AlgorithmPassword
+		}
+	}
+	return constants.AlgorithmX25519
+}
+
+// X25519AgeRecipient 将 hycrypt X25519 公钥文件转换为 age1... 接收方字符串
+func X25519AgeRecipient(publicKeyPath string) (string, error) {
+	publicKey, err := readX25519PublicKey(publicKeyPath)
+	if err != nil {
+		return "", err
+	}
+	return age.NewX25519Recipient(publicKey).String(), nil
+}
+
+// ageRecipients 构建 age 接收方：x25519 使用本地公钥和额外接收方，password 使用 scrypt 口令
+func (p *UnifiedProcessor) ageRecipients(method string) ([]age.Recipient, error) {
+	var extra []string
+	if p.config.AgeConfig != nil {
+		extra = p.config.AgeConfig.Recipients
+	}
+
+	switch method {
+	case constants.AlgorithmX25519:
+		x25519 := algorithmConfig[X25519Config](p.config, constants.AlgorithmX25519)
+		if x25519 == nil {
+			return nil, errors.InvalidConfig("X25519 key not configured", nil)
+		}
+		own, err := X25519AgeRecipient(x25519.PublicKeyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		recipients, err := parseAgeRecipients(append([]string{own}, extra...))
+		if err != nil {
+			return nil, errors.InvalidConfig("invalid age recipient", err)
+		}
+		return recipients, nil
+	case constants.AlgorithmPassword:
+		password := algorithmConfig[PasswordConfig](p.config, constants.AlgorithmPassword)
+		if password == nil {
+			return nil, errors.InvalidConfig("password not configured", nil)
+		}
+		// age 口令文件只能有一个接收方
+		if len(extra) > 0 {
+			return nil, errors.InvalidConfig("age passphrase encryption cannot be combined with other recipients", nil)
+		}
+		recipient, err := age.NewScryptRecipient(password.Password)
+		if err != nil {
+			return nil, errors.InvalidConfig("invalid age passphrase", err)
+		}
+		return []age.Recipient{recipient}, nil
+	default:
+		return nil, errors.InvalidConfig(fmt.Sprintf("age output supports %s and %s methods, got %s",
+			constants.AlgorithmX25519, constants.AlgorithmPassword, method), nil)
+	}
+}
+
+// ageIdentities 收集可用的 age 身份：本地 X25519 私钥、age 身份文件和口令
+func (p *UnifiedProcessor) ageIdentities() ([]age.Identity, error) {
+	var identities []age.Identity
+
+	if x25519 := algorithmConfig[X25519Config](p.config, constants.AlgorithmX25519); x25519 != nil {
+		if _, err := os.Stat(x25519.PrivateKeyPath); err == nil {
+			privateKey, err := readX25519PrivateKey(x25519.PrivateKeyPath, x25519.Passphrase)
+			if err != nil {
+				return nil, err
+			}
+			identities = append(identities, age.NewX25519Identity(privateKey))
+		}
+	}
+
+	if p.config.AgeConfig != nil {
+		for _, path := range p.config.AgeConfig.IdentityPaths {
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, errors.KeyNotFound("age identity", path)
+			}
+			parsed, err := age.ParseIdentities(file)
+			file.Close()
+			if err != nil {
+				return nil, errors.InvalidFormat("age identity file", err)
+			}
+			identities = append(identities, parsed...)
+		}
+	}
+
+	if password := algorithmConfig[PasswordConfig](p.config, constants.AlgorithmPassword); password != nil && len(password.Password) > 0 {
+		identity, err := age.NewScryptIdentity(password.Password)
+		if err != nil {
+			return nil, errors.InvalidConfig("invalid age passphrase", err)
+		}
+		identities = append(identities, identity)
+	}
+
+	if len(identities) == 0 {
+		return nil, errors.KeyNotFound("age identity", "")
+	}
+	return identities, nil
+}
+
+// IsInlineAgeRecipient 判断接收方是直接给出的公钥文本而非文件路径
+func IsInlineAgeRecipient(value string) bool {
+	return strings.HasPrefix(value, "age1") || strings.HasPrefix(value, X25519PublicKeyPrefix)
+}
+
+// parseAgeRecipients 解析接收方：age1... 字符串、hycrypt X25519 公钥文本或接收方文件
+func parseAgeRecipients(values []string) ([]age.Recipient, error) {
+	var recipients []age.Recipient
+	for _, value := range values {
+		switch {
+		case strings.HasPrefix(value, "age1"):
+			recipient, err := age.ParseX25519Recipient(value)
+			if err != nil {
+				return nil, err
+			}
+			recipients = append(recipients, recipient)
+		case strings.HasPrefix(value, X25519PublicKeyPrefix):
+			publicKey, err := ParseX25519PublicKey(value)
+			if err != nil {
+				return nil, err
+			}
+			recipients = append(recipients, age.NewX25519Recipient(publicKey))
+		default:
+			file, err := os.Open(value)
+			if err != nil {
+				return nil, fmt.Errorf("recipient file not found: %s", value)
+			}
+			parsed, err := age.ParseRecipients(file)
+			file.Close()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", value, err)
+			}
+			recipients = append(recipients, parsed...)
+		}
+	}
+	return recipients, nil
+}
+
+// encryptAge 明文直接写入 age 输出，由 AgeSink 完成加密
+func (p *UnifiedProcessor) encryptAge(ctx context.Context, source domain.DataSource, sink domain.DataSink, opts domain.CryptoOptions) (*domain.CryptoResult, error) {
+	startTime := time.Now()
+
+	if _, ok := sink.(*datasink.AgeSinkInterface); !ok {
+		return nil, errors.InvalidConfig("age output requires an age sink", nil)
+	}
+	// age 格式没有签名字段
+	if p.signer != nil && p.signer.config.Sign {
+		return nil, discardOutput(sink, errors.InvalidConfig("signing is not supported for age output", nil))
+	}
+
+	reader, err := source.Read(ctx)
+	if err != nil {
+		return nil, discardOutput(sink, errors.EncryptionFailed(opts.Method, err))
+	}
+	defer reader.Close()
+
+	if err := sink.Write(ctx, reader); err != nil {
+		return nil, discardOutput(sink, errors.EncryptionFailed(opts.Method, err))
+	}
+
+	return &domain.CryptoResult{
+		Success:       true,
+		OutputPath:    sink.Path(),
+		ProcessedSize: source.Size(),
+		Method:        opts.Method,
+		ProcessTime:   time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
+// decryptAge AgeSource 已输出明文，直接写入输出
+func (p *UnifiedProcessor) decryptAge(ctx context.Context, source domain.DataSource, sink domain.DataSink, opts domain.CryptoOptions) (*domain.CryptoResult, error) {
+	startTime := time.Now()
+
+	method := opts.Method
+	if method == "" {
+		method = DetectAgeMethod(source.Name())
+	}
+
+	if p.signer != nil && p.signer.config.RequireSignature {
+		return nil, discardOutput(sink, errors.SignatureInvalid("age files are not signed", nil))
+	}
+
+	reader, err := source.Read(ctx)
+	if err != nil {
+		return nil, discardOutput(sink, errors.DecryptionFailed(method, err))
+	}
+	defer reader.Close()
+
+	// 载荷认证失败时删除已写出的部分明文
+	if err := sink.Write(ctx, reader); err != nil {
+		return nil, discardOutput(sink, errors.DecryptionFailed(method, err))
+	}
+
+	return &domain.CryptoResult{
+		Success:       true,
+		OutputPath:    sink.Path(),
+		ProcessedSize: source.Size(),
+		Method:        method,
+		ProcessTime:   time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
+// isAgeFile 检查文件是否为 age 格式
+func isAgeFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	prefix := make([]byte, len(age.Intro))
+	n, _ := io.ReadFull(file, prefix)
+	return age.IsAge(prefix[:n])
+}
@@ -0,0 +1,194 @@
+package crypto
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/format"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// 压缩级别范围，0 表示使用编码默认级别
+const (
+	maxGzipLevel = gzip.BestCompression
+	maxZstdLevel = 22
+)
+
+// compressionSniffSize 检测已压缩数据时读取的前缀长度
+const compressionSniffSize = 32
+
+// compressedMagics 已压缩或已加密格式的魔数，再次压缩几乎没有收益
+var compressedMagics = [][]byte{
+	{0x1f, 0x8b},                       // gzip
+	{0x28, 0xb5, 0x2f, 0xfd},           // zstd
+	{0xfd, '7', 'z', 'X', 'Z', 0x00},   // xz
+	{'B', 'Z', 'h'},                    // bzip2
+	{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}, // 7z
+	{0x04, 0x22, 0x4d, 0x18},           // lz4
+	{'R', 'a', 'r', '!', 0x1a, 0x07},   // rar
+	{0x89, 'P', 'N', 'G', '\r', '\n'},  // png
+	{0xff, 0xd8, 0xff},                 // jpeg
+	{'G', 'I', 'F', '8'},               // gif
+	{'O', 'g', 'g', 'S'},               // ogg
+	{'f', 'L', 'a', 'C'},               // flac
+	{'I', 'D', '3'},                    // mp3
+	{0x1a, 0x45, 0xdf, 0xa3},           // mkv、webm
+	[]byte("age-encryption.org/v1\n"),  // age
+	format.Magic,                       // hycrypt
+}
+
+// zipMagic zip 本地文件头签名
+var zipMagic = []byte{'P', 'K', 0x03, 0x04}
+
+// isCompressedData 根据数据开头判断是否为已压缩的格式
+func isCompressedData(prefix []byte) bool {
+	// zip、docx、jar 等按第一个条目的压缩方式判断，条目只存储的压缩包（如启用压缩时的目录压缩包）仍可压缩
+	if bytes.HasPrefix(prefix, zipMagic) {
+		return len(prefix) < 10 || binary.LittleEndian.Uint16(prefix[8:10]) != zip.Store
+	}
+	for _, magic := range compressedMagics {
+		if bytes.HasPrefix(prefix, magic) {
+			return true
+		}
+	}
+	// RIFF 容器中的 WebP，ISO BMFF 容器（mp4、mov、heic）
+	if len(prefix) >= 12 && bytes.Equal(prefix[:4], []byte("RIFF")) && bytes.Equal(prefix[8:12], []byte("WEBP")) {
+		return true
+	}
+	return len(prefix) >= 8 && bytes.Equal(prefix[4:8], []byte("ftyp"))
+}
+
+// ValidateCompression 检查压缩编码和级别，级别 0 表示编码默认级别
+func ValidateCompression(compression string, level int) error {
+	if compression == "" {
+		compression = constants.CompressionNone
+	}
+	if !constants.IsValidCompression(compression) {
+		return fmt.Errorf("compression must be one of %v, got '%s'", constants.SupportedCompressions, compression)
+	}
+
+	maxLevel := 0
+	switch compression {
+	case constants.CompressionGzip:
+		maxLevel = maxGzipLevel
+	case constants.CompressionZstd:
+		maxLevel = maxZstdLevel
+	}
+	if level < 0 || level > maxLevel {
+		if maxLevel == 0 {
+			return fmt.Errorf("compression level must be 0 when compression is %s, got %d", compression, level)
+		}
+		return fmt.Errorf("%s compression level must be 0 (default) or between 1 and %d, got %d", compression, maxLevel, level)
+	}
+	return nil
+}
+
+// newCompressWriter 创建写入 dst 的压缩器
+func newCompressWriter(dst io.Writer, compression format.CompressionID, level int) (io.WriteCloser, error) {
+	switch compression {
+	case format.CompressionGzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(dst, level)
+	case format.CompressionZstd:
+		options := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+		if level > 0 {
+			options = append(options, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+		return zstd.NewWriter(dst, options...)
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", compression)
+	}
+}
+
+// compressReader 读取时压缩 src
+//
+// 每次从 src 读取一块写入压缩器，压缩输出暂存在缓冲区中，不需要额外的 goroutine。
+type compressReader struct {
+	src    io.Reader
+	writer io.WriteCloser
+	out    bytes.Buffer
+	chunk  []byte
+	done   bool
+}
+
+// newCompressReader 创建压缩读取器
+func newCompressReader(src io.Reader, compression format.CompressionID, level int) (*compressReader, error) {
+	c := &compressReader{src: src, chunk: make([]byte, DefaultChunkSize)}
+	writer, err := newCompressWriter(&c.out, compression, level)
+	if err != nil {
+		return nil, err
+	}
+	c.writer = writer
+	return c, nil
+}
+
+func (c *compressReader) Read(p []byte) (int, error) {
+	for c.out.Len() == 0 && !c.done {
+		n, err := c.src.Read(c.chunk)
+		if n > 0 {
+			if _, werr := c.writer.Write(c.chunk[:n]); werr != nil {
+				return 0, werr
+			}
+		}
+		switch {
+		case err == io.EOF:
+			if cerr := c.writer.Close(); cerr != nil {
+				return 0, cerr
+			}
+			c.done = true
+		case err != nil:
+			return 0, err
+		}
+	}
+
+	if c.out.Len() == 0 {
+		return 0, io.EOF
+	}
+	return c.out.Read(p)
+}
+
+// decompressReader 读取时解压，读到结尾或出错时释放解压器
+type decompressReader struct {
+	reader io.Reader
+	close  func()
+	err    error
+}
+
+// newDecompressReader 创建解压读取器
+func newDecompressReader(src io.Reader, compression format.CompressionID) (*decompressReader, error) {
+	switch compression {
+	case format.CompressionGzip:
+		reader, err := gzip.NewReader(src)
+		if err != nil {
+			return nil, err
+		}
+		return &decompressReader{reader: reader, close: func() { reader.Close() }}, nil
+	case format.CompressionZstd:
+		decoder, err := zstd.NewReader(src, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return &decompressReader{reader: decoder, close: decoder.Close}, nil
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", compression)
+	}
+}
+
+func (d *decompressReader) Read(p []byte) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+	n, err := d.reader.Read(p)
+	if err != nil {
+		d.err = err
+		d.close()
+	}
+	return n, err
+}
@@ -0,0 +1,173 @@
+package crypto
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/datasink"
+	"hycrypt/internal/datasource"
+	"hycrypt/internal/domain"
+	"hycrypt/internal/format"
+	"hycrypt/internal/utils"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCompressionRoundTrip(t *testing.T) {
+	text := bytes.Repeat([]byte("compress me before encryption\n"), 10000)
+	random := make([]byte, 100000)
+	rand.Read(random)
+
+	for _, compression := range []format.CompressionID{format.CompressionGzip, format.CompressionZstd} {
+		for _, level := range []int{0, 1, 9} {
+			for _, plaintext := range [][]byte{nil, text, random} {
+				compressor, err := newCompressReader(iotest.HalfReader(bytes.NewReader(plaintext)), compression, level)
+				if err != nil {
+					t.Fatalf("%s level %d: newCompressReader failed: %v", compression, level, err)
+				}
+				compressed, err := io.ReadAll(compressor)
+				if err != nil {
+					t.Fatalf("%s level %d: compression failed: %v", compression, level, err)
+				}
+
+				decompressor, err := newDecompressReader(bytes.NewReader(compressed), compression)
+				if err != nil {
+					t.Fatalf("%s level %d: newDecompressReader failed: %v", compression, level, err)
+				}
+				opened, err := io.ReadAll(decompressor)
+				if err != nil || !bytes.Equal(opened, plaintext) {
+					t.Fatalf("%s level %d: round trip mismatch: %v", compression, level, err)
+				}
+				if len(plaintext) == len(text) && len(compressed) >= len(text)/10 {
+					t.Errorf("%s level %d: text compressed to %d bytes", compression, level, len(compressed))
+				}
+			}
+		}
+	}
+}
+
+func TestValidateCompression(t *testing.T) {
+	tests := []struct {
+		compression string
+		level       int
+		wantErr     bool
+	}{
+		{"", 0, false},
+		{constants.CompressionNone, 0, false},
+		{constants.CompressionGzip, 9, false},
+		{constants.CompressionGzip, 10, true},
+		{constants.CompressionZstd, 22, false},
+		{constants.CompressionZstd, -1, true},
+		{constants.CompressionNone, 3, true},
+		{constants.CompressionGzip, 0, false},
+		{"brotli", 0, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateCompression(tt.compression, tt.level); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateCompression(%q, %d) = %v, wantErr %v", tt.compression, tt.level, err, tt.wantErr)
+		}
+	}
+}
+
+func TestProcessorCompression(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	dir := t.TempDir()
+
+	text := bytes.Repeat([]byte("log line\n"), 50000)
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	writer.Write(text)
+	writer.Close()
+
+	os.WriteFile(filepath.Join(dir, "app.log"), text, 0600)
+	os.WriteFile(filepath.Join(dir, "app.log.gz"), gzipped.Bytes(), 0600)
+	os.MkdirAll(filepath.Join(dir, "logs", "old"), 0700)
+	os.WriteFile(filepath.Join(dir, "logs", "old", "app.log"), text, 0600)
+	utils.ZipDirectory(filepath.Join(dir, "logs"), filepath.Join(dir, "deflated.zip"), zip.Deflate)
+	utils.ZipDirectory(filepath.Join(dir, "logs"), filepath.Join(dir, "stored.zip"), zip.Store)
+
+	tests := []struct {
+		name    string
+		input   string
+		padding string
+		want    format.CompressionID
+	}{
+		{"text file", "app.log", "", format.CompressionZstd},
+		{"already compressed", "app.log.gz", "", format.CompressionNone},
+		{"padding requested", "app.log", constants.PaddingPadme, format.CompressionNone},
+		{"directory", "logs", "", format.CompressionZstd},
+		{"deflated archive", "deflated.zip", "", format.CompressionNone},
+		{"stored archive", "stored.zip", "", format.CompressionZstd},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewUnifiedProcessor(&ProcessorConfig{
+				Algorithms:  map[string]any{constants.AlgorithmKMAC: &KMACConfig{Key: key, KeySize: 32, AESKeySize: 32}},
+				Padding:     tt.padding,
+				Compression: constants.CompressionZstd,
+			})
+			if err != nil {
+				t.Fatalf("NewUnifiedProcessor failed: %v", err)
+			}
+			opts := domain.CryptoOptions{Method: constants.AlgorithmKMAC}
+
+			encrypted, err := processor.ProcessFile(context.Background(), filepath.Join(dir, tt.input), t.TempDir(), true, opts)
+			if err != nil {
+				t.Fatalf("encryption failed: %v", err)
+			}
+			header, err := format.ReadFile(encrypted.OutputPath)
+			if err != nil || header.Compression() != tt.want {
+				t.Fatalf("header compression = %s, want %s (%v)", header.Compression(), tt.want, err)
+			}
+			if info, _ := os.Stat(encrypted.OutputPath); tt.want != format.CompressionNone && info.Size() > int64(len(text)/10) {
+				t.Errorf("compressed ciphertext is %d bytes", info.Size())
+			}
+
+			decrypted, err := processor.ProcessFile(context.Background(), encrypted.OutputPath, t.TempDir(), false, opts)
+			if err != nil {
+				t.Fatalf("decryption failed: %v", err)
+			}
+			original := filepath.Join(dir, tt.input)
+			opened := decrypted.OutputPath
+			if tt.input == "logs" {
+				original = filepath.Join(dir, "logs", "old", "app.log")
+				opened = filepath.Join(decrypted.OutputPath, "old", "app.log")
+			}
+			want, _ := os.ReadFile(original)
+			if got, err := os.ReadFile(opened); err != nil || !bytes.Equal(got, want) {
+				t.Errorf("round trip mismatch: %v", err)
+			}
+		})
+	}
+
+	// 直接传入条目已压缩的目录数据源时不再压缩
+	processor, err := NewUnifiedProcessor(&ProcessorConfig{
+		Algorithms:  map[string]any{constants.AlgorithmKMAC: &KMACConfig{Key: key, KeySize: 32, AESKeySize: 32}},
+		Compression: constants.CompressionZstd,
+	})
+	if err != nil {
+		t.Fatalf("NewUnifiedProcessor failed: %v", err)
+	}
+	source, err := datasource.CreateDirectorySource(filepath.Join(dir, "logs"), zip.Deflate)
+	if err != nil {
+		t.Fatalf("CreateDirectorySource failed: %v", err)
+	}
+	sink, err := datasink.FileSink(filepath.Join(t.TempDir(), "logs.hycrypt"))
+	if err != nil {
+		t.Fatalf("FileSink failed: %v", err)
+	}
+	if _, err := processor.Encrypt(context.Background(), source, sink, domain.CryptoOptions{Method: constants.AlgorithmKMAC}); err != nil {
+		t.Fatalf("encryption failed: %v", err)
+	}
+	sink.Close()
+	if header, err := format.ReadFile(sink.Path()); err != nil || header.Compression() != format.CompressionNone {
+		t.Errorf("deflated directory archive compressed again: %v", err)
+	}
+}
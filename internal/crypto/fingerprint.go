@@ -0,0 +1,112 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/mlkem"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"os"
+)
+
+// FingerprintSize 密钥指纹长度（字节），显示为 16 位十六进制
+const FingerprintSize = 8
+
+// 指纹的域分隔字符串，不同类型的密钥即使字节相同指纹也不同
+const (
+	kmacFingerprintDomain    = "hycrypt kmac key"
+	rsaFingerprintDomain     = "hycrypt rsa public key"
+	x25519FingerprintDomain  = "hycrypt x25519 public key"
+	mlkemFingerprintDomain   = "hycrypt mlkem768x25519 public key"
+	ed25519FingerprintDomain = "hycrypt ed25519 public key"
+)
+
+// keyFingerprint 计算 SHA-256(domain || 0x00 || parts...) 并截取前 FingerprintSize 字节
+func keyFingerprint(domain string, parts ...[]byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(domain))
+	h.Write([]byte{0})
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)[:FingerprintSize]
+}
+
+// FormatFingerprint 将指纹格式化为十六进制文本
+func FormatFingerprint(fingerprint []byte) string {
+	return hex.EncodeToString(fingerprint)
+}
+
+// KMACKeyFingerprint 计算 KMAC 密钥指纹，指纹由密钥单向派生，不泄露密钥本身
+func KMACKeyFingerprint(key []byte) []byte {
+	return keyFingerprint(kmacFingerprintDomain, key)
+}
+
+// rsaFingerprint 计算 RSA 公钥指纹（PKCS#1 DER 编码）
+func rsaFingerprint(publicKey *rsa.PublicKey) []byte {
+	return keyFingerprint(rsaFingerprintDomain, x509.MarshalPKCS1PublicKey(publicKey))
+}
+
+// RSAPublicKeyFingerprints 读取 RSA 公钥文件（PEM 或 ssh-rsa 公钥行）并计算每个公钥的指纹
+func RSAPublicKeyFingerprints(path string) ([][]byte, error) {
+	publicKeys, err := readRSAPublicKeys(path)
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := make([][]byte, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		fingerprints[i] = rsaFingerprint(publicKey)
+	}
+	return fingerprints, nil
+}
+
+// x25519Fingerprint 计算 X25519 公钥指纹，由 ssh-ed25519 密钥映射的公钥同样适用
+func x25519Fingerprint(publicKey *ecdh.PublicKey) []byte {
+	return keyFingerprint(x25519FingerprintDomain, publicKey.Bytes())
+}
+
+// mlkemFingerprint 计算 ML-KEM-768 + X25519 混合公钥指纹
+func mlkemFingerprint(encapsulationKey *mlkem.EncapsulationKey768, x25519Public *ecdh.PublicKey) []byte {
+	return keyFingerprint(mlkemFingerprintDomain, encapsulationKey.Bytes(), x25519Public.Bytes())
+}
+
+// Ed25519KeyFingerprint 计算 Ed25519 签名公钥指纹
+func Ed25519KeyFingerprint(publicKey ed25519.PublicKey) []byte {
+	return keyFingerprint(ed25519FingerprintDomain, publicKey)
+}
+
+// SigningKeyFingerprint 读取签名公钥文件并计算指纹
+func SigningKeyFingerprint(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.KeyNotFound("signing public", path)
+	}
+	publicKey, err := ParseEd25519PublicKey(string(data))
+	if err != nil {
+		return nil, errors.InvalidFormat("signing public key", err)
+	}
+	return Ed25519KeyFingerprint(publicKey), nil
+}
+
+// checkKeyFingerprint 在解密前比较头部记录的接收方指纹与本地密钥指纹，不符时返回 ErrWrongKey
+// 未记录指纹的旧文件不检查
+func checkKeyFingerprint(header *format.Header, method string, fingerprint []byte) error {
+	recipients := header.GetAll(format.TagKeyFingerprint)
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	expected := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		if bytes.Equal(recipient, fingerprint) {
+			return nil
+		}
+		expected[i] = FormatFingerprint(recipient)
+	}
+	return errors.WrongKey(method, expected, FormatFingerprint(fingerprint))
+}
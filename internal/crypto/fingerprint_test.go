@@ -0,0 +1,111 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestKeyFingerprintMismatch(t *testing.T) {
+	newKMAC := func(t *testing.T) CryptoService {
+		key := make([]byte, 32)
+		rand.Read(key)
+		service, err := KMACService(&KMACConfig{Key: key, KeySize: 32, AESKeySize: 32})
+		if err != nil {
+			t.Fatalf("KMACService failed: %v", err)
+		}
+		return service
+	}
+	newX25519 := func(t *testing.T) CryptoService {
+		service, err := X25519Service(writeTestKeys[X25519Config](t, constants.AlgorithmX25519))
+		if err != nil {
+			t.Fatalf("X25519Service failed: %v", err)
+		}
+		return service
+	}
+	newMLKEM := func(t *testing.T) CryptoService {
+		service, err := MLKEMService(writeTestKeys[MLKEMConfig](t, constants.AlgorithmMLKEM))
+		if err != nil {
+			t.Fatalf("MLKEMService failed: %v", err)
+		}
+		return service
+	}
+
+	tests := []struct {
+		name       string
+		newService func(t *testing.T) CryptoService
+	}{
+		{constants.AlgorithmKMAC, newKMAC},
+		{constants.AlgorithmX25519, newX25519},
+		{constants.AlgorithmMLKEM, newMLKEM},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner := tt.newService(t)
+			encrypted, err := owner.EncryptData(context.Background(), strings.NewReader("fingerprint"))
+			if err != nil {
+				t.Fatalf("EncryptData failed: %v", err)
+			}
+			sealed, _ := io.ReadAll(encrypted)
+
+			header, _, err := format.Open(bytes.NewReader(sealed))
+			if err != nil {
+				t.Fatalf("format.Open failed: %v", err)
+			}
+			recorded, ok := header.Get(format.TagKeyFingerprint)
+			if !ok || len(recorded) != FingerprintSize {
+				t.Fatalf("expected %d-byte key fingerprint in header, got %x", FingerprintSize, recorded)
+			}
+
+			// 本人的密钥可以解密
+			if _, err := owner.DecryptData(context.Background(), bytes.NewReader(sealed)); err != nil {
+				t.Fatalf("DecryptData failed: %v", err)
+			}
+
+			// 其他密钥在解密前即被拒绝，错误信息包含两个指纹
+			_, err = tt.newService(t).DecryptData(context.Background(), bytes.NewReader(sealed))
+			cryptoErr, ok := err.(*errors.CryptoErrorInterface)
+			if !ok || cryptoErr.Code != errors.ErrWrongKey {
+				t.Fatalf("expected wrong key error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), FormatFingerprint(recorded)) {
+				t.Errorf("error does not name the expected key: %v", err)
+			}
+		})
+	}
+}
+
+func TestAlgorithmFingerprintMatchesHeader(t *testing.T) {
+	config := writeTestKeys[X25519Config](t, constants.AlgorithmX25519)
+	service, err := X25519Service(config)
+	if err != nil {
+		t.Fatalf("X25519Service failed: %v", err)
+	}
+	encrypted, err := service.EncryptData(context.Background(), strings.NewReader("fingerprint"))
+	if err != nil {
+		t.Fatalf("EncryptData failed: %v", err)
+	}
+	header, _, err := format.Open(encrypted)
+	if err != nil {
+		t.Fatalf("format.Open failed: %v", err)
+	}
+	recorded, _ := header.Get(format.TagKeyFingerprint)
+
+	algorithm, _ := LookupAlgorithm(constants.AlgorithmX25519)
+	fingerprint, err := algorithm.Fingerprint(map[string]string{
+		"x25519_public_key":  config.PublicKeyPath,
+		"x25519_private_key": config.PrivateKeyPath,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+	if !bytes.Equal(fingerprint, recorded) {
+		t.Errorf("fingerprint %x does not match header %x", fingerprint, recorded)
+	}
+}
@@ -0,0 +1,102 @@
+package crypto
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testParameters 测试密钥使用的算法参数
+var testParameters = Parameters{
+	AESKeySize:      32,
+	RSAKeySize:      2048,
+	KMACKeySize:     32,
+	Argon2Time:      1,
+	Argon2MemoryKiB: 8 * 1024,
+	Argon2Threads:   1,
+}
+
+// testSettings 测试用的 Settings，密钥文件路径按 KeyFile.Name 索引
+type testSettings map[string]string
+
+func (s testSettings) AlgorithmParameters() Parameters   { return testParameters }
+func (s testSettings) GetKeyFilePath(name string) string { return s[name] }
+func (s testSettings) GetRSARecipientPaths() []string    { return nil }
+
+// writeTestKeys 用注册的密钥生成函数在 t.TempDir() 中生成算法的密钥文件，返回该算法 Configure 构建的配置
+func writeTestKeys[C any](t *testing.T, name string) *C {
+	t.Helper()
+
+	algorithm, ok := LookupAlgorithm(name)
+	if !ok || algorithm.GenerateKeys == nil {
+		t.Fatalf("algorithm %s has no key files", name)
+	}
+	files, err := algorithm.GenerateKeys(testParameters)
+	if err != nil {
+		t.Fatalf("%s: GenerateKeys failed: %v", name, err)
+	}
+
+	dir := t.TempDir()
+	settings := testSettings{}
+	for _, keyFile := range algorithm.KeyFiles {
+		path := filepath.Join(dir, keyFile.Name)
+		mode := os.FileMode(0644)
+		if keyFile.Private {
+			mode = 0600
+		}
+		if err := os.WriteFile(path, files[keyFile.Name], mode); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+		settings[keyFile.Name] = path
+	}
+
+	config, err := algorithm.Configure(settings, ConfigureOptions{Selected: true})
+	if err != nil {
+		t.Fatalf("%s: Configure failed: %v", name, err)
+	}
+	return config.(*C)
+}
+
+// writeTestSigningKey 在 t.TempDir() 中生成 Ed25519 签名私钥，返回启用签名的配置
+func writeTestSigningKey(t *testing.T) *SigningConfig {
+	t.Helper()
+
+	privateKey, err := GenerateEd25519Key()
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key failed: %v", err)
+	}
+	config := &SigningConfig{PrivateKeyPath: filepath.Join(t.TempDir(), "signing.key"), Sign: true}
+	if err := os.WriteFile(config.PrivateKeyPath, []byte(EncodeEd25519PrivateKey(privateKey)+"\n"), 0600); err != nil {
+		t.Fatalf("write signing key: %v", err)
+	}
+	return config
+}
+
+// writeTestSSHKeys 在 t.TempDir() 中以 OpenSSH 格式写出公钥行和私钥，passphrase 非空时加密私钥
+func writeTestSSHKeys(t *testing.T, key any, passphrase string) (string, string) {
+	t.Helper()
+
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("NewSignerFromKey failed: %v", err)
+	}
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(key, "test")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "test", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatalf("MarshalPrivateKey failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	publicPath := filepath.Join(dir, "id.pub")
+	privatePath := filepath.Join(dir, "id")
+	os.WriteFile(publicPath, ssh.MarshalAuthorizedKey(signer.PublicKey()), 0644)
+	os.WriteFile(privatePath, pem.EncodeToMemory(block), 0600)
+	return publicPath, privatePath
+}
@@ -0,0 +1,208 @@
+package crypto
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hycrypt/internal/domain"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/jwe"
+	"io"
+	"strings"
+	"time"
+)
+
+// jweDirCustomization KMAC256 派生 JWE dir 密钥的自定义字符串
+const jweDirCustomization = "hycrypt jwe dir A256GCM"
+
+// JWEService 支持 JWE 格式的加密服务
+type JWEService interface {
+	EncryptJWE(plaintext []byte) (*jwe.Object, error)
+	DecryptJWE(object *jwe.Object) ([]byte, error)
+}
+
+// DetectJWEMethod 根据 JWE 的 alg 推断对应的加密方法，无法识别时返回空
+func DetectJWEMethod(text string) string {
+	object, err := jwe.Parse(text)
+	if err != nil {
+		return ""
+	}
+	for _, algorithm := range Algorithms() {
+		if algorithm.JWEAlgorithm != "" && algorithm.JWEAlgorithm == object.Algorithm() {
+			return algorithm.Name
+		}
+	}
+	return ""
+}
+
+// EncryptJWE 使用 RSA-OAEP-256 为自身公钥和额外接收方封装内容加密密钥
+func (r *RSAServiceInterface) EncryptJWE(plaintext []byte) (*jwe.Object, error) {
+	return jwe.EncryptRSA(plaintext, r.recipientKeys())
+}
+
+// DecryptJWE 使用 RSA 私钥解密 JWE
+func (r *RSAServiceInterface) DecryptJWE(object *jwe.Object) ([]byte, error) {
+	if r.privateKey == nil {
+		if err := r.loadPrivateKey(); err != nil {
+			return nil, err
+		}
+	}
+	return object.DecryptRSA(r.privateKey)
+}
+
+// EncryptJWE 使用 KMAC 密钥派生的 dir 密钥加密
+func (k *KMACServiceInterface) EncryptJWE(plaintext []byte) (*jwe.Object, error) {
+	key := k.deriveJWEKey()
+	defer k.clearKey(key)
+	return jwe.EncryptDirect(plaintext, key, jweKeyID(key))
+}
+
+// DecryptJWE 使用 KMAC 密钥派生的 dir 密钥解密，kid 不匹配时说明密钥不同
+func (k *KMACServiceInterface) DecryptJWE(object *jwe.Object) ([]byte, error) {
+	key := k.deriveJWEKey()
+	defer k.clearKey(key)
+	if kid := object.Protected.Kid; kid != "" && kid != jweKeyID(key) {
+		return nil, fmt.Errorf("jwe was encrypted with a different key (kid %s)", kid)
+	}
+	return object.DecryptDirect(key)
+}
+
+// deriveJWEKey 派生 dir 密钥: KMAC256(key, "", 32, jweDirCustomization)
+func (k *KMACServiceInterface) deriveJWEKey() []byte {
+	return kmac256(k.config.Key, nil, 32, []byte(jweDirCustomization))
+}
+
+// jweKeyID dir 密钥的标识：SHA-256 前 8 字节的 base64url 编码
+func jweKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// jweService 返回支持 JWE 的加密服务
+func (p *UnifiedProcessor) jweService(method string) (JWEService, error) {
+	service, err := p.getCryptoService(method)
+	if err != nil {
+		return nil, err
+	}
+	jweService, ok := service.(JWEService)
+	if !ok {
+		return nil, errors.InvalidConfig(fmt.Sprintf("jwe supports %s methods, got %s",
+			strings.Join(JWEAlgorithms(), ", "), method), nil)
+	}
+	return jweService, nil
+}
+
+// encryptJWE 加密为 JWE 紧凑或 JSON 序列化文本并写入输出
+func (p *UnifiedProcessor) encryptJWE(ctx context.Context, source domain.DataSource, sink domain.DataSink, opts domain.CryptoOptions) (*domain.CryptoResult, error) {
+	startTime := time.Now()
+
+	service, err := p.jweService(opts.Method)
+	if err != nil {
+		return nil, err
+	}
+	// JWE 没有签名字段
+	if p.signer != nil && p.signer.config.Sign {
+		return nil, errors.InvalidConfig("signing is not supported for jwe output", nil)
+	}
+
+	reader, err := source.Read(ctx)
+	if err != nil {
+		return nil, errors.EncryptionFailed(opts.Method, err)
+	}
+	defer reader.Close()
+
+	plaintext, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.EncryptionFailed(opts.Method, err)
+	}
+	defer clearBytes(plaintext)
+
+	object, err := service.EncryptJWE(plaintext)
+	if err != nil {
+		return nil, errors.EncryptionFailed(opts.Method, err)
+	}
+
+	var serialized string
+	if opts.OutputFormat == domain.OutputJWEJSON {
+		encoded, err := object.JSON()
+		if err != nil {
+			return nil, errors.EncryptionFailed(opts.Method, err)
+		}
+		serialized = string(encoded)
+	} else {
+		// 多个接收方只能使用 JSON 序列化
+		serialized, err = object.Compact()
+		if err != nil {
+			return nil, errors.InvalidConfig("compact jwe supports a single recipient, use jwe-json", err)
+		}
+	}
+
+	if err := sink.Write(ctx, strings.NewReader(serialized)); err != nil {
+		return nil, errors.EncryptionFailed(opts.Method, err)
+	}
+
+	return &domain.CryptoResult{
+		Success:       true,
+		OutputPath:    sink.Path(),
+		ProcessedSize: source.Size(),
+		Method:        opts.Method,
+		ProcessTime:   time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
+// decryptJWE 解析 JWE 文本，根据 alg 选择解密服务
+func (p *UnifiedProcessor) decryptJWE(ctx context.Context, source domain.DataSource, sink domain.DataSink, opts domain.CryptoOptions) (*domain.CryptoResult, error) {
+	startTime := time.Now()
+
+	reader, err := source.Read(ctx)
+	if err != nil {
+		return nil, discardOutput(sink, errors.DecryptionFailed(opts.Method, err))
+	}
+	defer reader.Close()
+
+	text, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, discardOutput(sink, errors.DecryptionFailed(opts.Method, err))
+	}
+
+	object, err := jwe.Parse(string(text))
+	if err != nil {
+		return nil, discardOutput(sink, errors.InvalidFormat("jwe", err))
+	}
+
+	method := DetectJWEMethod(string(text))
+	if method == "" {
+		return nil, discardOutput(sink, errors.InvalidFormat("jwe", fmt.Errorf("unsupported alg: %q", object.Algorithm())))
+	}
+
+	if p.signer != nil && p.signer.config.RequireSignature {
+		return nil, discardOutput(sink, errors.SignatureInvalid("jwe is not signed", nil))
+	}
+
+	service, err := p.jweService(method)
+	if err != nil {
+		return nil, discardOutput(sink, err)
+	}
+
+	plaintext, err := service.DecryptJWE(object)
+	if err != nil {
+		if cryptoErr, ok := err.(*errors.CryptoErrorInterface); ok {
+			return nil, discardOutput(sink, cryptoErr)
+		}
+		return nil, discardOutput(sink, errors.DecryptionFailed(method, err))
+	}
+	defer clearBytes(plaintext)
+
+	if err := sink.Write(ctx, strings.NewReader(string(plaintext))); err != nil {
+		return nil, discardOutput(sink, errors.DecryptionFailed(method, err))
+	}
+
+	return &domain.CryptoResult{
+		Success:       true,
+		OutputPath:    sink.Path(),
+		ProcessedSize: source.Size(),
+		Method:        method,
+		ProcessTime:   time.Since(startTime).Milliseconds(),
+	}, nil
+}
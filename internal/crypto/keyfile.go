@@ -0,0 +1,259 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/pkcs8"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// EncryptedPrivateKeyPEMType 口令加密的 PKCS#8 私钥（与 OpenSSL 兼容）
+	EncryptedPrivateKeyPEMType = "ENCRYPTED PRIVATE KEY"
+	// EncryptedKMACKeyPEMType 口令加密的 KMAC 密钥，内容同样是 PBES2 EncryptedPrivateKeyInfo
+	EncryptedKMACKeyPEMType = "HYCRYPT ENCRYPTED KMAC KEY"
+
+	openSSHPrivateKeyPEMType = "OPENSSH PRIVATE KEY"
+)
+
+// IsProtectedKeyFile 检查密钥文件是否受口令保护（加密 PKCS#8、加密 KMAC 密钥、OpenSSH 或传统加密 PEM）
+func IsProtectedKeyFile(path string) bool {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	if block, _ := pem.Decode(keyData); block != nil {
+		switch block.Type {
+		case EncryptedPrivateKeyPEMType, EncryptedKMACKeyPEMType:
+			return true
+		}
+	}
+	_, err = ssh.ParseRawPrivateKey(keyData)
+	_, ok := err.(*ssh.PassphraseMissingError)
+	return ok
+}
+
+// decryptPEMBlock 解密 PBES2 加密的 PEM 块，口令错误时返回 ErrPermissionDenied
+func decryptPEMBlock(block *pem.Block, path string, passphrase PassphraseFunc) ([]byte, error) {
+	if passphrase == nil {
+		return nil, errors.InvalidConfig(fmt.Sprintf("key file is passphrase protected: %s", path), nil)
+	}
+	secret, err := passphrase(path)
+	if err != nil {
+		return nil, err
+	}
+	defer clearBytes(secret)
+
+	plaintext, err := pkcs8.Decrypt(block.Bytes, secret)
+	if err == pkcs8.ErrIncorrectPassphrase {
+		return nil, incorrectPassphrase(path, err)
+	}
+	if err != nil {
+		return nil, errors.InvalidFormat("encrypted key file", err)
+	}
+	return plaintext, nil
+}
+
+// incorrectPassphrase 口令错误统一返回 ErrPermissionDenied
+func incorrectPassphrase(path string, cause error) error {
+	return errors.CryptoError(errors.ErrPermissionDenied, "incorrect passphrase for private key", cause).
+		WithContext("path", path)
+}
+
+// decryptPKCS8PrivateKey 解密 ENCRYPTED PRIVATE KEY 块并解析其中的 PKCS#8 私钥
+func decryptPKCS8PrivateKey(block *pem.Block, path string, passphrase PassphraseFunc) (crypto.PrivateKey, error) {
+	der, err := decryptPEMBlock(block, path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer clearBytes(der)
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		// CBC 模式下错误口令偶尔能通过填充检查，此时解析必然失败
+		return nil, incorrectPassphrase(path, err)
+	}
+	return key, nil
+}
+
+// EncryptPrivateKeyPEM 将私钥编码为口令加密的 PKCS#8 PEM（scrypt + AES-256-CBC，OpenSSL 可直接读取）
+func EncryptPrivateKeyPEM(key crypto.PrivateKey, passphrase []byte) ([]byte, error) {
+	if ed25519Key, ok := asEd25519PrivateKey(key); ok {
+		key = ed25519Key
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, errors.InvalidFormat("private key", err)
+	}
+	defer clearBytes(der)
+
+	encrypted, err := pkcs8.Encrypt(der, passphrase, pkcs8.AES256CBC)
+	if err != nil {
+		return nil, errors.EncryptionFailed("pkcs8", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: EncryptedPrivateKeyPEMType, Bytes: encrypted}), nil
+}
+
+// EncodeKMACKey 编码 KMAC 密钥：无口令时为十六进制文本，有口令时为 scrypt + AES-256-GCM 加密的 PEM
+func EncodeKMACKey(key, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return []byte(hex.EncodeToString(key)), nil
+	}
+
+	encrypted, err := pkcs8.Encrypt(key, passphrase, pkcs8.AES256GCM)
+	if err != nil {
+		return nil, errors.EncryptionFailed("pkcs8", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: EncryptedKMACKeyPEMType, Bytes: encrypted}), nil
+}
+
+// DecodeKMACKey 解码 KMAC 密钥文件内容（十六进制文本或加密 PEM），受保护时通过 passphrase 获取口令
+func DecodeKMACKey(keyData []byte, path string, passphrase PassphraseFunc) ([]byte, error) {
+	if block, _ := pem.Decode(keyData); block != nil {
+		if block.Type != EncryptedKMACKeyPEMType {
+			return nil, errors.InvalidFormat("kmac key", fmt.Errorf("unexpected PEM type: %s", block.Type))
+		}
+		return decryptPEMBlock(block, path, passphrase)
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(keyData)))
+	if err != nil {
+		return nil, errors.InvalidFormat("kmac key", err)
+	}
+	return key, nil
+}
+
+// ChangeKeyFilePassphrase 设置、修改或移除密钥文件的口令，newPassphrase 为空时移除保护
+// 支持 KMAC 密钥、PEM 私钥（加密后统一为 PKCS#8，移除保护后 RSA 恢复为 PKCS#1）与 OpenSSH 私钥（保持 OpenSSH 格式，注释不保留）
+func ChangeKeyFilePassphrase(path string, current PassphraseFunc, newPassphrase []byte) error {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return errors.KeyNotFound("private", path)
+	}
+
+	if err := checkProtectableKeyData(keyData, path); err != nil {
+		return err
+	}
+
+	var encoded []byte
+	block, _ := pem.Decode(keyData)
+	switch {
+	case block == nil || block.Type == EncryptedKMACKeyPEMType:
+		key, err := DecodeKMACKey(keyData, path, current)
+		if err != nil {
+			return err
+		}
+		defer clearBytes(key)
+		if encoded, err = EncodeKMACKey(key, newPassphrase); err != nil {
+			return err
+		}
+
+	case block.Type == openSSHPrivateKeyPEMType:
+		key, err := readPrivateKey(path, current)
+		if err != nil {
+			return err
+		}
+		var sshBlock *pem.Block
+		if len(newPassphrase) == 0 {
+			sshBlock, err = ssh.MarshalPrivateKey(key, "")
+		} else {
+			sshBlock, err = ssh.MarshalPrivateKeyWithPassphrase(key, "", newPassphrase)
+		}
+		if err != nil {
+			return errors.InvalidFormat("openssh private key", err)
+		}
+		encoded = pem.EncodeToMemory(sshBlock)
+
+	case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+		key, err := readPrivateKey(path, current)
+		if err != nil {
+			return err
+		}
+		if len(newPassphrase) > 0 {
+			if encoded, err = EncryptPrivateKeyPEM(key, newPassphrase); err != nil {
+				return err
+			}
+		} else if encoded, err = encodePlainPrivateKeyPEM(key); err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(path, bytes.NewReader(encoded), 0600)
+}
+
+// CheckProtectableKeyFile 检查密钥文件是否为可设置口令的格式（KMAC 密钥、PEM 私钥或 OpenSSH 私钥）
+func CheckProtectableKeyFile(path string) error {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return errors.KeyNotFound("private", path)
+	}
+	return checkProtectableKeyData(keyData, path)
+}
+
+// checkProtectableKeyData 非 PEM 文件只接受十六进制 KMAC 密钥，PEM 文件只接受私钥
+func checkProtectableKeyData(keyData []byte, path string) error {
+	block, _ := pem.Decode(keyData)
+	if block == nil {
+		if _, err := hex.DecodeString(strings.TrimSpace(string(keyData))); err == nil {
+			return nil
+		}
+		return errors.CryptoError(errors.ErrInvalidInput, "unsupported key file format", nil).WithContext("path", path)
+	}
+	if block.Type == EncryptedKMACKeyPEMType || strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return nil
+	}
+	return errors.CryptoError(errors.ErrInvalidInput, fmt.Sprintf("unsupported key file format: %s", block.Type), nil).
+		WithContext("path", path)
+}
+
+// encodePlainPrivateKeyPEM 未加密的私钥 PEM：RSA 使用 keygen 生成的 PKCS#1，其余使用 PKCS#8
+func encodePlainPrivateKeyPEM(key crypto.PrivateKey) ([]byte, error) {
+	if rsaKey, ok := key.(*rsa.PrivateKey); ok {
+		return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), nil
+	}
+	if ed25519Key, ok := asEd25519PrivateKey(key); ok {
+		key = ed25519Key
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, errors.InvalidFormat("private key", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// writeFileAtomic 将 content 写入同目录的临时文件并以 mode 权限重命名为 path，避免中断时留下损坏的文件
+func writeFileAtomic(path string, content io.Reader, mode os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if err := temp.Chmod(mode); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if _, err := io.Copy(temp, content); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,107 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/pem"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// staticPassphrase 返回固定口令的 PassphraseFunc
+func staticPassphrase(passphrase string) PassphraseFunc {
+	return func(string) ([]byte, error) { return []byte(passphrase), nil }
+}
+
+func TestChangeKeyFilePassphrase(t *testing.T) {
+	dir := t.TempDir()
+	rsaPath := writeTestKeys[RSAConfig](t, constants.AlgorithmRSA).PrivateKeyPath
+	original, err := readPrivateKey(rsaPath, nil)
+	if err != nil {
+		t.Fatalf("readPrivateKey failed: %v", err)
+	}
+	rsaKey := original.(*rsa.PrivateKey)
+
+	kmacKey := bytes.Repeat([]byte{0x5a}, 32)
+	kmacPath := filepath.Join(dir, "kmac.key")
+	os.WriteFile(kmacPath, []byte(hex.EncodeToString(kmacKey)), 0600)
+
+	// load 读取密钥文件并与原始密钥比较
+	testCases := []struct {
+		name      string
+		path      string
+		plainType string
+		load      func(PassphraseFunc) (bool, error)
+	}{
+		{"rsa", rsaPath, "RSA PRIVATE KEY", func(passphrase PassphraseFunc) (bool, error) {
+			key, err := readPrivateKey(rsaPath, passphrase)
+			if err != nil {
+				return false, err
+			}
+			return rsaKey.Equal(key), nil
+		}},
+		{"kmac", kmacPath, "", func(passphrase PassphraseFunc) (bool, error) {
+			data, _ := os.ReadFile(kmacPath)
+			key, err := DecodeKMACKey(data, kmacPath, passphrase)
+			if err != nil {
+				return false, err
+			}
+			return bytes.Equal(key, kmacKey), nil
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ChangeKeyFilePassphrase(tc.path, nil, []byte("first")); err != nil {
+				t.Fatalf("protect failed: %v", err)
+			}
+			if !IsProtectedKeyFile(tc.path) {
+				t.Fatal("key file not protected")
+			}
+			if _, err := tc.load(nil); err == nil {
+				t.Error("protected key loaded without passphrase")
+			}
+			if _, err := tc.load(staticPassphrase("wrong")); err == nil {
+				t.Error("wrong passphrase accepted")
+			} else if cryptoErr, ok := err.(*errors.CryptoErrorInterface); !ok || cryptoErr.Code != errors.ErrPermissionDenied {
+				t.Errorf("wrong passphrase: got %v, expected ErrPermissionDenied", err)
+			}
+
+			if err := ChangeKeyFilePassphrase(tc.path, staticPassphrase("first"), []byte("second")); err != nil {
+				t.Fatalf("change failed: %v", err)
+			}
+			if ok, err := tc.load(staticPassphrase("second")); err != nil || !ok {
+				t.Fatalf("load with new passphrase failed: %v", err)
+			}
+
+			if err := ChangeKeyFilePassphrase(tc.path, staticPassphrase("second"), nil); err != nil {
+				t.Fatalf("unprotect failed: %v", err)
+			}
+			if IsProtectedKeyFile(tc.path) {
+				t.Fatal("key file still protected")
+			}
+			if ok, err := tc.load(nil); err != nil || !ok {
+				t.Fatalf("load unprotected key failed: %v", err)
+			}
+
+			// 移除口令后恢复原来的格式
+			data, _ := os.ReadFile(tc.path)
+			if block, _ := pem.Decode(data); tc.plainType != "" && (block == nil || block.Type != tc.plainType) {
+				t.Errorf("unprotected key is not %s", tc.plainType)
+			} else if tc.plainType == "" && block != nil {
+				t.Errorf("unprotected kmac key is PEM: %s", block.Type)
+			}
+		})
+	}
+}
+
+func TestChangeKeyFilePassphraseRejectsPublicKey(t *testing.T) {
+	config := writeTestKeys[RSAConfig](t, constants.AlgorithmRSA)
+	if err := ChangeKeyFilePassphrase(config.PublicKeyPath, nil, []byte("secret")); err == nil {
+		t.Error("public key accepted")
+	}
+}
@@ -0,0 +1,110 @@
+package crypto
+
+import (
+	"encoding/pem"
+	"fmt"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/shamir"
+	"os"
+	"path/filepath"
+)
+
+// 分片记录的密钥文件类型
+const (
+	KeyShareKindKMAC       = "kmac"
+	KeyShareKindPrivateKey = "private-key"
+)
+
+// SplitKeyFile 将密钥文件按 Shamir 方案拆分，任意 threshold 个分片可恢复原文件
+// 分片保存文件原始内容，受口令保护的密钥恢复后仍需原口令
+func SplitKeyFile(path string, threshold, total int) ([]*shamir.Share, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.KeyNotFound("private", path)
+	}
+	kind, err := keyShareKind(keyData, path)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := shamir.SplitShares(keyData, kind, filepath.Base(path), threshold, total)
+	if err != nil {
+		return nil, errors.CryptoError(errors.ErrInvalidInput, "failed to split key file", err).WithContext("path", path)
+	}
+	return shares, nil
+}
+
+// CombineKeyShares 合并分片恢复密钥文件内容，并确认恢复结果是分片声明类型的密钥文件
+func CombineKeyShares(shares []*shamir.Share) ([]byte, error) {
+	keyData, err := shamir.CombineShares(shares)
+	if err != nil {
+		return nil, errors.CryptoError(errors.ErrInvalidInput, "failed to combine key shares", err)
+	}
+
+	// 分片数量不足门限以外的错误（如混入其他拆分的分片）会得到无效数据
+	if kind, err := keyShareKind(keyData, "recovered key"); err != nil || kind != shares[0].Kind {
+		return nil, errors.CryptoError(errors.ErrInvalidInput, "recovered data is not a valid key file", err)
+	}
+	return keyData, nil
+}
+
+// keyShareKind 根据文件内容判断密钥类型，只接受可设置口令的密钥格式
+func keyShareKind(keyData []byte, path string) (string, error) {
+	if err := checkProtectableKeyData(keyData, path); err != nil {
+		return "", err
+	}
+	if block, _ := pem.Decode(keyData); block == nil || block.Type == EncryptedKMACKeyPEMType {
+		return KeyShareKindKMAC, nil
+	}
+	return KeyShareKindPrivateKey, nil
+}
+
+// WriteRecoveredKeyFile 写出恢复的密钥文件，目标已存在时拒绝覆盖
+func WriteRecoveredKeyFile(path string, keyData []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return errors.CryptoError(errors.ErrInvalidInput, fmt.Sprintf("key file already exists: %s", path), nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create key file: %w", err)
+	}
+	if _, err := file.Write(keyData); err != nil {
+		file.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return file.Close()
+}
+
+// WriteKeyShares 将分片分别写入 outputDir（默认当前目录）下的文本文件，返回写入的路径
+func WriteKeyShares(shares []*shamir.Share, outputDir string) ([]string, error) {
+	if outputDir == "" {
+		outputDir = "."
+	}
+	if err := os.MkdirAll(outputDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create share directory: %w", err)
+	}
+
+	paths := make([]string, 0, len(shares))
+	for _, share := range shares {
+		name := fmt.Sprintf("%s-%s-share-%d-of-%d.txt", share.Name, share.ID, share.Index, share.Total)
+		path := filepath.Join(outputDir, name)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create share file: %w", err)
+		}
+		_, err = file.Write(share.Encode())
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to write share file: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
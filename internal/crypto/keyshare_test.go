@@ -0,0 +1,51 @@
+package crypto
+
+import (
+	"bytes"
+	"hycrypt/internal/constants"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	rsaPath := writeTestKeys[RSAConfig](t, constants.AlgorithmRSA).PrivateKeyPath
+
+	shares, err := SplitKeyFile(rsaPath, 2, 3)
+	if err != nil {
+		t.Fatalf("SplitKeyFile failed: %v", err)
+	}
+	if shares[0].Kind != KeyShareKindPrivateKey {
+		t.Errorf("kind = %q, expected %q", shares[0].Kind, KeyShareKindPrivateKey)
+	}
+
+	recovered, err := CombineKeyShares(shares[1:])
+	if err != nil {
+		t.Fatalf("CombineKeyShares failed: %v", err)
+	}
+	original, _ := os.ReadFile(rsaPath)
+	if !bytes.Equal(recovered, original) {
+		t.Error("recovered key file differs from original")
+	}
+
+	// 恢复时不覆盖现有密钥文件
+	if err := WriteRecoveredKeyFile(rsaPath, recovered); err == nil {
+		t.Error("WriteRecoveredKeyFile overwrote existing key file")
+	}
+	restoredPath := filepath.Join(dir, "restored.pem")
+	if err := WriteRecoveredKeyFile(restoredPath, recovered); err != nil {
+		t.Fatalf("WriteRecoveredKeyFile failed: %v", err)
+	}
+	service := &RSAServiceInterface{config: &RSAConfig{PrivateKeyPath: restoredPath}}
+	if err := service.loadPrivateKey(); err != nil {
+		t.Errorf("restored key cannot be loaded: %v", err)
+	}
+}
+
+func TestSplitKeyFileRejectsPublicKey(t *testing.T) {
+	config := writeTestKeys[RSAConfig](t, constants.AlgorithmRSA)
+	if _, err := SplitKeyFile(config.PublicKeyPath, 2, 3); err == nil {
+		t.Error("SplitKeyFile accepted a public key")
+	}
+}
@@ -0,0 +1,59 @@
+package crypto
+
+import (
+	"golang.org/x/crypto/sha3"
+)
+
+// kmac256Rate cSHAKE256 的速率（字节），用于 bytepad
+const kmac256Rate = 136
+
+// kmac256 按 NIST SP 800-185 计算 KMAC256(K, X, L, S)
+//
+// 输出长度 outLen 以字节为单位。
+func kmac256(key, data []byte, outLen int, customization []byte) []byte {
+	h := sha3.NewCShake256([]byte("KMAC"), customization)
+
+	// bytepad(encode_string(K), 136)
+	encodedKey := append(leftEncode(uint64(len(key))*8), key...)
+	prefix := leftEncode(kmac256Rate)
+	h.Write(prefix)
+	h.Write(encodedKey)
+	if pad := (len(prefix) + len(encodedKey)) % kmac256Rate; pad != 0 {
+		h.Write(make([]byte, kmac256Rate-pad))
+	}
+
+	h.Write(data)
+	h.Write(rightEncode(uint64(outLen) * 8))
+
+	out := make([]byte, outLen)
+	h.Read(out)
+	return out
+}
+
+// leftEncode SP 800-185 left_encode
+func leftEncode(x uint64) []byte {
+	b := minimalBytes(x)
+	return append([]byte{byte(len(b))}, b...)
+}
+
+// rightEncode SP 800-185 right_encode
+func rightEncode(x uint64) []byte {
+	b := minimalBytes(x)
+	return append(b, byte(len(b)))
+}
+
+// minimalBytes 返回 x 的最短大端表示（至少一个字节）
+func minimalBytes(x uint64) []byte {
+	var buf [8]byte
+	n := 0
+	for v := x; v > 0; v >>= 8 {
+		n++
+	}
+	if n == 0 {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		buf[7-i] = byte(x >> (8 * i))
+	}
+	return buf[8-n:]
+}
@@ -1,22 +1,112 @@
 package crypto
 
 import (
+	"bytes"
 	"context"
+	"crypto/cipher"
 	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"hycrypt/internal/constants"
 	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"hycrypt/internal/jwe"
 	"io"
+	"os"
 	"strings"
 
 	"golang.org/x/crypto/sha3"
 )
 
+// kmacCustomization KMAC256 密钥派生的自定义字符串
+const kmacCustomization = "hycrypt/v3 aes-gcm key"
+
+func init() {
+	Register(&Algorithm{
+		Name:         constants.AlgorithmKMAC,
+		HeaderID:     format.AlgorithmKMAC,
+		DisplayName:  "KMAC",
+		Icon:         "🔑",
+		Order:        20,
+		KeyFiles:     []KeyFile{{Name: "kmac_key", Private: true, ShareKind: KeyShareKindKMAC}},
+		JWEAlgorithm: jwe.AlgDirect,
+		SymmetricKey: &SymmetricKey{
+			Size:   func(params Parameters) int { return params.KMACKeySize },
+			Decode: DecodeKMACKey,
+			Encode: EncodeKMACKey,
+		},
+		Configure: func(settings Settings, options ConfigureOptions) (any, error) {
+			path := settings.GetKeyFilePath("kmac_key")
+			// 受口令保护的密钥仅在使用 KMAC 时解锁
+			if !options.Selected && IsProtectedKeyFile(path) {
+				return nil, nil
+			}
+			params := settings.AlgorithmParameters()
+			key, err := LoadKMACKey(path, params.KMACKeySize, options.Passphrase)
+			if err != nil {
+				return nil, err
+			}
+			return &KMACConfig{
+				Key:        key,
+				KeySize:    params.KMACKeySize,
+				AESKeySize: params.AESKeySize,
+			}, nil
+		},
+		NewService: newServiceFunc(KMACService),
+		Validate: func(params Parameters) error {
+			if params.KMACKeySize != 16 && params.KMACKeySize != 32 && params.KMACKeySize != 64 {
+				return fmt.Errorf("KMAC key size must be 16, 32 or 64 bytes")
+			}
+			return nil
+		},
+		GenerateKeys: func(params Parameters) (map[string][]byte, error) {
+			key := make([]byte, params.KMACKeySize)
+			if _, err := rand.Read(key); err != nil {
+				return nil, err
+			}
+			// 密钥文件保存十六进制文本
+			return map[string][]byte{"kmac_key": []byte(hex.EncodeToString(key))}, nil
+		},
+		Fingerprint: func(paths map[string]string, passphrase PassphraseFunc) ([]byte, error) {
+			path := paths["kmac_key"]
+			keyData, err := os.ReadFile(path)
+			if err != nil {
+				return nil, errors.KeyNotFound(constants.AlgorithmKMAC, path)
+			}
+			key, err := DecodeKMACKey(keyData, path, passphrase)
+			if err != nil {
+				return nil, err
+			}
+			defer clearBytes(key)
+			return KMACKeyFingerprint(key), nil
+		},
+	})
+}
+
 // KMACServiceInterface KMAC加密服务
 type KMACServiceInterface struct {
 	*BaseService
 	config *KMACConfig
 }
 
+// LoadKMACKey 读取 KMAC 密钥文件并校验密钥长度，受口令保护时通过 passphrase 获取口令
+func LoadKMACKey(path string, keySize int, passphrase PassphraseFunc) ([]byte, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.KeyNotFound(constants.AlgorithmKMAC, path)
+	}
+	key, err := DecodeKMACKey(keyData, path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		clearBytes(key)
+		return nil, errors.InvalidConfig("KMAC key length mismatch",
+			fmt.Errorf("expected %d bytes, got %d", keySize, len(key)))
+	}
+	return key, nil
+}
+
 func KMACService(config *KMACConfig) (*KMACServiceInterface, error) {
 	service := &KMACServiceInterface{
 		BaseService: &BaseService{config: config},
@@ -31,37 +121,49 @@ func KMACService(config *KMACConfig) (*KMACServiceInterface, error) {
 }
 
 func (k *KMACServiceInterface) EncryptData(ctx context.Context, data io.Reader) (io.Reader, error) {
-	plaintext, err := io.ReadAll(data)
-	if err != nil {
-		return nil, errors.EncryptionFailed(constants.AlgorithmKMAC, err)
-	}
-
 	// 生成随机salt
 	salt := make([]byte, 16)
 	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
 		return nil, errors.EncryptionFailed(constants.AlgorithmKMAC, err)
 	}
 
-	// 派生AES密钥
-	aesKey := k.deriveKey(salt, k.config.AESKeySize)
-	defer k.clearKey(aesKey)
+	// 构建头部: salt、密钥长度和密钥指纹记录在参数区
+	header := format.FromContext(ctx, format.AlgorithmKMAC)
+	header.KDF = format.KDFKMAC256
+	keySize := selectAEAD(header, k.config.AESKeySize)
+	header.Set(format.TagSalt, salt)
+	header.SetKeySize(keySize)
+	header.Set(format.TagKeyFingerprint, k.fingerprint())
 
-	// AES加密
-	ciphertext, err := encryptAESGCM(aesKey, plaintext)
+	// 派生数据密钥，AEAD 实例创建后即可清除原始密钥
+	aesKey := k.deriveKMAC256(salt, keySize)
+	aead, err := newAEAD(header.AEAD, aesKey)
+	k.clearKey(aesKey)
 	if err != nil {
 		return nil, errors.EncryptionFailed(constants.AlgorithmKMAC, err)
 	}
 
-	// 组合结果: [salt][ciphertext]
-	result := make([]byte, 16+len(ciphertext))
-	copy(result[:16], salt)
-	copy(result[16:], ciphertext)
-
-	return strings.NewReader(string(result)), nil
+	// 分块流式加密: [header][chunk...]
+	result, err := sealStream(ctx, header, aead, data)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmKMAC, err)
+	}
+	return result, nil
 }
 
 func (k *KMACServiceInterface) DecryptData(ctx context.Context, data io.Reader) (io.Reader, error) {
-	ciphertext, err := io.ReadAll(data)
+	header, body, err := format.Open(data)
+	if err != nil {
+		return nil, errors.InvalidFormat("kmac encrypted data", err)
+	}
+
+	// 带头部的新格式
+	if header != nil {
+		return k.decryptWithHeader(ctx, header, body)
+	}
+
+	// 旧格式: [salt][ciphertext]
+	ciphertext, err := io.ReadAll(body)
 	if err != nil {
 		return nil, errors.DecryptionFailed(constants.AlgorithmKMAC, err)
 	}
@@ -87,6 +189,98 @@ func (k *KMACServiceInterface) DecryptData(ctx context.Context, data io.Reader)
 	return strings.NewReader(string(plaintext)), nil
 }
 
+// decryptWithHeader 解密带头部的数据
+func (k *KMACServiceInterface) decryptWithHeader(ctx context.Context, header *format.Header, body io.Reader) (io.Reader, error) {
+	// 分块流式格式
+	if header.IsStreaming() {
+		aead, err := k.openAEAD(header)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := openStream(ctx, header, aead, body)
+		if err != nil {
+			return nil, errors.InvalidFormat("kmac encrypted data", err)
+		}
+		return plaintext, nil
+	}
+
+	aesKey, err := k.headerKey(header)
+	if err != nil {
+		return nil, err
+	}
+	defer k.clearKey(aesKey)
+
+	// 版本 1: 单块 AES-GCM
+	if header.AEAD != format.AEADAESGCM {
+		return nil, errors.InvalidFormat("kmac encrypted data",
+			fmt.Errorf("unsupported aead %s for format version %d", header.AEAD, header.Version))
+	}
+	ciphertext, err := io.ReadAll(body)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmKMAC, err)
+	}
+
+	plaintext, err := decryptAESGCM(aesKey, ciphertext)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmKMAC, err)
+	}
+
+	return bytes.NewReader(plaintext), nil
+}
+
+// openAEAD 校验头部与本地密钥，派生分块流式格式使用的 AEAD
+func (k *KMACServiceInterface) openAEAD(header *format.Header) (cipher.AEAD, error) {
+	aesKey, err := k.headerKey(header)
+	if err != nil {
+		return nil, err
+	}
+	defer k.clearKey(aesKey)
+
+	aead, err := newAEAD(header.AEAD, aesKey)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmKMAC, err)
+	}
+	return aead, nil
+}
+
+// headerKey 校验头部并恢复数据加密密钥
+func (k *KMACServiceInterface) headerKey(header *format.Header) ([]byte, error) {
+	if header.Algorithm != format.AlgorithmKMAC {
+		return nil, errors.InvalidFormat("kmac encrypted data",
+			fmt.Errorf("file was encrypted with %s", header.Algorithm))
+	}
+	if err := checkKeyFingerprint(header, constants.AlgorithmKMAC, k.fingerprint()); err != nil {
+		return nil, err
+	}
+	if !isSupportedAEAD(header.AEAD) {
+		return nil, errors.InvalidFormat("kmac encrypted data",
+			fmt.Errorf("unsupported aead: %d", header.AEAD))
+	}
+
+	salt, ok := header.Get(format.TagSalt)
+	if !ok || len(salt) == 0 {
+		return nil, errors.InvalidFormat("kmac encrypted data", fmt.Errorf("missing salt"))
+	}
+
+	keySize := header.KeySize()
+	if keySize == 0 {
+		keySize = k.config.AESKeySize
+	}
+
+	var aesKey []byte
+	switch {
+	case header.KDF == format.KDFKMAC256:
+		aesKey = k.deriveKMAC256(salt, keySize)
+	case header.KDF == format.KDFShake256 && header.Version < format.Version3:
+		// 旧版派生仅保留用于解密
+		aesKey = k.deriveKey(salt, keySize)
+	default:
+		return nil, errors.InvalidFormat("kmac encrypted data",
+			fmt.Errorf("unsupported kdf %d for format version %d", header.KDF, header.Version))
+	}
+	return aesKey, nil
+}
+
 func (k *KMACServiceInterface) ValidateKeys() error {
 	if len(k.config.Key) == 0 {
 		return errors.KeyNotFound(constants.AlgorithmKMAC, "config")
@@ -94,6 +288,17 @@ func (k *KMACServiceInterface) ValidateKeys() error {
 	return nil
 }
 
+// fingerprint 本地 KMAC 密钥指纹
+func (k *KMACServiceInterface) fingerprint() []byte {
+	return KMACKeyFingerprint(k.config.Key)
+}
+
+// deriveKMAC256 使用 KMAC256(key, salt, keyLength, kmacCustomization) 派生AES密钥
+func (k *KMACServiceInterface) deriveKMAC256(salt []byte, keyLength int) []byte {
+	return kmac256(k.config.Key, salt, keyLength, []byte(kmacCustomization))
+}
+
+// deriveKey 旧版 SHAKE256 密钥派生，仅用于解密旧文件
 func (k *KMACServiceInterface) deriveKey(salt []byte, keyLength int) []byte {
 	// 使用SHAKE256作为KMAC的实现
 	h := sha3.NewShake256()
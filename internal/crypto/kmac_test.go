@@ -0,0 +1,54 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// NIST SP 800-185 KMAC256 示例向量
+func TestKMAC256Vectors(t *testing.T) {
+	key, _ := hex.DecodeString("404142434445464748494A4B4C4D4E4F505152535455565758595A5B5C5D5E5F")
+
+	testCases := []struct {
+		name          string
+		data          string
+		customization string
+		expected      string
+	}{
+		{
+			name:          "sample 4",
+			data:          "00010203",
+			customization: "My Tagged Application",
+			expected: "20C570C31346F703C9AC36C61C03CB64C3970D0CFC787E9B79599D273A68D2F7" +
+				"F69D4CC3DE9D104A351689F27CF6F5951F0103F33F4F24871024D9C27773A8DD",
+		},
+		{
+			name:          "sample 6",
+			data:          sequenceHex(0xC8),
+			customization: "My Tagged Application",
+			expected: "B58618F71F92E1D56C1B8C55DDD7CD188B97B4CA4D99831EB2699A837DA2E4D9" +
+				"70FBACFDE50033AEA585F1A2708510C32D07880801BD182898FE476876FC8965",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tc.data)
+			expected, _ := hex.DecodeString(tc.expected)
+
+			got := kmac256(key, data, len(expected), []byte(tc.customization))
+			if hex.EncodeToString(got) != hex.EncodeToString(expected) {
+				t.Errorf("KMAC256 mismatch:\n got  %x\n want %x", got, expected)
+			}
+		})
+	}
+}
+
+// sequenceHex 生成 00 01 02 ... 的十六进制字符串
+func sequenceHex(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,346 @@
+package crypto
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/mlkem"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/pem"
+	"fmt"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+	"os"
+)
+
+func init() {
+	Register(&Algorithm{
+		Name:        constants.AlgorithmMLKEM,
+		HeaderID:    format.AlgorithmMLKEM,
+		DisplayName: "ML-KEM-768 + X25519",
+		Icon:        "🛡️ ",
+		Order:       50,
+		KeyFiles:    []KeyFile{{Name: "mlkem_public_key"}, {Name: "mlkem_private_key", Private: true}},
+		Configure: func(settings Settings, _ ConfigureOptions) (any, error) {
+			return &MLKEMConfig{
+				PublicKeyPath:  settings.GetKeyFilePath("mlkem_public_key"),
+				PrivateKeyPath: settings.GetKeyFilePath("mlkem_private_key"),
+			}, nil
+		},
+		NewService: newServiceFunc(MLKEMService),
+		GenerateKeys: func(Parameters) (map[string][]byte, error) {
+			publicPEM, privatePEM, err := GenerateMLKEMKeys()
+			if err != nil {
+				return nil, err
+			}
+			return map[string][]byte{"mlkem_public_key": publicPEM, "mlkem_private_key": privatePEM}, nil
+		},
+		Fingerprint: func(paths map[string]string, _ PassphraseFunc) ([]byte, error) {
+			service, err := MLKEMService(&MLKEMConfig{
+				PublicKeyPath:  paths["mlkem_public_key"],
+				PrivateKeyPath: paths["mlkem_private_key"],
+			})
+			if err != nil {
+				return nil, err
+			}
+			return service.fingerprint(), nil
+		},
+	})
+}
+
+// ML-KEM-768 + X25519 密钥的 PEM 类型
+const (
+	MLKEMPublicKeyType  = "HYCRYPT MLKEM768 X25519 PUBLIC KEY"
+	MLKEMPrivateKeyType = "HYCRYPT MLKEM768 X25519 PRIVATE KEY"
+)
+
+// 密钥编码长度
+const (
+	// mlkemPublicKeySize 公钥: ML-KEM-768 封装密钥 | X25519 公钥
+	mlkemPublicKeySize = mlkem.EncapsulationKeySize768 + 32
+	// mlkemPrivateKeySize 私钥: ML-KEM-768 种子 | X25519 私钥
+	mlkemPrivateKeySize = mlkem.SeedSize + 32
+)
+
+// mlkemInfo HKDF info，绑定算法与用途
+// 标签中的 aes-256-gcm 是固定的历史写法：派生的 32 字节密钥同样用于 ChaCha20-Poly1305，
+// 实际的数据加密算法记录在头部并计入关联数据，修改标签会使已加密的文件无法解密
+const mlkemInfo = "hycrypt/v3 mlkem768-x25519 aes-256-gcm"
+
+// MLKEMServiceInterface ML-KEM-768 + X25519 混合加密服务
+//
+// 数据密钥同时依赖 ML-KEM 与 X25519 两个共享密钥，
+// 只要其中任一算法未被攻破，加密结果就保持机密。
+type MLKEMServiceInterface struct {
+	*BaseService
+	encapsulationKey *mlkem.EncapsulationKey768
+	decapsulationKey *mlkem.DecapsulationKey768
+	x25519Public     *ecdh.PublicKey
+	x25519Private    *ecdh.PrivateKey
+	config           *MLKEMConfig
+}
+
+func MLKEMService(config *MLKEMConfig) (*MLKEMServiceInterface, error) {
+	service := &MLKEMServiceInterface{
+		BaseService: &BaseService{config: config},
+		config:      config,
+	}
+
+	// 尝试加载私钥（解密需要）
+	service.loadPrivateKey() // 忽略错误，私钥可选
+
+	// 加载公钥，文件缺失时由私钥推导，存在但无法使用时报错
+	if err := service.loadPublicKey(); err != nil {
+		if _, statErr := os.Stat(config.PublicKeyPath); service.decapsulationKey == nil || !os.IsNotExist(statErr) {
+			return nil, err
+		}
+		service.encapsulationKey = service.decapsulationKey.EncapsulationKey()
+		service.x25519Public = service.x25519Private.PublicKey()
+	}
+
+	return service, nil
+}
+
+func (s *MLKEMServiceInterface) EncryptData(ctx context.Context, data io.Reader) (io.Reader, error) {
+	// ML-KEM-768 封装
+	mlkemShared, encapsulation := s.encapsulationKey.Encapsulate()
+
+	// X25519 临时密钥协商
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmMLKEM, err)
+	}
+	x25519Shared, err := ephemeral.ECDH(s.x25519Public)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmMLKEM, err)
+	}
+
+	ephemeralPublic := ephemeral.PublicKey().Bytes()
+	aesKey, err := deriveMLKEMKey(mlkemShared, x25519Shared, encapsulation, ephemeralPublic, s.x25519Public.Bytes())
+	clearBytes(mlkemShared)
+	clearBytes(x25519Shared)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmMLKEM, err)
+	}
+
+	// 派生密钥固定 256 位，适用于所有 AEAD
+	header := format.FromContext(ctx, format.AlgorithmMLKEM)
+	selectAEAD(header, hybridAESKeySize)
+	aead, err := newAEAD(header.AEAD, aesKey)
+	clearBytes(aesKey)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmMLKEM, err)
+	}
+
+	// 构建头部: 封装密文、临时公钥与接收方指纹记录在参数区
+	header.KDF = format.KDFHKDFSHA256
+	header.Set(format.TagEncapsulation, encapsulation)
+	header.Set(format.TagEphemeralKey, ephemeralPublic)
+	header.Set(format.TagKeyFingerprint, mlkemFingerprint(s.encapsulationKey, s.x25519Public))
+	header.SetKeySize(hybridAESKeySize)
+
+	result, err := sealStream(ctx, header, aead, data)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmMLKEM, err)
+	}
+	return result, nil
+}
+
+func (s *MLKEMServiceInterface) DecryptData(ctx context.Context, data io.Reader) (io.Reader, error) {
+	header, body, err := format.Open(data)
+	if err != nil {
+		return nil, errors.InvalidFormat("mlkem encrypted data", err)
+	}
+	if header == nil {
+		return nil, errors.InvalidFormat("mlkem encrypted data", fmt.Errorf("missing hycrypt header"))
+	}
+
+	aead, err := s.openAEAD(header)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := openStream(ctx, header, aead, body)
+	if err != nil {
+		return nil, errors.InvalidFormat("mlkem encrypted data", err)
+	}
+	return plaintext, nil
+}
+
+// openAEAD 校验头部与本地密钥，派生分块流式格式使用的 AEAD
+func (s *MLKEMServiceInterface) openAEAD(header *format.Header) (cipher.AEAD, error) {
+	if s.decapsulationKey == nil {
+		return nil, errors.KeyNotFound("private", s.config.PrivateKeyPath)
+	}
+
+	if header.Algorithm != format.AlgorithmMLKEM {
+		return nil, errors.InvalidFormat("mlkem encrypted data",
+			fmt.Errorf("file was encrypted with %s", header.Algorithm))
+	}
+	if err := checkKeyFingerprint(header, constants.AlgorithmMLKEM, s.fingerprint()); err != nil {
+		return nil, err
+	}
+	if header.KDF != format.KDFHKDFSHA256 || !isSupportedAEAD(header.AEAD) {
+		return nil, errors.InvalidFormat("mlkem encrypted data",
+			fmt.Errorf("unsupported kdf/aead: %d/%d", header.KDF, header.AEAD))
+	}
+
+	encapsulation, ok := header.Get(format.TagEncapsulation)
+	if !ok {
+		return nil, errors.InvalidFormat("mlkem encrypted data", fmt.Errorf("missing encapsulation"))
+	}
+	ephemeralPublic, ok := header.Get(format.TagEphemeralKey)
+	if !ok {
+		return nil, errors.InvalidFormat("mlkem encrypted data", fmt.Errorf("missing ephemeral key"))
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralPublic)
+	if err != nil {
+		return nil, errors.InvalidFormat("mlkem encrypted data", err)
+	}
+
+	mlkemShared, err := s.decapsulationKey.Decapsulate(encapsulation)
+	if err != nil {
+		return nil, errors.InvalidFormat("mlkem encrypted data", err)
+	}
+	x25519Shared, err := s.x25519Private.ECDH(ephemeral)
+	if err != nil {
+		clearBytes(mlkemShared)
+		return nil, errors.DecryptionFailed(constants.AlgorithmMLKEM, err)
+	}
+
+	aesKey, err := deriveMLKEMKey(mlkemShared, x25519Shared, encapsulation, ephemeralPublic, s.x25519Private.PublicKey().Bytes())
+	clearBytes(mlkemShared)
+	clearBytes(x25519Shared)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmMLKEM, err)
+	}
+
+	aead, err := newAEAD(header.AEAD, aesKey)
+	clearBytes(aesKey)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmMLKEM, err)
+	}
+	return aead, nil
+}
+
+// fingerprint 本地密钥指纹，私钥已加载时取私钥对应的公钥，否则取配置的公钥
+func (s *MLKEMServiceInterface) fingerprint() []byte {
+	if s.decapsulationKey != nil {
+		return mlkemFingerprint(s.decapsulationKey.EncapsulationKey(), s.x25519Private.PublicKey())
+	}
+	return mlkemFingerprint(s.encapsulationKey, s.x25519Public)
+}
+
+func (s *MLKEMServiceInterface) ValidateKeys() error {
+	if s.encapsulationKey == nil {
+		return errors.KeyNotFound("public", s.config.PublicKeyPath)
+	}
+	return nil
+}
+
+func (s *MLKEMServiceInterface) loadPublicKey() error {
+	raw, err := readMLKEMPEM(s.config.PublicKeyPath, MLKEMPublicKeyType, mlkemPublicKeySize)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.KeyNotFound("public", s.config.PublicKeyPath)
+		}
+		return errors.InvalidFormat("mlkem public key", err)
+	}
+
+	encapsulationKey, err := mlkem.NewEncapsulationKey768(raw[:mlkem.EncapsulationKeySize768])
+	if err != nil {
+		return errors.InvalidFormat("mlkem public key", err)
+	}
+	x25519Public, err := ecdh.X25519().NewPublicKey(raw[mlkem.EncapsulationKeySize768:])
+	if err != nil {
+		return errors.InvalidFormat("mlkem public key", err)
+	}
+
+	s.encapsulationKey = encapsulationKey
+	s.x25519Public = x25519Public
+	return nil
+}
+
+func (s *MLKEMServiceInterface) loadPrivateKey() error {
+	raw, err := readMLKEMPEM(s.config.PrivateKeyPath, MLKEMPrivateKeyType, mlkemPrivateKeySize)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.KeyNotFound("private", s.config.PrivateKeyPath)
+		}
+		return errors.InvalidFormat("mlkem private key", err)
+	}
+	defer clearBytes(raw)
+
+	decapsulationKey, err := mlkem.NewDecapsulationKey768(raw[:mlkem.SeedSize])
+	if err != nil {
+		return errors.InvalidFormat("mlkem private key", err)
+	}
+	x25519Private, err := ecdh.X25519().NewPrivateKey(raw[mlkem.SeedSize:])
+	if err != nil {
+		return errors.InvalidFormat("mlkem private key", err)
+	}
+
+	s.decapsulationKey = decapsulationKey
+	s.x25519Private = x25519Private
+	return nil
+}
+
+// readMLKEMPEM 读取并校验 PEM 编码的混合密钥
+func readMLKEMPEM(path, blockType string, size int) ([]byte, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(keyData)
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM format")
+	}
+	if block.Type != blockType {
+		return nil, fmt.Errorf("unexpected PEM type %q", block.Type)
+	}
+	if len(block.Bytes) != size {
+		return nil, fmt.Errorf("invalid key length: %d", len(block.Bytes))
+	}
+	return block.Bytes, nil
+}
+
+// deriveMLKEMKey 组合两个共享密钥派生 AES-256 密钥
+// salt 绑定封装密文、临时公钥与接收方公钥
+func deriveMLKEMKey(mlkemShared, x25519Shared, encapsulation, ephemeralPublic, recipientPublic []byte) ([]byte, error) {
+	secret := make([]byte, 0, len(mlkemShared)+len(x25519Shared))
+	secret = append(secret, mlkemShared...)
+	secret = append(secret, x25519Shared...)
+	defer clearBytes(secret)
+
+	salt := make([]byte, 0, len(encapsulation)+len(ephemeralPublic)+len(recipientPublic))
+	salt = append(salt, encapsulation...)
+	salt = append(salt, ephemeralPublic...)
+	salt = append(salt, recipientPublic...)
+
+	return hkdf.Key(sha256.New, secret, salt, mlkemInfo, hybridAESKeySize)
+}
+
+// GenerateMLKEMKeys 生成 ML-KEM-768 + X25519 密钥对，返回 PEM 编码的公钥和私钥
+func GenerateMLKEMKeys() (publicPEM, privatePEM []byte, err error) {
+	decapsulationKey, err := mlkem.GenerateKey768()
+	if err != nil {
+		return nil, nil, err
+	}
+	x25519Private, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	public := append(decapsulationKey.EncapsulationKey().Bytes(), x25519Private.PublicKey().Bytes()...)
+	private := append(decapsulationKey.Bytes(), x25519Private.Bytes()...)
+	defer clearBytes(private)
+
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: MLKEMPublicKeyType, Bytes: public})
+	privatePEM = pem.EncodeToMemory(&pem.Block{Type: MLKEMPrivateKeyType, Bytes: private})
+	return publicPEM, privatePEM, nil
+}
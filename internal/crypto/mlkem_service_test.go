@@ -0,0 +1,75 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"hycrypt/internal/constants"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMLKEMRoundTrip(t *testing.T) {
+	config := writeTestKeys[MLKEMConfig](t, constants.AlgorithmMLKEM)
+
+	// 仅持有公钥即可加密
+	encryptor, err := MLKEMService(&MLKEMConfig{PublicKeyPath: config.PublicKeyPath})
+	if err != nil {
+		t.Fatalf("MLKEMService failed: %v", err)
+	}
+
+	plaintext := bytes.Repeat([]byte("post-quantum "), 10000)
+	encrypted, err := encryptor.EncryptData(context.Background(), bytes.NewReader(plaintext))
+	if err != nil {
+		t.Fatalf("EncryptData failed: %v", err)
+	}
+	sealed, _ := io.ReadAll(encrypted)
+
+	// 仅持有私钥即可解密，公钥由私钥推导
+	decryptor, err := MLKEMService(&MLKEMConfig{PrivateKeyPath: config.PrivateKeyPath})
+	if err != nil {
+		t.Fatalf("MLKEMService failed: %v", err)
+	}
+	decrypted, err := decryptor.DecryptData(context.Background(), bytes.NewReader(sealed))
+	if err != nil {
+		t.Fatalf("DecryptData failed: %v", err)
+	}
+	opened, err := io.ReadAll(decrypted)
+	if err != nil {
+		t.Fatalf("reading plaintext failed: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Error("round trip mismatch")
+	}
+
+	// 其他接收方的私钥无法解密
+	other, err := MLKEMService(writeTestKeys[MLKEMConfig](t, constants.AlgorithmMLKEM))
+	if err != nil {
+		t.Fatalf("MLKEMService failed: %v", err)
+	}
+	decrypted, err = other.DecryptData(context.Background(), bytes.NewReader(sealed))
+	if err == nil {
+		_, err = io.ReadAll(decrypted)
+	}
+	if err == nil {
+		t.Error("expected decryption with another key to fail")
+	}
+}
+
+func TestMLKEMRejectsWrongKeyType(t *testing.T) {
+	dir := t.TempDir()
+	config := writeTestKeys[MLKEMConfig](t, constants.AlgorithmMLKEM)
+
+	// 公钥文件放到私钥位置，类型不匹配应被拒绝
+	if _, err := MLKEMService(&MLKEMConfig{PublicKeyPath: filepath.Join(dir, "missing.pem"), PrivateKeyPath: config.PublicKeyPath}); err == nil {
+		t.Error("expected public key used as private key to be rejected")
+	}
+
+	// 公钥文件存在但无法解析时报错，不由私钥推导
+	corrupt := filepath.Join(dir, "corrupt.pem")
+	os.WriteFile(corrupt, []byte("not a key"), 0644)
+	if _, err := MLKEMService(&MLKEMConfig{PublicKeyPath: corrupt, PrivateKeyPath: config.PrivateKeyPath}); err == nil {
+		t.Error("expected unreadable public key to be rejected")
+	}
+}
@@ -0,0 +1,202 @@
+package crypto
+
+import (
+	"fmt"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+	"math/bits"
+)
+
+// 填充编码: 明文末尾追加 0x80 标记字节，再补零到填充方案给出的长度。
+// 填充位于 AEAD 分块之内，解密时去掉最后一个 0x80 及其后的零字节
+const (
+	paddingMarker = 0x80
+
+	// minBucketSize bucket 方案的最小长度，所有不超过该长度的明文密文长度相同
+	minBucketSize = 1024
+)
+
+// paddedSize 返回长度为 size（含标记字节）的明文按方案填充后的长度
+func paddedSize(padding format.PaddingID, size uint64) (uint64, error) {
+	switch padding {
+	case format.PaddingNone:
+		return size, nil
+	case format.PaddingPadme:
+		return padme(size), nil
+	case format.PaddingBucket:
+		if size <= minBucketSize {
+			return minBucketSize, nil
+		}
+		if size > 1<<63 {
+			return 0, fmt.Errorf("plaintext too large to pad: %d bytes", size)
+		}
+		return 1 << bits.Len64(size-1), nil
+	default:
+		return 0, fmt.Errorf("unsupported padding: %s", padding)
+	}
+}
+
+// padme 实现 PADMÉ（Nikitin 等，PETS 2019）: 长度 L 取整后只保留
+// floor(log2(floor(log2 L)))+1 位有效位，额外开销不超过 12%
+func padme(size uint64) uint64 {
+	if size < 2 {
+		return size
+	}
+	e := uint(bits.Len64(size) - 1)
+	s := uint(bits.Len(e))
+	mask := uint64(1)<<(e-s) - 1
+	return (size + mask) &^ mask
+}
+
+// padReader 在明文末尾追加填充
+type padReader struct {
+	src     io.Reader
+	padding format.PaddingID
+	size    uint64
+	marker  bool
+	zeros   uint64
+	done    bool
+}
+
+// newPadReader 创建填充读取器，读到 src 结尾时按方案追加标记字节和零字节
+func newPadReader(src io.Reader, padding format.PaddingID) *padReader {
+	return &padReader{src: src, padding: padding}
+}
+
+func (p *padReader) Read(buf []byte) (int, error) {
+	if !p.done {
+		n, err := p.src.Read(buf)
+		p.size += uint64(n)
+		if err != io.EOF {
+			return n, err
+		}
+
+		// 明文读完后计算需要补齐的零字节数
+		padded, err := paddedSize(p.padding, p.size+1)
+		if err != nil {
+			return n, err
+		}
+		p.done = true
+		p.marker = true
+		p.zeros = padded - p.size - 1
+		if n > 0 {
+			return n, nil
+		}
+	}
+
+	n := 0
+	if p.marker && len(buf) > 0 {
+		buf[0] = paddingMarker
+		p.marker = false
+		n = 1
+	}
+	fill := uint64(len(buf) - n)
+	if fill > p.zeros {
+		fill = p.zeros
+	}
+	clear(buf[n : n+int(fill)])
+	p.zeros -= fill
+	n += int(fill)
+
+	if n == 0 && len(buf) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// unpadReader 去掉明文末尾的填充
+//
+// 末尾的 0x80 标记及其后的零字节只计数不缓存，确认后面还有数据时再原样输出，
+// 因此内存占用与填充长度无关。
+type unpadReader struct {
+	src     io.Reader
+	buf     []byte
+	pending []byte
+
+	// heldMarker/heldZeros 可能属于填充的标记字节与零字节
+	heldMarker bool
+	heldZeros  uint64
+
+	// flushMarker/flushZeros 确认属于明文、等待输出的标记字节与零字节
+	flushMarker bool
+	flushZeros  uint64
+
+	err error
+}
+
+// newUnpadReader 创建去填充读取器，src 必须在完成认证后才返回 io.EOF
+func newUnpadReader(src io.Reader) *unpadReader {
+	return &unpadReader{src: src, buf: make([]byte, DefaultChunkSize)}
+}
+
+func (u *unpadReader) Read(p []byte) (int, error) {
+	for {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		if u.flushMarker {
+			p[0] = paddingMarker
+			u.flushMarker = false
+			return 1, nil
+		}
+		if u.flushZeros > 0 {
+			n := uint64(len(p))
+			if n > u.flushZeros {
+				n = u.flushZeros
+			}
+			clear(p[:n])
+			u.flushZeros -= n
+			return int(n), nil
+		}
+		if len(u.pending) > 0 {
+			n := copy(p, u.pending)
+			u.pending = u.pending[n:]
+			return n, nil
+		}
+		if u.err != nil {
+			return 0, u.err
+		}
+
+		n, err := u.src.Read(u.buf)
+		u.consume(u.buf[:n])
+		switch {
+		case err == io.EOF && !u.heldMarker:
+			u.err = errors.InvalidFormat("encrypted stream", fmt.Errorf("missing padding marker"))
+		case err != nil:
+			u.err = err
+		}
+	}
+}
+
+// consume 处理一段明文: 最后一个非零字节之前的数据确定属于明文，
+// 最后一个非零字节若为标记字节则与其后的零字节一起暂存
+func (u *unpadReader) consume(b []byte) {
+	last := len(b) - 1
+	for last >= 0 && b[last] == 0 {
+		last--
+	}
+	if last < 0 {
+		if u.heldMarker {
+			u.heldZeros += uint64(len(b))
+		} else {
+			u.pending = b
+		}
+		return
+	}
+
+	// 后面还有非零数据，暂存的字节属于明文
+	if u.heldMarker {
+		u.flushMarker = true
+		u.flushZeros = u.heldZeros
+	}
+	if b[last] == paddingMarker {
+		u.pending = b[:last]
+		u.heldMarker = true
+		u.heldZeros = uint64(len(b) - last - 1)
+	} else {
+		u.pending = b
+		u.heldMarker = false
+		u.heldZeros = 0
+	}
+}
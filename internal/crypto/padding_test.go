@@ -0,0 +1,124 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"hycrypt/internal/format"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestPaddedSize(t *testing.T) {
+	testCases := []struct {
+		padding format.PaddingID
+		size    uint64
+		want    uint64
+	}{
+		{format.PaddingNone, 37, 37},
+		{format.PaddingPadme, 1, 1},
+		{format.PaddingPadme, 100, 104},
+		{format.PaddingPadme, 1000, 1024},
+		{format.PaddingPadme, 1 << 20, 1 << 20},
+		{format.PaddingPadme, 1<<20 + 1, 1<<20 + 1<<15},
+		{format.PaddingBucket, 1, 1024},
+		{format.PaddingBucket, 1024, 1024},
+		{format.PaddingBucket, 1025, 2048},
+		{format.PaddingBucket, 3 << 20, 4 << 20},
+	}
+
+	for _, tc := range testCases {
+		got, err := paddedSize(tc.padding, tc.size)
+		if err != nil || got != tc.want {
+			t.Errorf("paddedSize(%s, %d) = %d, %v; want %d", tc.padding, tc.size, got, err, tc.want)
+		}
+	}
+	if _, err := paddedSize(format.PaddingID(99), 1); err == nil {
+		t.Error("expected unknown padding to fail")
+	}
+}
+
+func TestPaddingRoundTrip(t *testing.T) {
+	random := make([]byte, 3*DefaultChunkSize)
+	rand.Read(random)
+
+	testCases := []struct {
+		name      string
+		plaintext []byte
+	}{
+		{"empty", nil},
+		{"marker only", []byte{paddingMarker}},
+		{"ends with marker and zeros", []byte{1, 2, paddingMarker, 0, 0}},
+		{"zeros only", make([]byte, 5000)},
+		{"zero run across reads", append(append([]byte{paddingMarker}, make([]byte, 2*DefaultChunkSize)...), 7)},
+		{"random", random},
+	}
+
+	for _, padding := range []format.PaddingID{format.PaddingPadme, format.PaddingBucket} {
+		for _, tc := range testCases {
+			t.Run(padding.String()+"/"+tc.name, func(t *testing.T) {
+				padded, err := io.ReadAll(iotest.HalfReader(newPadReader(bytes.NewReader(tc.plaintext), padding)))
+				if err != nil {
+					t.Fatalf("padding failed: %v", err)
+				}
+				want, _ := paddedSize(padding, uint64(len(tc.plaintext))+1)
+				if uint64(len(padded)) != want {
+					t.Errorf("padded length = %d, want %d", len(padded), want)
+				}
+
+				opened, err := io.ReadAll(newUnpadReader(iotest.HalfReader(bytes.NewReader(padded))))
+				if err != nil {
+					t.Fatalf("unpadding failed: %v", err)
+				}
+				if !bytes.Equal(opened, tc.plaintext) {
+					t.Error("round trip mismatch")
+				}
+			})
+		}
+	}
+
+	if _, err := io.ReadAll(newUnpadReader(bytes.NewReader([]byte{1, 2, 0, 0}))); err == nil {
+		t.Error("expected missing padding marker to fail")
+	}
+}
+
+func TestKMACPaddingHidesLength(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	service, err := KMACService(&KMACConfig{Key: key, KeySize: 32, AESKeySize: 32})
+	if err != nil {
+		t.Fatalf("KMACService failed: %v", err)
+	}
+
+	template := &format.Header{}
+	template.SetPadding(format.PaddingBucket)
+	ctx := format.NewContext(context.Background(), template)
+
+	// 不同长度的短小秘密加密后长度相同，解密时自动去除填充
+	var sizes []int
+	for _, secret := range []string{"abandon ability", "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong"} {
+		encrypted, err := service.EncryptData(ctx, bytes.NewReader([]byte(secret)))
+		if err != nil {
+			t.Fatalf("EncryptData failed: %v", err)
+		}
+		sealed, _ := io.ReadAll(encrypted)
+		sizes = append(sizes, len(sealed))
+
+		header, _, _ := format.Parse(sealed)
+		if header.Padding() != format.PaddingBucket {
+			t.Errorf("header padding = %s", header.Padding())
+		}
+
+		decrypted, err := service.DecryptData(context.Background(), bytes.NewReader(sealed))
+		if err != nil {
+			t.Fatalf("DecryptData failed: %v", err)
+		}
+		if opened, _ := io.ReadAll(decrypted); string(opened) != secret {
+			t.Errorf("round trip mismatch: %q", opened)
+		}
+	}
+	if sizes[0] != sizes[1] {
+		t.Errorf("ciphertext sizes differ: %v", sizes)
+	}
+}
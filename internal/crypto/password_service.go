@@ -0,0 +1,190 @@
+package crypto
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+)
+
+func init() {
+	Register(&Algorithm{
+		Name:          constants.AlgorithmPassword,
+		HeaderID:      format.AlgorithmPassword,
+		DisplayName:   "口令 (Argon2id)",
+		Icon:          "🔏",
+		Order:         30,
+		NeedsPassword: true,
+		Configure: func(settings Settings, options ConfigureOptions) (any, error) {
+			if len(options.Password) == 0 {
+				return nil, nil
+			}
+			params := settings.AlgorithmParameters()
+			return &PasswordConfig{
+				Password:   options.Password,
+				AESKeySize: params.AESKeySize,
+				Time:       params.Argon2Time,
+				MemoryKiB:  params.Argon2MemoryKiB,
+				Threads:    params.Argon2Threads,
+			}, nil
+		},
+		NewService: newServiceFunc(PasswordService),
+		Validate: func(params Parameters) error {
+			return validateArgon2Params(params.Argon2Time, params.Argon2MemoryKiB, params.Argon2Threads)
+		},
+	})
+}
+
+// Argon2id 参数上限，防止恶意头部导致过量内存或CPU消耗
+const (
+	maxArgon2Time      = 64
+	maxArgon2MemoryKiB = 4 * 1024 * 1024 // 4 GiB
+)
+
+// PasswordServiceInterface 口令加密服务
+type PasswordServiceInterface struct {
+	*BaseService
+	config *PasswordConfig
+}
+
+func PasswordService(config *PasswordConfig) (*PasswordServiceInterface, error) {
+	if len(config.Password) == 0 {
+		return nil, errors.InvalidConfig("password cannot be empty", nil)
+	}
+	if err := validateArgon2Params(config.Time, config.MemoryKiB, config.Threads); err != nil {
+		return nil, errors.InvalidConfig("invalid argon2id parameters", err)
+	}
+
+	return &PasswordServiceInterface{
+		BaseService: &BaseService{config: config},
+		config:      config,
+	}, nil
+}
+
+func (p *PasswordServiceInterface) EncryptData(ctx context.Context, data io.Reader) (io.Reader, error) {
+	// 生成随机salt
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmPassword, err)
+	}
+
+	// 构建头部: salt 和 Argon2id 参数记录在参数区，解密时无需额外配置
+	header := format.FromContext(ctx, format.AlgorithmPassword)
+	header.KDF = format.KDFArgon2id
+	keySize := selectAEAD(header, p.config.AESKeySize)
+	header.Set(format.TagSalt, salt)
+	header.SetArgon2Params(p.config.Time, p.config.MemoryKiB, p.config.Threads)
+	header.SetKeySize(keySize)
+
+	aesKey := p.deriveKey(salt, p.config.Time, p.config.MemoryKiB, p.config.Threads, keySize)
+	aead, err := newAEAD(header.AEAD, aesKey)
+	clearBytes(aesKey)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmPassword, err)
+	}
+
+	result, err := sealStream(ctx, header, aead, data)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmPassword, err)
+	}
+	return result, nil
+}
+
+func (p *PasswordServiceInterface) DecryptData(ctx context.Context, data io.Reader) (io.Reader, error) {
+	header, body, err := format.Open(data)
+	if err != nil {
+		return nil, errors.InvalidFormat("password encrypted data", err)
+	}
+	if header == nil {
+		return nil, errors.InvalidFormat("password encrypted data", fmt.Errorf("missing hycrypt header"))
+	}
+
+	aead, err := p.openAEAD(header)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := openStream(ctx, header, aead, body)
+	if err != nil {
+		return nil, errors.InvalidFormat("password encrypted data", err)
+	}
+	return plaintext, nil
+}
+
+// openAEAD 校验头部与本地密钥，派生分块流式格式使用的 AEAD
+func (p *PasswordServiceInterface) openAEAD(header *format.Header) (cipher.AEAD, error) {
+	if header.Algorithm != format.AlgorithmPassword {
+		return nil, errors.InvalidFormat("password encrypted data",
+			fmt.Errorf("file was encrypted with %s", header.Algorithm))
+	}
+	if header.KDF != format.KDFArgon2id || !isSupportedAEAD(header.AEAD) {
+		return nil, errors.InvalidFormat("password encrypted data",
+			fmt.Errorf("unsupported kdf/aead: %d/%d", header.KDF, header.AEAD))
+	}
+
+	salt, ok := header.Get(format.TagSalt)
+	if !ok || len(salt) == 0 {
+		return nil, errors.InvalidFormat("password encrypted data", fmt.Errorf("missing salt"))
+	}
+
+	time, memory, threads, ok := header.Argon2Params()
+	if !ok {
+		return nil, errors.InvalidFormat("password encrypted data", fmt.Errorf("missing argon2id parameters"))
+	}
+	if err := validateArgon2Params(time, memory, threads); err != nil {
+		return nil, errors.InvalidFormat("password encrypted data", err)
+	}
+
+	keySize := header.KeySize()
+	if keySize == 0 {
+		keySize = p.config.AESKeySize
+	}
+
+	aesKey := p.deriveKey(salt, time, memory, threads, keySize)
+	aead, err := newAEAD(header.AEAD, aesKey)
+	clearBytes(aesKey)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmPassword, err)
+	}
+	return aead, nil
+}
+
+func (p *PasswordServiceInterface) ValidateKeys() error {
+	if len(p.config.Password) == 0 {
+		return errors.KeyNotFound(constants.AlgorithmPassword, "prompt")
+	}
+	return nil
+}
+
+// deriveKey 使用 Argon2id 从口令派生AES密钥
+func (p *PasswordServiceInterface) deriveKey(salt []byte, time, memory uint32, threads uint8, keyLength int) []byte {
+	return argon2.IDKey(p.config.Password, salt, time, memory, threads, uint32(keyLength))
+}
+
+// validateArgon2Params 检查 Argon2id 参数是否在合理范围内
+func validateArgon2Params(time, memory uint32, threads uint8) error {
+	if time == 0 || time > maxArgon2Time {
+		return fmt.Errorf("argon2id time must be between 1 and %d, got %d", maxArgon2Time, time)
+	}
+	if threads == 0 {
+		return fmt.Errorf("argon2id parallelism must be at least 1")
+	}
+	if memory < 8*uint32(threads) || memory > maxArgon2MemoryKiB {
+		return fmt.Errorf("argon2id memory must be between %d and %d KiB, got %d",
+			8*uint32(threads), maxArgon2MemoryKiB, memory)
+	}
+	return nil
+}
+
+// clearBytes 清除内存中的敏感数据
+func clearBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,83 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+func testPasswordService(t *testing.T, password string) *PasswordServiceInterface {
+	t.Helper()
+
+	service, err := PasswordService(&PasswordConfig{
+		Password:   []byte(password),
+		AESKeySize: 32,
+		Time:       1,
+		MemoryKiB:  64,
+		Threads:    1,
+	})
+	if err != nil {
+		t.Fatalf("PasswordService failed: %v", err)
+	}
+	return service
+}
+
+func TestPasswordRoundTrip(t *testing.T) {
+	plaintext := []byte("seed words go here")
+
+	encrypted, err := testPasswordService(t, "correct horse").EncryptData(context.Background(), bytes.NewReader(plaintext))
+	if err != nil {
+		t.Fatalf("EncryptData failed: %v", err)
+	}
+	sealed, _ := io.ReadAll(encrypted)
+
+	// 解密端使用不同的参数配置，应以头部记录的参数为准
+	decryptor, err := PasswordService(&PasswordConfig{
+		Password:   []byte("correct horse"),
+		AESKeySize: 16,
+		Time:       2,
+		MemoryKiB:  128,
+		Threads:    2,
+	})
+	if err != nil {
+		t.Fatalf("PasswordService failed: %v", err)
+	}
+	decrypted, err := decryptor.DecryptData(context.Background(), bytes.NewReader(sealed))
+	if err != nil {
+		t.Fatalf("DecryptData failed: %v", err)
+	}
+	opened, err := io.ReadAll(decrypted)
+	if err != nil {
+		t.Fatalf("reading plaintext failed: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("round trip mismatch: got %q", opened)
+	}
+
+	decrypted, err = testPasswordService(t, "wrong horse").DecryptData(context.Background(), bytes.NewReader(sealed))
+	if err == nil {
+		_, err = io.ReadAll(decrypted)
+	}
+	if err == nil {
+		t.Error("expected wrong password to fail")
+	}
+}
+
+func TestPasswordServiceRejectsInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config PasswordConfig
+	}{
+		{"empty password", PasswordConfig{Time: 1, MemoryKiB: 64, Threads: 1}},
+		{"zero time", PasswordConfig{Password: []byte("x"), MemoryKiB: 64, Threads: 1}},
+		{"zero threads", PasswordConfig{Password: []byte("x"), Time: 1, MemoryKiB: 64}},
+		{"memory too small", PasswordConfig{Password: []byte("x"), Time: 1, MemoryKiB: 8, Threads: 4}},
+	}
+
+	for _, tc := range testCases {
+		if _, err := PasswordService(&tc.config); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+	}
+}
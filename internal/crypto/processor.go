@@ -1,24 +1,61 @@
 package crypto
 
 import (
+	"archive/zip"
+	"bufio"
 	"context"
 	"fmt"
-	"hycrypt/internal/constants"
+	"hycrypt/internal/age"
 	"hycrypt/internal/datasink"
 	"hycrypt/internal/datasource"
 	"hycrypt/internal/domain"
 	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
 	"hycrypt/internal/naming"
 	"hycrypt/internal/utils"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
 // ProcessorConfig 处理器配置
 type ProcessorConfig struct {
-	RSAConfig  *RSAConfig
-	KMACConfig *KMACConfig
+	// Algorithms 各算法 Configure 返回的配置，按算法名称索引
+	Algorithms map[string]any
+
+	// AEAD 加密时使用的数据加密算法名称，为空时使用 AES-GCM
+	AEAD string
+
+	// Padding 加密时使用的长度隐藏填充方案名称，为空时不填充
+	Padding string
+
+	// Compression 加密前的压缩编码名称，为空时不压缩；启用填充时不压缩
+	Compression string
+	// CompressionLevel 压缩级别，0 为编码默认级别
+	CompressionLevel int
+
+	// Workers 分块并发加解密的块数，0 为 GOMAXPROCS；输出与并发数无关
+	Workers int
+
+	// SigningConfig Ed25519 签名与验签配置，为空时不签名也不验签
+	SigningConfig *SigningConfig
+
+	// AgeConfig age 格式的额外接收方与身份，为空时只使用本地密钥
+	AgeConfig *AgeConfig
+}
+
+// SigningConfig Ed25519 签名配置
+type SigningConfig struct {
+	// PrivateKeyPath 签名私钥文件，其公钥自动受信任
+	PrivateKeyPath string
+	// Sign 加密时对明文摘要和元数据签名
+	Sign bool
+	// TrustedKeyPaths 受信任签名者公钥文件，每行一个
+	TrustedKeyPaths []string
+	// RequireSignature 解密时拒绝未签名的文件
+	RequireSignature bool
 }
 
 // RSAConfig RSA配置
@@ -27,6 +64,12 @@ type RSAConfig struct {
 	PrivateKeyPath string
 	KeySize        int
 	AESKeySize     int
+
+	// RecipientPaths 额外接收方的 RSA 公钥文件（PEM 或 ssh-rsa 公钥行），数据密钥为每个接收方各封装一份
+	RecipientPaths []string
+
+	// Passphrase 私钥受口令保护时调用，为空时无法加载受保护的私钥
+	Passphrase PassphraseFunc
 }
 
 // KMACConfig KMAC配置
@@ -36,66 +79,147 @@ type KMACConfig struct {
 	AESKeySize int
 }
 
+// PasswordConfig 口令加密配置
+type PasswordConfig struct {
+	Password   []byte
+	AESKeySize int
+	Time       uint32 // Argon2id 迭代次数
+	MemoryKiB  uint32 // Argon2id 内存开销（KiB）
+	Threads    uint8  // Argon2id 并行度
+}
+
+// X25519Config X25519 混合加密配置
+// 密钥文件也可以是 OpenSSH Ed25519 密钥（ssh-ed25519 公钥行与 OpenSSH 私钥），加载时映射为 X25519 密钥
+type X25519Config struct {
+	PublicKeyPath  string
+	PrivateKeyPath string
+
+	// Passphrase OpenSSH 私钥受口令保护时调用
+	Passphrase PassphraseFunc
+}
+
+// MLKEMConfig ML-KEM-768 + X25519 混合加密配置
+type MLKEMConfig struct {
+	PublicKeyPath  string
+	PrivateKeyPath string
+}
+
 // UnifiedProcessor 统一加密处理器
 type UnifiedProcessor struct {
-	rsaService  *RSAServiceInterface
-	kmacService *KMACServiceInterface
-	config      *ProcessorConfig
-	strategy    domain.FileNameStrategy
+	services map[string]CryptoService
+	signer   *signer
+	config   *ProcessorConfig
+	strategy domain.FileNameStrategy
 }
 
 func NewUnifiedProcessor(config *ProcessorConfig) (*UnifiedProcessor, error) {
+	if config.AEAD != "" && format.AEADByName(config.AEAD) == format.AEADNone {
+		return nil, errors.InvalidConfig(fmt.Sprintf("unsupported aead: %s", config.AEAD), nil)
+	}
+	if _, ok := format.PaddingByName(config.Padding); !ok {
+		return nil, errors.InvalidConfig(fmt.Sprintf("unsupported padding: %s", config.Padding), nil)
+	}
+	if err := ValidateCompression(config.Compression, config.CompressionLevel); err != nil {
+		return nil, errors.InvalidConfig(err.Error(), nil)
+	}
+	if config.Workers < 0 || config.Workers > MaxWorkers {
+		return nil, errors.InvalidConfig(fmt.Sprintf("workers must be between 0 and %d, got %d", MaxWorkers, config.Workers), nil)
+	}
+
+	// 初始化已配置的算法服务
+	services, err := newServices(config)
+	if err != nil {
+		return nil, err
+	}
+
 	processor := &UnifiedProcessor{
+		services: services,
 		config:   config,
 		strategy: naming.DefaultStrategy(".hycrypt"),
 	}
 
-	// 初始化 RSA 服务（如果配置存在）
-	if config.RSAConfig != nil {
-		rsaService, err := RSAService(config.RSAConfig)
+	// 初始化签名（如果配置存在）
+	if config.SigningConfig != nil {
+		signer, err := newSigner(config.SigningConfig)
 		if err != nil {
-			return nil, errors.InvalidConfig("failed to initialize RSA service", err)
+			return nil, errors.InvalidConfig("failed to initialize signing", err)
 		}
-		processor.rsaService = rsaService
-	}
-
-	// 初始化 KMAC 服务（如果配置存在）
-	if config.KMACConfig != nil {
-		kmacService, err := KMACService(config.KMACConfig)
-		if err != nil {
-			return nil, errors.InvalidConfig("failed to initialize KMAC service", err)
-		}
-		processor.kmacService = kmacService
+		processor.signer = signer
 	}
 
 	return processor, nil
 }
 
 func (p *UnifiedProcessor) Encrypt(ctx context.Context, source domain.DataSource, sink domain.DataSink, opts domain.CryptoOptions) (*domain.CryptoResult, error) {
+	if opts.OutputFormat == domain.OutputAge {
+		return p.encryptAge(ctx, source, sink, opts)
+	}
+	if opts.OutputFormat == domain.OutputJWE || opts.OutputFormat == domain.OutputJWEJSON {
+		return p.encryptJWE(ctx, source, sink, opts)
+	}
+
 	startTime := time.Now()
 
 	// 选择加密服务
 	cryptoService, err := p.getCryptoService(opts.Method)
 	if err != nil {
-		return nil, err
+		return nil, discardOutput(sink, err)
 	}
 
 	// 读取数据
 	reader, err := source.Read(ctx)
 	if err != nil {
-		return nil, errors.EncryptionFailed(opts.Method, err)
+		return nil, discardOutput(sink, errors.EncryptionFailed(opts.Method, err))
 	}
 	defer reader.Close()
 
+	// 头部模板: 记录载荷类型和原始文件名，由加密服务写入文件头并作为关联数据认证
+	template := &format.Header{AEAD: format.AEADByName(p.config.AEAD)}
+	if source.Type() == "directory" {
+		template.Flags |= format.FlagDirectory
+	}
+	template.Set(format.TagFileName, []byte(filepath.Base(source.Name())))
+	padding, _ := format.PaddingByName(p.config.Padding)
+	template.SetPadding(padding)
+
+	// 压缩: 已压缩的数据跳过；启用压缩时目录压缩包的条目只存储，由外层编码统一压缩
+	var plaintext io.Reader = reader
+	compression := p.compression()
+	if compression != format.CompressionNone {
+		buffered := bufio.NewReader(reader)
+		if prefix, _ := buffered.Peek(compressionSniffSize); isCompressedData(prefix) {
+			compression = format.CompressionNone
+		}
+		plaintext = buffered
+	}
+	template.SetCompression(compression)
+
+	// 签名: 头部记录签名者，明文末尾追加签名后一并加密，签名覆盖最终的认证头部
+	var signing *signingReader
+	if p.signer != nil && p.signer.config.Sign {
+		p.signer.prepare(template)
+		signing = p.signer.signReader(plaintext)
+		plaintext = signing
+	}
+	if compression != format.CompressionNone {
+		if plaintext, err = newCompressReader(plaintext, compression, p.config.CompressionLevel); err != nil {
+			return nil, discardOutput(sink, errors.EncryptionFailed(opts.Method, err))
+		}
+	}
+	ctx = WithWorkers(format.NewContext(ctx, template), p.config.Workers)
+	if signing != nil {
+		ctx = withSealedHeader(ctx, signing.bind)
+	}
+
 	// 执行加密
-	result, err := cryptoService.EncryptData(ctx, reader)
+	result, err := cryptoService.EncryptData(ctx, plaintext)
 	if err != nil {
-		return nil, errors.EncryptionFailed(opts.Method, err)
+		return nil, discardOutput(sink, errors.EncryptionFailed(opts.Method, err))
 	}
 
-	// 写入结果
+	// 写入结果: 加密中途失败时删除不完整的密文
 	if err := sink.Write(ctx, result); err != nil {
-		return nil, errors.EncryptionFailed(opts.Method, err)
+		return nil, discardOutput(sink, errors.EncryptionFailed(opts.Method, err))
 	}
 
 	return &domain.CryptoResult{
@@ -104,51 +228,106 @@ func (p *UnifiedProcessor) Encrypt(ctx context.Context, source domain.DataSource
 		ProcessedSize: source.Size(),
 		Method:        opts.Method,
 		ProcessTime:   time.Since(startTime).Milliseconds(),
+		Compression:   compressionName(compression),
 	}, nil
 }
 
 func (p *UnifiedProcessor) Decrypt(ctx context.Context, source domain.DataSource, sink domain.DataSink, opts domain.CryptoOptions) (*domain.CryptoResult, error) {
+	if source.Type() == "age" {
+		return p.decryptAge(ctx, source, sink, opts)
+	}
+	if opts.InputFormat == domain.InputJWE {
+		return p.decryptJWE(ctx, source, sink, opts)
+	}
+
 	startTime := time.Now()
 
-	// 自动检测加密方法（如果未指定）
+	// 读取数据
+	reader, err := source.Read(ctx)
+	if err != nil {
+		return nil, discardOutput(sink, errors.DecryptionFailed(opts.Method, err))
+	}
+	defer reader.Close()
+
+	// 优先读取文件头部，旧格式文件回退到文件名检测
+	header, payload, err := format.Sniff(reader)
+	if err != nil {
+		return nil, discardOutput(sink, errors.InvalidFormat("hycrypt header", err))
+	}
+
 	method := opts.Method
-	if method == "" {
+	if header != nil {
+		method = header.Algorithm.String()
+	} else if method == "" {
 		method = p.detectEncryptionMethod(source.Name())
-		if method == "" {
-			return nil, errors.DecryptionFailed("unknown", fmt.Errorf("cannot detect encryption method"))
-		}
+	}
+	if method == "" {
+		return nil, discardOutput(sink, errors.DecryptionFailed("unknown", fmt.Errorf("cannot detect encryption method")))
 	}
 
 	// 选择解密服务
 	cryptoService, err := p.getCryptoService(method)
 	if err != nil {
-		return nil, err
+		return nil, discardOutput(sink, err)
 	}
 
-	// 读取数据
-	reader, err := source.Read(ctx)
-	if err != nil {
-		return nil, errors.DecryptionFailed(method, err)
+	signed := header != nil && header.HasFlag(format.FlagSigned)
+	if !signed && p.signer != nil && p.signer.config.RequireSignature {
+		return nil, discardOutput(sink, errors.SignatureInvalid("file is not signed", nil))
 	}
-	defer reader.Close()
 
 	// 执行解密
-	result, err := cryptoService.DecryptData(ctx, reader)
+	result, err := cryptoService.DecryptData(WithWorkers(ctx, p.config.Workers), payload)
 	if err != nil {
-		return nil, errors.DecryptionFailed(method, err)
+		// 密钥不符时保留原错误码，提示文件对应的密钥
+		if cryptoErr, ok := err.(*errors.CryptoErrorInterface); ok && cryptoErr.Code == errors.ErrWrongKey {
+			return nil, discardOutput(sink, cryptoErr)
+		}
+		return nil, discardOutput(sink, errors.DecryptionFailed(method, err))
+	}
+
+	// 解压: 还原签名时的明文
+	var compression format.CompressionID
+	if header != nil {
+		compression = header.Compression()
+		if result, err = decompress(header, result); err != nil {
+			if cryptoErr, ok := err.(*errors.CryptoErrorInterface); ok {
+				return nil, discardOutput(sink, cryptoErr)
+			}
+			return nil, discardOutput(sink, errors.DecryptionFailed(method, err))
+		}
+	}
+
+	// 验签: 签名者必须受信任，签名在读取结束时校验
+	var signedBy string
+	if signed {
+		if p.signer == nil {
+			return nil, discardOutput(sink, errors.SignatureInvalid("no trusted signers configured", nil))
+		}
+		result, signedBy, err = p.signer.verify(header, result)
+		if err != nil {
+			return nil, discardOutput(sink, err)
+		}
 	}
 
-	// 写入结果
+	// 写入结果: 认证或验签失败时保留原错误码
 	if err := sink.Write(ctx, result); err != nil {
-		return nil, errors.DecryptionFailed(method, err)
+		if cryptoErr, ok := err.(*errors.CryptoErrorInterface); ok {
+			return nil, discardOutput(sink, cryptoErr)
+		}
+		return nil, discardOutput(sink, errors.DecryptionFailed(method, err))
 	}
 
 	// 检查是否为目录压缩包，如果是则自动解压
-	encryptedFileName := filepath.Base(source.Name())
-	_, _, _, isDirectory := p.strategy.ParseEncryptedName(encryptedFileName)
+	var isDirectory bool
+	if header != nil {
+		isDirectory = header.IsDirectory()
+	} else {
+		_, _, _, isDirectory = p.strategy.ParseEncryptedName(filepath.Base(source.Name()))
+	}
 
 	outputPath := sink.Path()
-	if isDirectory {
+	if _, toFile := sink.(*datasink.FileSinkInterface); isDirectory && toFile {
 		// 解压zip文件到目录
 		outputPath, err = p.handleDirectoryDecryption(sink.Path())
 		if err != nil {
@@ -162,31 +341,78 @@ func (p *UnifiedProcessor) Decrypt(ctx context.Context, source domain.DataSource
 		ProcessedSize: source.Size(),
 		Method:        method,
 		ProcessTime:   time.Since(startTime).Milliseconds(),
+		Signer:        signedBy,
+		Compression:   compressionName(compression),
 	}, nil
 }
 
+// compression 返回加密时使用的压缩编码，启用长度隐藏填充时压缩率会泄露内容，不压缩
+func (p *UnifiedProcessor) compression() format.CompressionID {
+	if padding, _ := format.PaddingByName(p.config.Padding); padding != format.PaddingNone {
+		return format.CompressionNone
+	}
+	compression, _ := format.CompressionByName(p.config.Compression)
+	return compression
+}
+
+// archiveMethod 返回目录压缩包条目的压缩方式，输出 hycrypt 格式且启用压缩时条目只存储不压缩
+func (p *UnifiedProcessor) archiveMethod(opts domain.CryptoOptions) uint16 {
+	if (opts.OutputFormat == domain.OutputFile || opts.OutputFormat == domain.OutputHex) && p.compression() != format.CompressionNone {
+		return zip.Store
+	}
+	return zip.Deflate
+}
+
+// decompress 按头部记录的压缩编码解压明文
+func decompress(header *format.Header, plaintext io.Reader) (io.Reader, error) {
+	value, ok := header.Get(format.TagCompression)
+	if !ok {
+		return plaintext, nil
+	}
+	if len(value) != 1 {
+		return nil, errors.InvalidFormat("hycrypt header", fmt.Errorf("invalid compression field: %x", value))
+	}
+	if header.Compression() == format.CompressionNone {
+		return plaintext, nil
+	}
+	return newDecompressReader(plaintext, header.Compression())
+}
+
+// compressionName 返回结果中显示的压缩编码，不压缩时为空
+func compressionName(compression format.CompressionID) string {
+	if compression == format.CompressionNone {
+		return ""
+	}
+	return compression.String()
+}
+
+// discardOutput 处理失败时关闭并删除输出文件，不保留未经验证的明文或不完整的密文
+func discardOutput(sink domain.DataSink, err error) error {
+	switch sink.(type) {
+	case *datasink.FileSinkInterface, *datasink.AgeSinkInterface:
+		sink.Close()
+		os.Remove(sink.Path())
+	}
+	return err
+}
+
 func (p *UnifiedProcessor) ValidateConfig() error {
-	if p.rsaService == nil && p.kmacService == nil {
+	if len(p.services) == 0 {
 		return errors.InvalidConfig("no crypto service available", nil)
 	}
 	return nil
 }
 
 func (p *UnifiedProcessor) getCryptoService(method string) (CryptoService, error) {
-	switch method {
-	case constants.AlgorithmRSA:
-		if p.rsaService == nil {
-			return nil, errors.InvalidConfig("RSA service not available", nil)
-		}
-		return p.rsaService, nil
-	case constants.AlgorithmKMAC:
-		if p.kmacService == nil {
-			return nil, errors.InvalidConfig("KMAC service not available", nil)
-		}
-		return p.kmacService, nil
-	default:
+	algorithm, ok := LookupAlgorithm(method)
+	if !ok {
 		return nil, errors.InvalidConfig(fmt.Sprintf("unsupported method: %s", method), nil)
 	}
+	service, ok := p.services[method]
+	if !ok {
+		return nil, errors.InvalidConfig(fmt.Sprintf("%s service not available", algorithm.DisplayName), nil)
+	}
+	return service, nil
 }
 
 func (p *UnifiedProcessor) detectEncryptionMethod(fileName string) string {
@@ -204,8 +430,14 @@ func (p *UnifiedProcessor) ProcessFile(ctx context.Context, inputPath, outputDir
 	var source domain.DataSource
 	var err error
 
+	ageInput := !isEncrypt && isAgeFile(inputPath)
 	if isEncrypt {
-		source, err = datasource.CreateSource(inputPath, opts.InputFormat)
+		source, err = datasource.CreateSource(inputPath, opts.InputFormat, p.archiveMethod(opts))
+	} else if ageInput {
+		var identities []age.Identity
+		if identities, err = p.ageIdentities(); err == nil {
+			source, err = datasource.AgeSource(inputPath, identities)
+		}
 	} else {
 		source, err = datasource.FileSource(inputPath)
 	}
@@ -216,7 +448,19 @@ func (p *UnifiedProcessor) ProcessFile(ctx context.Context, inputPath, outputDir
 
 	// 生成输出文件名
 	var fileName string
-	if isEncrypt {
+	if isEncrypt && opts.OutputFormat == domain.OutputAge {
+		// age 文件沿用 age 工具的命名习惯，目录以 zip 形式加密
+		fileName = filepath.Base(source.Name())
+		if source.Type() == "directory" {
+			fileName += ".zip"
+		}
+		fileName += AgeExtension
+	} else if ageInput {
+		fileName = strings.TrimSuffix(filepath.Base(source.Name()), AgeExtension)
+		if fileName == filepath.Base(source.Name()) {
+			fileName += ".decrypted"
+		}
+	} else if isEncrypt {
 		// 根据数据源类型选择适当的命名策略
 		if source.Type() == "directory" {
 			// 使用目录命名策略，只使用目录的基本名称
@@ -233,6 +477,11 @@ func (p *UnifiedProcessor) ProcessFile(ctx context.Context, inputPath, outputDir
 		encryptedFileName := filepath.Base(source.Name())
 		originalName, _, _, isDirectory := p.strategy.ParseEncryptedName(encryptedFileName)
 
+		// 优先使用头部中经过认证的原始文件名，文件被重命名后仍能还原
+		if header, err := format.ReadFile(inputPath); err == nil && header != nil && header.FileName() != "" {
+			originalName = header.FileName()
+		}
+
 		if isDirectory {
 			// 对于目录，解密后应该是一个目录名
 			fileName = originalName
@@ -245,7 +494,17 @@ func (p *UnifiedProcessor) ProcessFile(ctx context.Context, inputPath, outputDir
 	outputPath := filepath.Join(outputDir, fileName)
 
 	// 创建数据输出
-	sink, err := datasink.CreateSink(outputPath, opts.OutputFormat)
+	var sink domain.DataSink
+	if isEncrypt && opts.OutputFormat == domain.OutputAge {
+		var recipients []age.Recipient
+		if recipients, err = p.ageRecipients(opts.Method); err == nil {
+			sink, err = datasink.AgeSink(outputPath, recipients)
+		}
+	} else if ageInput {
+		sink, err = datasink.FileSink(outputPath)
+	} else {
+		sink, err = datasink.CreateSink(outputPath, opts.OutputFormat)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -259,6 +518,81 @@ func (p *UnifiedProcessor) ProcessFile(ctx context.Context, inputPath, outputDir
 	}
 }
 
+// VerifyFile 认证并解密文件但丢弃明文，ProcessedSize 为明文长度
+func (p *UnifiedProcessor) VerifyFile(ctx context.Context, inputPath string, opts domain.CryptoOptions) (*domain.CryptoResult, error) {
+	var source domain.DataSource
+	var err error
+	if isAgeFile(inputPath) {
+		var identities []age.Identity
+		if identities, err = p.ageIdentities(); err == nil {
+			source, err = datasource.AgeSource(inputPath, identities)
+		}
+	} else {
+		source, err = datasource.FileSource(inputPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sink := datasink.CreateDiscardSink()
+	result, err := p.Decrypt(ctx, source, sink, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.OutputPath = ""
+	result.ProcessedSize = sink.Written()
+	return result, nil
+}
+
+// OpenRange 打开 hycrypt 加密数据用于随机访问解密，size 为密文总长度
+//
+// 只支持分块流式格式且未压缩的数据。签名覆盖完整明文，随机访问时无法验签，要求签名时拒绝打开。
+func (p *UnifiedProcessor) OpenRange(src io.ReaderAt, size int64) (*RangeReader, error) {
+	section := io.NewSectionReader(src, 0, size)
+	header, err := format.Read(section)
+	if err != nil {
+		return nil, errors.InvalidFormat("hycrypt header", err)
+	}
+	if !header.IsStreaming() {
+		return nil, errors.InvalidFormat("hycrypt header",
+			fmt.Errorf("format version %d does not support random access", header.Version))
+	}
+	if _, ok := header.Get(format.TagCompression); ok {
+		return nil, errors.InvalidFormat("hycrypt header", fmt.Errorf("compressed data does not support random access"))
+	}
+	// 签名覆盖完整明文，范围读取无法验签；要求签名时已签名和未签名的文件都拒绝
+	if p.signer != nil && p.signer.config.RequireSignature {
+		if !header.HasFlag(format.FlagSigned) {
+			return nil, errors.SignatureInvalid("file is not signed", nil)
+		}
+		return nil, errors.SignatureInvalid("signature cannot be verified for byte ranges", nil)
+	}
+
+	method := header.Algorithm.String()
+	cryptoService, err := p.getCryptoService(method)
+	if err != nil {
+		return nil, err
+	}
+	opener, ok := cryptoService.(rangeOpener)
+	if !ok {
+		return nil, errors.InvalidConfig(fmt.Sprintf("%s does not support random access", method), nil)
+	}
+
+	aead, err := opener.openAEAD(header)
+	if err != nil {
+		return nil, err
+	}
+	offset, _ := section.Seek(0, io.SeekCurrent)
+	reader, err := newRangeReader(header, aead, src, offset, size-offset)
+	if err != nil {
+		if cryptoErr, ok := err.(*errors.CryptoErrorInterface); ok {
+			return nil, cryptoErr
+		}
+		return nil, errors.DecryptionFailed(method, err)
+	}
+	return reader, nil
+}
+
 // handleDirectoryDecryption 处理目录解密（解压zip文件）
 func (p *UnifiedProcessor) handleDirectoryDecryption(zipFilePath string) (string, error) {
 	// 确定目标目录路径
@@ -0,0 +1,226 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/datasink"
+	"hycrypt/internal/domain"
+	"hycrypt/internal/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestVerifyFileDiscardsPlaintext(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	processor, err := NewUnifiedProcessor(&ProcessorConfig{
+		Algorithms: map[string]any{constants.AlgorithmKMAC: &KMACConfig{Key: key, KeySize: 32, AESKeySize: 32}},
+	})
+	if err != nil {
+		t.Fatalf("NewUnifiedProcessor failed: %v", err)
+	}
+	opts := domain.CryptoOptions{Method: constants.AlgorithmKMAC}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("nightly integrity check"), 0600)
+	os.MkdirAll(filepath.Join(dir, "folder"), 0700)
+	os.WriteFile(filepath.Join(dir, "folder", "inner.txt"), []byte("inside"), 0600)
+
+	archive := t.TempDir()
+	for _, input := range []string{"notes.txt", "folder"} {
+		encrypted, err := processor.ProcessFile(context.Background(), filepath.Join(dir, input), archive, true, opts)
+		if err != nil {
+			t.Fatalf("encryption failed: %v", err)
+		}
+
+		result, err := processor.VerifyFile(context.Background(), encrypted.OutputPath, opts)
+		if err != nil {
+			t.Fatalf("%s: VerifyFile failed: %v", input, err)
+		}
+		if result.ProcessedSize == 0 {
+			t.Errorf("%s: no plaintext was authenticated", input)
+		}
+	}
+	// 校验不写出明文，也不解压目录
+	if entries, _ := os.ReadDir(archive); len(entries) != 2 {
+		t.Errorf("expected only the 2 encrypted files, found %d entries", len(entries))
+	}
+
+	entries, _ := os.ReadDir(archive)
+	tampered := filepath.Join(archive, entries[0].Name())
+	data, _ := os.ReadFile(tampered)
+	data[len(data)-1] ^= 1
+	os.WriteFile(tampered, data, 0600)
+	if _, err := processor.VerifyFile(context.Background(), tampered, opts); err == nil {
+		t.Error("expected tampered file to fail verification")
+	}
+}
+
+// failingSource 读出部分数据后返回错误的数据源
+type failingSource struct{}
+
+func (failingSource) Read(ctx context.Context) (io.ReadCloser, error) {
+	data := io.MultiReader(bytes.NewReader(make([]byte, 3*DefaultChunkSize)), iotest.ErrReader(fmt.Errorf("disk read error")))
+	return io.NopCloser(data), nil
+}
+func (failingSource) Size() int64  { return 0 }
+func (failingSource) Name() string { return "data.bin" }
+func (failingSource) Type() string { return "file" }
+
+func TestEncryptRemovesPartialOutput(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	processor, err := NewUnifiedProcessor(&ProcessorConfig{
+		Algorithms: map[string]any{constants.AlgorithmKMAC: &KMACConfig{Key: key, KeySize: 32, AESKeySize: 32}},
+		Workers:    1,
+	})
+	if err != nil {
+		t.Fatalf("NewUnifiedProcessor failed: %v", err)
+	}
+
+	sink, err := datasink.FileSink(filepath.Join(t.TempDir(), "data.bin.hycrypt"))
+	if err != nil {
+		t.Fatalf("FileSink failed: %v", err)
+	}
+	defer sink.Close()
+
+	if _, err := processor.Encrypt(context.Background(), failingSource{}, sink, domain.CryptoOptions{Method: constants.AlgorithmKMAC}); err == nil {
+		t.Fatal("expected encryption to fail")
+	}
+	if _, err := os.Stat(sink.Path()); !os.IsNotExist(err) {
+		t.Errorf("partial ciphertext left at %s", sink.Path())
+	}
+}
+
+// bytesSource 内存中的数据源
+type bytesSource struct {
+	name string
+	data []byte
+}
+
+func (s bytesSource) Read(ctx context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.data)), nil
+}
+func (s bytesSource) Size() int64  { return int64(len(s.data)) }
+func (s bytesSource) Name() string { return s.name }
+func (s bytesSource) Type() string { return "file" }
+
+func TestDecryptRemovesPartialOutput(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	processor, err := NewUnifiedProcessor(&ProcessorConfig{
+		Algorithms: map[string]any{constants.AlgorithmKMAC: &KMACConfig{Key: key, KeySize: 32, AESKeySize: 32}},
+	})
+	if err != nil {
+		t.Fatalf("NewUnifiedProcessor failed: %v", err)
+	}
+
+	encrypted, err := processor.services[constants.AlgorithmKMAC].EncryptData(context.Background(), bytes.NewReader(make([]byte, 3*DefaultChunkSize)))
+	if err != nil {
+		t.Fatalf("EncryptData failed: %v", err)
+	}
+	sealed, _ := io.ReadAll(encrypted)
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+
+	testCases := []struct {
+		name   string
+		source bytesSource
+	}{
+		{"tampered", bytesSource{"data.bin.hycrypt", tampered}},
+		{"truncated", bytesSource{"data.bin.hycrypt", sealed[:len(sealed)-DefaultChunkSize]}},
+		{"bad header", bytesSource{"data.bin.hycrypt", []byte("HYCRYPT\x00\xff")}},
+		{"unknown method", bytesSource{"data.bin", []byte("not encrypted")}},
+	}
+	for _, tc := range testCases {
+		sink, err := datasink.FileSink(filepath.Join(t.TempDir(), "data.bin"))
+		if err != nil {
+			t.Fatalf("FileSink failed: %v", err)
+		}
+		if _, err := processor.Decrypt(context.Background(), tc.source, sink, domain.CryptoOptions{}); err == nil {
+			t.Errorf("%s: expected decryption to fail", tc.name)
+		}
+		sink.Close()
+		if _, err := os.Stat(sink.Path()); !os.IsNotExist(err) {
+			t.Errorf("%s: partial plaintext left at %s", tc.name, sink.Path())
+		}
+	}
+}
+
+func TestDecryptWrongKeyRemovesOutput(t *testing.T) {
+	alice, err := NewUnifiedProcessor(&ProcessorConfig{Algorithms: map[string]any{
+		constants.AlgorithmX25519: writeTestKeys[X25519Config](t, constants.AlgorithmX25519),
+	}})
+	if err != nil {
+		t.Fatalf("NewUnifiedProcessor failed: %v", err)
+	}
+	bob, err := NewUnifiedProcessor(&ProcessorConfig{Algorithms: map[string]any{
+		constants.AlgorithmX25519: writeTestKeys[X25519Config](t, constants.AlgorithmX25519),
+	}})
+	if err != nil {
+		t.Fatalf("NewUnifiedProcessor failed: %v", err)
+	}
+
+	encrypted, err := alice.services[constants.AlgorithmX25519].EncryptData(context.Background(), strings.NewReader("for alice"))
+	if err != nil {
+		t.Fatalf("EncryptData failed: %v", err)
+	}
+	sealed, _ := io.ReadAll(encrypted)
+
+	sink, err := datasink.FileSink(filepath.Join(t.TempDir(), "data.bin"))
+	if err != nil {
+		t.Fatalf("FileSink failed: %v", err)
+	}
+	defer sink.Close()
+
+	_, err = bob.Decrypt(context.Background(), bytesSource{"data.bin.hycrypt", sealed}, sink, domain.CryptoOptions{})
+	if cryptoErr, ok := err.(*errors.CryptoErrorInterface); !ok || cryptoErr.Code != errors.ErrWrongKey {
+		t.Fatalf("expected wrong key error, got %v", err)
+	}
+	if _, err := os.Stat(sink.Path()); !os.IsNotExist(err) {
+		t.Errorf("empty plaintext left at %s", sink.Path())
+	}
+}
+
+func TestProcessorWorkersMismatch(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	newProcessor := func(workers int) *UnifiedProcessor {
+		processor, err := NewUnifiedProcessor(&ProcessorConfig{
+			Algorithms: map[string]any{constants.AlgorithmKMAC: &KMACConfig{Key: key, KeySize: 32, AESKeySize: 32}},
+			Workers:    workers,
+		})
+		if err != nil {
+			t.Fatalf("NewUnifiedProcessor failed: %v", err)
+		}
+		return processor
+	}
+	opts := domain.CryptoOptions{Method: constants.AlgorithmKMAC}
+
+	plaintext := make([]byte, 5*DefaultChunkSize+123)
+	rand.Read(plaintext)
+	input := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(input, plaintext, 0600)
+
+	// 加密与解密使用不同的并发数
+	for _, workers := range [][2]int{{1, 8}, {8, 1}, {3, 2}} {
+		encrypted, err := newProcessor(workers[0]).ProcessFile(context.Background(), input, t.TempDir(), true, opts)
+		if err != nil {
+			t.Fatalf("%d workers: encryption failed: %v", workers[0], err)
+		}
+		decrypted, err := newProcessor(workers[1]).ProcessFile(context.Background(), encrypted.OutputPath, t.TempDir(), false, opts)
+		if err != nil {
+			t.Fatalf("sealed with %d workers, opened with %d: decryption failed: %v", workers[0], workers[1], err)
+		}
+		if opened, _ := os.ReadFile(decrypted.OutputPath); !bytes.Equal(opened, plaintext) {
+			t.Errorf("sealed with %d workers, opened with %d: plaintext mismatch", workers[0], workers[1])
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"fmt"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+	"sync"
+)
+
+// rangeOpener 支持随机访问解密的服务，校验头部并返回分块流式格式使用的 AEAD
+type rangeOpener interface {
+	openAEAD(header *format.Header) (cipher.AEAD, error)
+}
+
+// RangeReader 随机访问解密读取器，实现 io.ReaderAt 与 io.ReadSeeker
+//
+// 分块在文件中的位置由分块大小直接算出，读取时只解密覆盖请求范围的分块，每个分块
+// 独立认证。打开时认证最后一个分块以发现截断，并去除长度隐藏填充和签名；签名覆盖
+// 完整明文，随机访问时不验签。
+type RangeReader struct {
+	aead       cipher.AEAD
+	prefix     []byte
+	aad        []byte
+	src        io.ReaderAt
+	bodyOffset int64
+	bodySize   int64
+	chunkSize  int64
+	chunks     int64
+	size       int64
+	pos        int64
+
+	mu     sync.Mutex
+	cached int64
+	sealed []byte
+	nonce  []byte
+	plain  []byte
+}
+
+// newRangeReader 创建随机访问解密读取器，bodyOffset 和 bodySize 为头部之后的分块密文范围
+func newRangeReader(header *format.Header, aead cipher.AEAD, src io.ReaderAt, bodyOffset, bodySize int64) (*RangeReader, error) {
+	chunkSize, prefix, aad, err := streamParams(header, aead)
+	if err != nil {
+		return nil, err
+	}
+	padding, err := streamPadding(header)
+	if err != nil {
+		return nil, err
+	}
+
+	overhead := int64(aead.Overhead())
+	sealedSize := int64(chunkSize) + overhead
+	chunks := (bodySize + sealedSize - 1) / sealedSize
+	if chunks == 0 {
+		return nil, errors.InvalidFormat("encrypted stream", fmt.Errorf("truncated before final chunk"))
+	}
+	if chunks-1 > int64(^uint32(0)) {
+		return nil, fmt.Errorf("stream too long: chunk counter overflow")
+	}
+	lastSealed := bodySize - (chunks-1)*sealedSize
+	if lastSealed < overhead {
+		return nil, fmt.Errorf("encrypted chunk %d too short", chunks-1)
+	}
+
+	r := &RangeReader{
+		aead:       aead,
+		prefix:     prefix,
+		aad:        aad,
+		src:        src,
+		bodyOffset: bodyOffset,
+		bodySize:   bodySize,
+		chunkSize:  int64(chunkSize),
+		chunks:     chunks,
+		size:       (chunks-1)*int64(chunkSize) + lastSealed - overhead,
+		cached:     -1,
+		sealed:     make([]byte, sealedSize),
+		plain:      make([]byte, 0, chunkSize),
+	}
+
+	// 认证最后一个分块: 截断在分块边界的文件在这里失败
+	if _, err := r.chunk(chunks - 1); err != nil {
+		return nil, err
+	}
+
+	if padding != format.PaddingNone {
+		if r.size, err = r.unpaddedSize(); err != nil {
+			return nil, err
+		}
+	}
+	if header.HasFlag(format.FlagSigned) {
+		if r.size < ed25519.SignatureSize {
+			return nil, errors.SignatureInvalid("signature missing", nil)
+		}
+		r.size -= ed25519.SignatureSize
+	}
+	return r, nil
+}
+
+// unpaddedSize 从末尾向前查找填充标记，返回去除填充后的明文长度
+func (r *RangeReader) unpaddedSize() (int64, error) {
+	for index := (r.size - 1) / r.chunkSize; index >= 0; index-- {
+		plain, err := r.chunk(index)
+		if err != nil {
+			return 0, err
+		}
+		trimmed := bytes.TrimRight(plain, "\x00")
+		if len(trimmed) == 0 {
+			continue
+		}
+		if trimmed[len(trimmed)-1] != paddingMarker {
+			break
+		}
+		return index*r.chunkSize + int64(len(trimmed)) - 1, nil
+	}
+	return 0, errors.InvalidFormat("encrypted stream", fmt.Errorf("missing padding marker"))
+}
+
+// chunk 读取并解密第 index 个分块，调用方需持有锁或尚未共享读取器
+func (r *RangeReader) chunk(index int64) ([]byte, error) {
+	if index == r.cached {
+		return r.plain, nil
+	}
+
+	sealedSize := int64(len(r.sealed))
+	offset := index * sealedSize
+	sealed := r.sealed[:min(sealedSize, r.bodySize-offset)]
+	// io.ReaderAt 允许读满时同时返回 io.EOF，只有读不满才是截断
+	if n, err := r.src.ReadAt(sealed, r.bodyOffset+offset); n < len(sealed) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	last := index == r.chunks-1
+	r.cached = -1
+	r.nonce = streamNonce(r.nonce, r.prefix, uint32(index), last)
+	plain, err := r.aead.Open(r.plain[:0], r.nonce, sealed, r.aad)
+	if err != nil {
+		if last {
+			return nil, errors.InvalidFormat("encrypted stream",
+				fmt.Errorf("chunk %d authentication failed (stream may be truncated or header tampered): %w", index, err))
+		}
+		return nil, errors.InvalidFormat("encrypted stream",
+			fmt.Errorf("chunk %d authentication failed (header or data tampered): %w", index, err))
+	}
+
+	r.plain = plain
+	r.cached = index
+	return plain, nil
+}
+
+// Size 返回明文长度
+func (r *RangeReader) Size() int64 {
+	return r.size
+}
+
+// ReadAt 解密从 off 开始的明文，可以并发调用
+func (r *RangeReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset: %d", off)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for n < len(p) && off < r.size {
+		index := off / r.chunkSize
+		plain, err := r.chunk(index)
+		if err != nil {
+			return n, err
+		}
+		start := off - index*r.chunkSize
+		end := min(int64(len(plain)), r.size-index*r.chunkSize)
+		copied := copy(p[n:], plain[start:end])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Read 从当前位置顺序解密
+func (r *RangeReader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.pos)
+	r.pos += int64(n)
+	if n > 0 && err == io.EOF {
+		return n, nil
+	}
+	return n, err
+}
+
+// Seek 设置下一次 Read 的明文位置
+func (r *RangeReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position: %d", offset)
+	}
+	r.pos = offset
+	return offset, nil
+}
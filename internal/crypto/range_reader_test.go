@@ -0,0 +1,207 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/domain"
+	"hycrypt/internal/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRangeReader(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+
+	tests := []struct {
+		name    string
+		config  ProcessorConfig
+		sizes   []int
+		wantErr bool
+	}{
+		{"plain", ProcessorConfig{}, []int{0, 100, DefaultChunkSize, 3*DefaultChunkSize + 17}, false},
+		{"padded", ProcessorConfig{Padding: constants.PaddingBucket}, []int{0, 100, 3*DefaultChunkSize + 17}, false},
+		{"signed", ProcessorConfig{SigningConfig: writeTestSigningKey(t)}, []int{0, DefaultChunkSize - 10}, false},
+		{"compressed", ProcessorConfig{Compression: constants.CompressionGzip}, []int{1000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Algorithms = map[string]any{constants.AlgorithmKMAC: &KMACConfig{Key: key, KeySize: 32, AESKeySize: 32}}
+			processor, err := NewUnifiedProcessor(&config)
+			if err != nil {
+				t.Fatalf("NewUnifiedProcessor failed: %v", err)
+			}
+
+			for _, size := range tt.sizes {
+				plaintext := bytes.Repeat([]byte("0123456789abcdef"), size/16+1)[:size]
+				input := filepath.Join(t.TempDir(), "data.txt")
+				os.WriteFile(input, plaintext, 0600)
+				encrypted, err := processor.ProcessFile(context.Background(), input, t.TempDir(), true,
+					domain.CryptoOptions{Method: constants.AlgorithmKMAC})
+				if err != nil {
+					t.Fatalf("encryption failed: %v", err)
+				}
+				sealed, _ := os.ReadFile(encrypted.OutputPath)
+
+				reader, err := processor.OpenRange(bytes.NewReader(sealed), int64(len(sealed)))
+				if tt.wantErr {
+					if err == nil {
+						t.Error("expected OpenRange to fail")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("size %d: OpenRange failed: %v", size, err)
+				}
+				if reader.Size() != int64(size) {
+					t.Fatalf("size %d: Size() = %d", size, reader.Size())
+				}
+
+				// 跨分块边界及越过结尾的范围
+				for _, r := range [][2]int{{0, 10}, {size / 2, 50}, {DefaultChunkSize - 5, 10}, {size - 3, 10}, {0, size}} {
+					off, length := max(r[0], 0), r[1]
+					buf := make([]byte, length)
+					n, err := reader.ReadAt(buf, int64(off))
+					want := plaintext[min(off, size):min(off+length, size)]
+					if !bytes.Equal(buf[:n], want) || (n < length && err != io.EOF) {
+						t.Errorf("size %d: ReadAt(%d, %d) = %d bytes, %v", size, off, length, n, err)
+					}
+				}
+
+				reader.Seek(int64(size/3), io.SeekStart)
+				if rest, err := io.ReadAll(reader); err != nil || !bytes.Equal(rest, plaintext[size/3:]) {
+					t.Errorf("size %d: Read after Seek mismatch: %v", size, err)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenRangeRequireSignature(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	newProcessor := func(signing *SigningConfig) *UnifiedProcessor {
+		processor, err := NewUnifiedProcessor(&ProcessorConfig{
+			Algorithms:    map[string]any{constants.AlgorithmKMAC: &KMACConfig{Key: key, KeySize: 32, AESKeySize: 32}},
+			SigningConfig: signing,
+		})
+		if err != nil {
+			t.Fatalf("NewUnifiedProcessor failed: %v", err)
+		}
+		return processor
+	}
+	signing := writeTestSigningKey(t)
+	signer := newProcessor(signing)
+	required := *signing
+	required.RequireSignature = true
+	verifier := newProcessor(&required)
+
+	for _, tt := range []struct {
+		name      string
+		processor *UnifiedProcessor
+	}{
+		{"signed", signer},
+		{"unsigned", newProcessor(nil)},
+	} {
+		input := filepath.Join(t.TempDir(), "data.txt")
+		os.WriteFile(input, []byte("range"), 0600)
+		encrypted, err := tt.processor.ProcessFile(context.Background(), input, t.TempDir(), true,
+			domain.CryptoOptions{Method: constants.AlgorithmKMAC})
+		if err != nil {
+			t.Fatalf("%s: encryption failed: %v", tt.name, err)
+		}
+		sealed, _ := os.ReadFile(encrypted.OutputPath)
+
+		// 范围读取无法验签，要求签名时一律拒绝
+		_, err = verifier.OpenRange(bytes.NewReader(sealed), int64(len(sealed)))
+		if cryptoErr, ok := err.(*errors.CryptoErrorInterface); !ok || cryptoErr.Code != errors.ErrSignatureInvalid {
+			t.Errorf("%s: expected %s error, got %v", tt.name, errors.ErrSignatureInvalid, err)
+		}
+	}
+}
+
+func TestRangeReaderTruncated(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	service, err := KMACService(&KMACConfig{Key: key, KeySize: 32, AESKeySize: 32})
+	if err != nil {
+		t.Fatalf("KMACService failed: %v", err)
+	}
+	processor := &UnifiedProcessor{services: map[string]CryptoService{constants.AlgorithmKMAC: service}}
+
+	encrypted, err := service.EncryptData(context.Background(), bytes.NewReader(make([]byte, 3*DefaultChunkSize)))
+	if err != nil {
+		t.Fatalf("EncryptData failed: %v", err)
+	}
+	sealed, _ := io.ReadAll(encrypted)
+
+	// 截断在分块边界: 剩余分块都能单独认证，只有结束标志能发现截断
+	truncated := sealed[:len(sealed)-DefaultChunkSize-16]
+	if _, err := processor.OpenRange(bytes.NewReader(truncated), int64(len(truncated))); err == nil {
+		t.Error("expected truncated stream to fail")
+	}
+
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-2*DefaultChunkSize] ^= 1
+	reader, err := processor.OpenRange(bytes.NewReader(tampered), int64(len(tampered)))
+	if err != nil {
+		t.Fatalf("OpenRange failed: %v", err)
+	}
+	if _, err := reader.ReadAt(make([]byte, 10), 0); err != nil {
+		t.Errorf("untouched chunk should decrypt: %v", err)
+	}
+	if _, err := reader.ReadAt(make([]byte, 10), DefaultChunkSize+10); err == nil {
+		t.Error("expected tampered chunk to fail")
+	}
+}
+
+// eofReaderAt 读到数据末尾时即使读满也返回 io.EOF，io.ReaderAt 允许这种行为
+type eofReaderAt struct {
+	data []byte
+}
+
+func (r eofReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(r.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data[off:])
+	if off+int64(n) == int64(len(r.data)) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func TestRangeReaderEOFOnFullRead(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	service, err := KMACService(&KMACConfig{Key: key, KeySize: 32, AESKeySize: 32})
+	if err != nil {
+		t.Fatalf("KMACService failed: %v", err)
+	}
+	processor := &UnifiedProcessor{services: map[string]CryptoService{constants.AlgorithmKMAC: service}}
+
+	plaintext := make([]byte, DefaultChunkSize+100)
+	rand.Read(plaintext)
+	encrypted, err := service.EncryptData(context.Background(), bytes.NewReader(plaintext))
+	if err != nil {
+		t.Fatalf("EncryptData failed: %v", err)
+	}
+	sealed, _ := io.ReadAll(encrypted)
+
+	reader, err := processor.OpenRange(eofReaderAt{sealed}, int64(len(sealed)))
+	if err != nil {
+		t.Fatalf("OpenRange failed: %v", err)
+	}
+	tail := make([]byte, 100)
+	if _, err := reader.ReadAt(tail, DefaultChunkSize); err != nil && err != io.EOF {
+		t.Fatalf("ReadAt of last chunk failed: %v", err)
+	}
+	if !bytes.Equal(tail, plaintext[DefaultChunkSize:]) {
+		t.Error("last chunk mismatch")
+	}
+}
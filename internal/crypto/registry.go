@@ -0,0 +1,228 @@
+package crypto
+
+import (
+	"fmt"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"hycrypt/internal/naming"
+	"sort"
+	"sync"
+)
+
+// KeyFile 算法所需的密钥文件
+type KeyFile struct {
+	// Name keys 配置项名称（yaml 键），如 "public_key"
+	Name string
+	// Private 私钥文件，以 0600 权限保存
+	Private bool
+	// ShareKind 拆分为分片时记录的密钥类型，为空表示该文件不支持拆分
+	ShareKind string
+}
+
+// SymmetricKey 对称密钥文件与原始密钥之间的编解码，用于助记词备份
+type SymmetricKey struct {
+	// Size 按配置参数返回密钥字节数
+	Size func(params Parameters) int
+	// Decode 解码密钥文件内容，受口令保护时通过 passphrase 获取口令
+	Decode func(keyData []byte, path string, passphrase PassphraseFunc) ([]byte, error)
+	// Encode 编码为密钥文件内容，passphrase 非空时加密保存
+	Encode func(key, passphrase []byte) ([]byte, error)
+}
+
+// ConfigureOptions 构建算法配置时的选项
+type ConfigureOptions struct {
+	// Selected 是否为当前选择的算法；为 false 表示仅为解密其他文件而顺带配置
+	Selected bool
+	// Passphrase 获取受口令保护私钥的口令
+	Passphrase PassphraseFunc
+	// Password 口令算法使用的口令，为空时口令算法不配置
+	Password []byte
+}
+
+// Parameters 算法相关的配置参数，用于配置校验和密钥生成
+type Parameters struct {
+	AESKeySize      int
+	RSAKeySize      int
+	KMACKeySize     int
+	Argon2Time      uint32
+	Argon2MemoryKiB uint32
+	Argon2Threads   uint8
+}
+
+// Settings 构建处理器配置时读取的配置，由 config.Config 实现
+type Settings interface {
+	// AlgorithmParameters 返回算法相关的配置参数
+	AlgorithmParameters() Parameters
+	// GetKeyFilePath 按 KeyFile.Name 返回当前选择的密钥文件完整路径
+	GetKeyFilePath(name string) string
+	// GetRSARecipientPaths 返回额外 RSA 接收方公钥文件完整路径
+	GetRSARecipientPaths() []string
+}
+
+// Algorithm 已注册的加密算法
+type Algorithm struct {
+	// Name 算法名称，用于命令行参数、配置文件和加密文件名
+	Name string
+	// HeaderID 文件头部中的算法标识，名称必须与 format 中的定义一致
+	HeaderID format.AlgorithmID
+	// DisplayName 界面中显示的名称
+	DisplayName string
+	// Icon 菜单图标
+	Icon string
+	// Order 菜单与列表中的排列顺序
+	Order int
+
+	// KeyFiles 所需的密钥文件，口令算法为空
+	KeyFiles []KeyFile
+	// NeedsPassword 加解密时需要输入口令
+	NeedsPassword bool
+
+	// JWEAlgorithm 文本输出为 JWE 时使用的密钥管理算法（alg），为空表示不支持 JWE
+	JWEAlgorithm string
+	// SymmetricKey 对称密钥的编解码，支持助记词备份；使用密钥对的算法为空
+	SymmetricKey *SymmetricKey
+
+	// Configure 按配置构建该算法的配置，存入 ProcessorConfig.Algorithms；返回 nil 表示不配置该算法
+	Configure func(settings Settings, options ConfigureOptions) (any, error)
+	// NewService 按 Configure 返回的配置创建服务
+	NewService func(config any) (CryptoService, error)
+	// Validate 校验算法相关的配置参数，可为空
+	Validate func(params Parameters) error
+	// GenerateKeys 生成密钥文件内容，按 KeyFile.Name 索引；不需要密钥文件的算法为空
+	GenerateKeys func(params Parameters) (map[string][]byte, error)
+	// Fingerprint 读取密钥文件计算密钥指纹，paths 按 KeyFile.Name 索引；受口令保护时通过 passphrase 获取口令；
+	// 不使用密钥文件的算法为空
+	Fingerprint func(paths map[string]string, passphrase PassphraseFunc) ([]byte, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]*Algorithm{}
+)
+
+// Register 注册加密算法并登记其头部标识名称，名称或头部标识重复时 panic
+func Register(algorithm *Algorithm) {
+	if algorithm.Name == "" || algorithm.NewService == nil {
+		panic("crypto: algorithm must have a name and a service factory")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[algorithm.Name]; exists {
+		panic(fmt.Sprintf("crypto: algorithm %s registered twice", algorithm.Name))
+	}
+	format.RegisterAlgorithm(algorithm.HeaderID, algorithm.Name)
+	registry[algorithm.Name] = algorithm
+	naming.RegisterMethod(algorithm.Name)
+}
+
+// newServiceFunc 将按具体配置类型创建服务的函数包装为 Algorithm.NewService
+func newServiceFunc[C any, S CryptoService](newService func(*C) (S, error)) func(any) (CryptoService, error) {
+	return func(config any) (CryptoService, error) {
+		typed, ok := config.(*C)
+		if !ok {
+			return nil, fmt.Errorf("unexpected config type %T", config)
+		}
+		return newService(typed)
+	}
+}
+
+// algorithmConfig 返回处理器配置中指定算法的配置，未配置或类型不符时返回 nil
+func algorithmConfig[C any](config *ProcessorConfig, name string) *C {
+	typed, _ := config.Algorithms[name].(*C)
+	return typed
+}
+
+// KeyFilesFor 返回加密或解密时需要读取的密钥文件：对称密钥算法始终需要全部文件，使用密钥对的算法只有解密需要私钥
+func (a *Algorithm) KeyFilesFor(decrypt bool) []KeyFile {
+	if a.SymmetricKey != nil {
+		return a.KeyFiles
+	}
+	var keyFiles []KeyFile
+	for _, keyFile := range a.KeyFiles {
+		if keyFile.Private == decrypt {
+			keyFiles = append(keyFiles, keyFile)
+		}
+	}
+	return keyFiles
+}
+
+// ShareKeyFile 返回支持拆分为分片的密钥文件
+func (a *Algorithm) ShareKeyFile() (KeyFile, bool) {
+	for _, keyFile := range a.KeyFiles {
+		if keyFile.ShareKind != "" {
+			return keyFile, true
+		}
+	}
+	return KeyFile{}, false
+}
+
+// LookupAlgorithm 按名称查找已注册的算法
+func LookupAlgorithm(name string) (*Algorithm, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	algorithm, ok := registry[name]
+	return algorithm, ok
+}
+
+// Algorithms 返回全部已注册算法，按 Order 排序
+func Algorithms() []*Algorithm {
+	registryMu.RLock()
+	algorithms := make([]*Algorithm, 0, len(registry))
+	for _, algorithm := range registry {
+		algorithms = append(algorithms, algorithm)
+	}
+	registryMu.RUnlock()
+
+	sort.Slice(algorithms, func(i, j int) bool {
+		if algorithms[i].Order != algorithms[j].Order {
+			return algorithms[i].Order < algorithms[j].Order
+		}
+		return algorithms[i].Name < algorithms[j].Name
+	})
+	return algorithms
+}
+
+// AlgorithmNames 返回全部已注册算法的名称
+func AlgorithmNames() []string {
+	algorithms := Algorithms()
+	names := make([]string, len(algorithms))
+	for i, algorithm := range algorithms {
+		names[i] = algorithm.Name
+	}
+	return names
+}
+
+// JWEAlgorithms 返回支持 JWE 输出的算法名称
+func JWEAlgorithms() []string {
+	var names []string
+	for _, algorithm := range Algorithms() {
+		if algorithm.JWEAlgorithm != "" {
+			names = append(names, algorithm.Name)
+		}
+	}
+	return names
+}
+
+// IsRegisteredAlgorithm 检查算法是否已注册
+func IsRegisteredAlgorithm(name string) bool {
+	_, ok := LookupAlgorithm(name)
+	return ok
+}
+
+// newServices 为处理器配置中已配置的算法创建服务
+func newServices(config *ProcessorConfig) (map[string]CryptoService, error) {
+	services := make(map[string]CryptoService)
+	for _, algorithm := range Algorithms() {
+		algorithmConfig, ok := config.Algorithms[algorithm.Name]
+		if !ok || algorithmConfig == nil {
+			continue
+		}
+		service, err := algorithm.NewService(algorithmConfig)
+		if err != nil {
+			return nil, errors.InvalidConfig(fmt.Sprintf("failed to initialize %s service", algorithm.DisplayName), err)
+		}
+		services[algorithm.Name] = service
+	}
+	return services, nil
+}
@@ -0,0 +1,65 @@
+package crypto
+
+import (
+	"hycrypt/internal/constants"
+	"slices"
+	"testing"
+)
+
+func TestAlgorithmRegistry(t *testing.T) {
+	expected := []string{
+		constants.AlgorithmRSA,
+		constants.AlgorithmKMAC,
+		constants.AlgorithmPassword,
+		constants.AlgorithmX25519,
+		constants.AlgorithmMLKEM,
+	}
+	if names := AlgorithmNames(); !slices.Equal(names, expected) {
+		t.Errorf("AlgorithmNames() = %v, expected %v", names, expected)
+	}
+
+	testCases := []struct {
+		algorithm string
+		expected  bool
+	}{
+		{constants.AlgorithmRSA, true},
+		{constants.AlgorithmKMAC, true},
+		{constants.AlgorithmPassword, true},
+		{constants.AlgorithmX25519, true},
+		{constants.AlgorithmMLKEM, true},
+		{"aes", false},
+		{"unknown", false},
+		{"", false},
+	}
+	for _, tc := range testCases {
+		if result := IsRegisteredAlgorithm(tc.algorithm); result != tc.expected {
+			t.Errorf("IsRegisteredAlgorithm(%s) = %v, expected %v", tc.algorithm, result, tc.expected)
+		}
+	}
+}
+
+func TestAlgorithmGenerateKeys(t *testing.T) {
+	params := Parameters{RSAKeySize: 2048, KMACKeySize: 32}
+
+	for _, algorithm := range Algorithms() {
+		if algorithm.GenerateKeys == nil {
+			if len(algorithm.KeyFiles) != 0 {
+				t.Errorf("%s: key files declared without a key generator", algorithm.Name)
+			}
+			continue
+		}
+
+		files, err := algorithm.GenerateKeys(params)
+		if err != nil {
+			t.Fatalf("%s: GenerateKeys failed: %v", algorithm.Name, err)
+		}
+		if len(files) != len(algorithm.KeyFiles) {
+			t.Errorf("%s: generated %d key files, expected %d", algorithm.Name, len(files), len(algorithm.KeyFiles))
+		}
+		for _, keyFile := range algorithm.KeyFiles {
+			if len(files[keyFile.Name]) == 0 {
+				t.Errorf("%s: missing generated key file %s", algorithm.Name, keyFile.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+	"os"
+	"slices"
+)
+
+// RekeySkipped 文件无需或无法原地轮换接收方，Reason 说明原因
+type RekeySkipped struct {
+	Reason string
+}
+
+func (e *RekeySkipped) Error() string {
+	return e.Reason
+}
+
+// RekeyFile 用 identity 的私钥解开文件中的数据密钥，再为本服务的全部接收方重新封装
+// 只替换头部中的接收方字段，加密载荷按字节原样复制，完成后原子替换原文件
+func (r *RSAServiceInterface) RekeyFile(path string, identity *RSAServiceInterface) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.FileNotFound(path)
+	}
+	defer file.Close()
+
+	header, body, err := format.Open(file)
+	if err != nil {
+		return errors.InvalidFormat("rsa encrypted data", err)
+	}
+	if header == nil {
+		return &RekeySkipped{Reason: "no hycrypt header"}
+	}
+	if header.Algorithm != format.AlgorithmRSA {
+		return &RekeySkipped{Reason: fmt.Sprintf("encrypted with %s", header.Algorithm)}
+	}
+	if !header.CanRewrapKeys() {
+		return &RekeySkipped{Reason: fmt.Sprintf("format version %d authenticates the wrapped keys, decrypt and re-encrypt instead", header.Version)}
+	}
+
+	// 接收方未变化时无需换钥
+	recipients := r.recipientKeys()
+	fingerprints := make([][]byte, len(recipients))
+	for i, publicKey := range recipients {
+		fingerprints[i] = rsaFingerprint(publicKey)
+	}
+	if slices.EqualFunc(header.GetAll(format.TagKeyFingerprint), fingerprints, bytes.Equal) {
+		return &RekeySkipped{Reason: "already wrapped for the current recipients"}
+	}
+
+	// 先核对旧密钥指纹，密钥不符时无需输入私钥口令
+	if err := checkKeyFingerprint(header, constants.AlgorithmRSA, identity.fingerprint()); err != nil {
+		return err
+	}
+	if identity.privateKey == nil {
+		if err := identity.loadPrivateKey(); err != nil {
+			return err
+		}
+	}
+
+	wrappedKeys := header.GetAll(format.TagWrappedKey)
+	if len(wrappedKeys) == 0 {
+		return errors.InvalidFormat("rsa encrypted data", fmt.Errorf("missing wrapped key"))
+	}
+	dataKey, err := identity.unwrapKey(wrappedKeys)
+	if err != nil {
+		return errors.DecryptionFailed(constants.AlgorithmRSA, err)
+	}
+	defer clear(dataKey)
+
+	// 为新接收方重新封装同一个数据密钥，其余头部字段保持不变
+	header.Remove(format.TagWrappedKey)
+	header.Remove(format.TagKeyFingerprint)
+	for i, publicKey := range recipients {
+		encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, dataKey, nil)
+		if err != nil {
+			return errors.EncryptionFailed(constants.AlgorithmRSA, err)
+		}
+		header.Add(format.TagWrappedKey, encryptedKey)
+		header.Add(format.TagKeyFingerprint, fingerprints[i])
+	}
+
+	headerBytes, err := header.Marshal()
+	if err != nil {
+		return errors.EncryptionFailed(constants.AlgorithmRSA, err)
+	}
+	// 保留原文件权限
+	info, err := file.Stat()
+	if err != nil {
+		return errors.FileNotFound(path)
+	}
+	return writeFileAtomic(path, io.MultiReader(bytes.NewReader(headerBytes), body), info.Mode().Perm())
+}
@@ -0,0 +1,86 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRSARekeyFile(t *testing.T) {
+	alice, err := RSAService(writeTestKeys[RSAConfig](t, constants.AlgorithmRSA))
+	if err != nil {
+		t.Fatalf("RSAService failed: %v", err)
+	}
+	bob, err := RSAService(writeTestKeys[RSAConfig](t, constants.AlgorithmRSA))
+	if err != nil {
+		t.Fatalf("RSAService failed: %v", err)
+	}
+
+	plaintext := bytes.Repeat([]byte("rotate me "), 20000)
+	encrypted, err := alice.EncryptData(context.Background(), bytes.NewReader(plaintext))
+	if err != nil {
+		t.Fatalf("EncryptData failed: %v", err)
+	}
+	sealed, _ := io.ReadAll(encrypted)
+	_, oldBody, _ := format.Parse(sealed)
+
+	path := filepath.Join(t.TempDir(), "data.hycrypt")
+	os.WriteFile(path, sealed, 0600)
+
+	// alice 的私钥解开数据密钥，重新封装给 bob
+	if err := bob.RekeyFile(path, alice); err != nil {
+		t.Fatalf("RekeyFile failed: %v", err)
+	}
+	rotated, _ := os.ReadFile(path)
+	header, newBody, err := format.Parse(rotated)
+	if err != nil {
+		t.Fatalf("format.Parse failed: %v", err)
+	}
+	if !bytes.Equal(newBody, oldBody) {
+		t.Error("rekey modified the encrypted payload")
+	}
+	if fingerprints := header.GetAll(format.TagKeyFingerprint); len(fingerprints) != 1 || !bytes.Equal(fingerprints[0], bob.fingerprint()) {
+		t.Errorf("expected only bob's fingerprint, got %x", fingerprints)
+	}
+
+	decrypted, err := bob.DecryptData(context.Background(), bytes.NewReader(rotated))
+	if err != nil {
+		t.Fatalf("DecryptData failed: %v", err)
+	}
+	if opened, err := io.ReadAll(decrypted); err != nil || !bytes.Equal(opened, plaintext) {
+		t.Fatalf("round trip mismatch: %v", err)
+	}
+	if _, err := alice.DecryptData(context.Background(), bytes.NewReader(rotated)); err == nil {
+		t.Error("old key still decrypts the rotated file")
+	}
+
+	// 已是当前接收方时跳过
+	if _, ok := bob.RekeyFile(path, alice).(*RekeySkipped); !ok {
+		t.Error("expected rotated file to be skipped")
+	}
+
+	// 旧密钥无法解开时报告密钥不符
+	other := filepath.Join(t.TempDir(), "other.hycrypt")
+	os.WriteFile(other, sealed, 0600)
+	err = bob.RekeyFile(other, bob)
+	if cryptoErr, ok := err.(*errors.CryptoErrorInterface); !ok || cryptoErr.Code != errors.ErrWrongKey {
+		t.Errorf("expected wrong key error, got %v", err)
+	}
+
+	// 版本 4 的关联数据包含封装的数据密钥，不能原地换钥
+	version4 := append([]byte{}, sealed...)
+	version4[len(format.Magic)] = format.Version4
+	os.WriteFile(other, version4, 0600)
+	if _, ok := bob.RekeyFile(other, alice).(*RekeySkipped); !ok {
+		t.Error("expected version 4 file to be skipped")
+	}
+	if unchanged, _ := os.ReadFile(other); !bytes.Equal(unchanged, version4) {
+		t.Error("skipped file was modified")
+	}
+}
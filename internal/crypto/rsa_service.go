@@ -1,7 +1,9 @@
 package crypto
 
 import (
+	"bytes"
 	"context"
+	"crypto/cipher"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -11,16 +13,65 @@ import (
 	"fmt"
 	"hycrypt/internal/constants"
 	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"hycrypt/internal/jwe"
 	"io"
 	"os"
+	"slices"
 	"strings"
 )
 
+func init() {
+	Register(&Algorithm{
+		Name:        constants.AlgorithmRSA,
+		HeaderID:    format.AlgorithmRSA,
+		DisplayName: "RSA",
+		Icon:        "🔐",
+		Order:       10,
+		KeyFiles: []KeyFile{
+			{Name: "public_key"},
+			{Name: "private_key", Private: true, ShareKind: KeyShareKindPrivateKey},
+		},
+		JWEAlgorithm: jwe.AlgRSAOAEP256,
+		Configure: func(settings Settings, options ConfigureOptions) (any, error) {
+			params := settings.AlgorithmParameters()
+			return &RSAConfig{
+				PublicKeyPath:  settings.GetKeyFilePath("public_key"),
+				PrivateKeyPath: settings.GetKeyFilePath("private_key"),
+				KeySize:        params.RSAKeySize,
+				AESKeySize:     params.AESKeySize,
+				RecipientPaths: settings.GetRSARecipientPaths(),
+				Passphrase:     options.Passphrase,
+			}, nil
+		},
+		NewService: newServiceFunc(RSAService),
+		Validate: func(params Parameters) error {
+			if params.RSAKeySize != 2048 && params.RSAKeySize != 3072 && params.RSAKeySize != 4096 {
+				return fmt.Errorf("RSA key size must be 2048, 3072 or 4096 bits")
+			}
+			return nil
+		},
+		GenerateKeys: generateRSAKeys,
+		Fingerprint: func(paths map[string]string, passphrase PassphraseFunc) ([]byte, error) {
+			service, err := RSAService(&RSAConfig{
+				PublicKeyPath:  paths["public_key"],
+				PrivateKeyPath: paths["private_key"],
+				Passphrase:     passphrase,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return service.fingerprint(), nil
+		},
+	})
+}
+
 // RSAServiceInterface RSA加密服务
 type RSAServiceInterface struct {
 	*BaseService
 	publicKey  *rsa.PublicKey
 	privateKey *rsa.PrivateKey
+	recipients []*rsa.PublicKey
 	config     *RSAConfig
 }
 
@@ -35,41 +86,59 @@ func RSAService(config *RSAConfig) (*RSAServiceInterface, error) {
 		return nil, err
 	}
 
-	// 尝试加载私钥（解密需要）
-	service.loadPrivateKey() // 忽略错误，私钥可选
+	// 加载额外接收方公钥
+	for _, path := range config.RecipientPaths {
+		recipients, err := readRSAPublicKeys(path)
+		if err != nil {
+			return nil, err
+		}
+		service.recipients = append(service.recipients, recipients...)
+	}
+
+	// 尝试加载私钥（解密需要），受口令保护的私钥在解密时才加载
+	if !IsProtectedKeyFile(config.PrivateKeyPath) {
+		service.loadPrivateKey() // 忽略错误，私钥可选
+	}
 
 	return service, nil
 }
 
 func (r *RSAServiceInterface) EncryptData(ctx context.Context, data io.Reader) (io.Reader, error) {
-	plaintext, err := io.ReadAll(data)
-	if err != nil {
-		return nil, errors.EncryptionFailed(constants.AlgorithmRSA, err)
-	}
+	// 新格式统一使用混合加密，封装的数据密钥记录在头部
+	return r.encryptLargeData(ctx, data)
+}
 
-	// 尝试直接RSA加密
-	ciphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, r.publicKey, plaintext, nil)
+func (r *RSAServiceInterface) DecryptData(ctx context.Context, data io.Reader) (io.Reader, error) {
+	header, body, err := format.Open(data)
 	if err != nil {
-		// 数据太大，使用混合加密
-		return r.encryptLargeData(plaintext)
+		return nil, errors.InvalidFormat("rsa encrypted data", err)
 	}
 
-	return strings.NewReader(string(ciphertext)), nil
-}
+	// 先核对密钥指纹，密钥不符时无需输入私钥口令
+	if header != nil && header.Algorithm == format.AlgorithmRSA {
+		if err := checkKeyFingerprint(header, constants.AlgorithmRSA, r.fingerprint()); err != nil {
+			return nil, err
+		}
+	}
 
-func (r *RSAServiceInterface) DecryptData(ctx context.Context, data io.Reader) (io.Reader, error) {
 	if r.privateKey == nil {
-		return nil, errors.KeyNotFound("private", r.config.PrivateKeyPath)
+		if err := r.loadPrivateKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	// 带头部的新格式无需猜测布局
+	if header != nil {
+		return r.decryptWithHeader(ctx, header, body)
 	}
 
-	ciphertext, err := io.ReadAll(data)
+	ciphertext, err := io.ReadAll(body)
 	if err != nil {
 		return nil, errors.DecryptionFailed(constants.AlgorithmRSA, err)
 	}
 
+	// 旧格式: 检查是否是混合加密
 	var plaintext []byte
-
-	// 检查是否是混合加密
 	if r.isHybridEncrypted(ciphertext) {
 		plaintext, err = r.decryptHybridData(ciphertext)
 	} else {
@@ -91,91 +160,247 @@ func (r *RSAServiceInterface) ValidateKeys() error {
 }
 
 func (r *RSAServiceInterface) loadPublicKey() error {
-	keyData, err := os.ReadFile(r.config.PublicKeyPath)
+	publicKeys, err := readRSAPublicKeys(r.config.PublicKeyPath)
 	if err != nil {
-		return errors.KeyNotFound("public", r.config.PublicKeyPath)
+		return err
+	}
+
+	r.publicKey = publicKeys[0]
+	return nil
+}
+
+// readRSAPublicKeys 读取 RSA 公钥：PEM 编码的单个公钥，或 OpenSSH 格式的 ssh-rsa 公钥行
+// （authorized_keys 中的每个 ssh-rsa 行各为一个公钥，其他类型的行被忽略）
+func readRSAPublicKeys(path string) ([]*rsa.PublicKey, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.KeyNotFound("public", path)
+	}
+
+	if isSSHPublicKey(keyData) {
+		sshKeys, err := parseSSHPublicKeys(keyData)
+		if err != nil {
+			return nil, errors.InvalidFormat("ssh public key", err)
+		}
+		var publicKeys []*rsa.PublicKey
+		for _, key := range sshKeys {
+			if rsaPublicKey, ok := key.(*rsa.PublicKey); ok {
+				publicKeys = append(publicKeys, rsaPublicKey)
+			}
+		}
+		if len(publicKeys) == 0 {
+			return nil, errors.InvalidFormat("public key", fmt.Errorf("no ssh-rsa public key found: %s", path))
+		}
+		return publicKeys, nil
 	}
 
 	block, _ := pem.Decode(keyData)
 	if block == nil {
-		return errors.InvalidFormat("pem", fmt.Errorf("invalid PEM format"))
+		return nil, errors.InvalidFormat("pem", fmt.Errorf("invalid PEM format: %s", path))
 	}
 
 	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		return errors.InvalidFormat("public key", err)
+		return nil, errors.InvalidFormat("public key", err)
 	}
 
 	rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
 	if !ok {
-		return errors.InvalidFormat("public key", fmt.Errorf("not an RSA public key"))
+		return nil, errors.InvalidFormat("public key", fmt.Errorf("not an RSA public key: %s", path))
 	}
+	return []*rsa.PublicKey{rsaPublicKey}, nil
+}
 
-	r.publicKey = rsaPublicKey
-	return nil
+// recipientKeys 返回需要封装数据密钥的全部公钥（自身公钥在前，去重）
+func (r *RSAServiceInterface) recipientKeys() []*rsa.PublicKey {
+	keys := []*rsa.PublicKey{r.publicKey}
+	for _, recipient := range r.recipients {
+		if !slices.ContainsFunc(keys, func(k *rsa.PublicKey) bool { return k.Equal(recipient) }) {
+			keys = append(keys, recipient)
+		}
+	}
+	return keys
 }
 
-func (r *RSAServiceInterface) loadPrivateKey() error {
-	keyData, err := os.ReadFile(r.config.PrivateKeyPath)
-	if err != nil {
-		return errors.KeyNotFound("private", r.config.PrivateKeyPath)
+// fingerprint 本地密钥指纹，私钥已加载时取私钥对应的公钥，否则取配置的公钥
+func (r *RSAServiceInterface) fingerprint() []byte {
+	if r.privateKey != nil {
+		return rsaFingerprint(&r.privateKey.PublicKey)
 	}
+	return rsaFingerprint(r.publicKey)
+}
 
-	block, _ := pem.Decode(keyData)
-	if block == nil {
-		return errors.InvalidFormat("pem", fmt.Errorf("invalid PEM format"))
+// unwrapKey 依次尝试头部中的封装密钥，返回私钥能解开的数据密钥
+func (r *RSAServiceInterface) unwrapKey(wrappedKeys [][]byte) ([]byte, error) {
+	var lastErr error
+	for _, wrapped := range wrappedKeys {
+		aesKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, r.privateKey, wrapped, nil)
+		if err == nil {
+			return aesKey, nil
+		}
+		lastErr = err
 	}
+	return nil, lastErr
+}
 
-	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+// loadPrivateKey 加载 PKCS#1、PKCS#8 或 OpenSSH 格式的私钥，受口令保护时通过回调获取口令
+func (r *RSAServiceInterface) loadPrivateKey() error {
+	key, err := readPrivateKey(r.config.PrivateKeyPath, r.config.Passphrase)
 	if err != nil {
-		// 尝试PKCS8格式
-		key, err2 := x509.ParsePKCS8PrivateKey(block.Bytes)
-		if err2 != nil {
-			return errors.InvalidFormat("private key", err)
-		}
+		return err
+	}
 
-		rsaPrivateKey, ok := key.(*rsa.PrivateKey)
-		if !ok {
-			return errors.InvalidFormat("private key", fmt.Errorf("not an RSA private key"))
-		}
-		privateKey = rsaPrivateKey
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return errors.InvalidFormat("private key", fmt.Errorf("not an RSA private key"))
 	}
 
 	r.privateKey = privateKey
 	return nil
 }
 
-func (r *RSAServiceInterface) encryptLargeData(plaintext []byte) (io.Reader, error) {
-	// 生成AES密钥
-	aesKey := make([]byte, r.config.AESKeySize)
+func (r *RSAServiceInterface) encryptLargeData(ctx context.Context, plaintext io.Reader) (io.Reader, error) {
+	header := format.FromContext(ctx, format.AlgorithmRSA)
+	header.KDF = format.KDFNone
+	keySize := selectAEAD(header, r.config.AESKeySize)
+
+	// 生成数据密钥
+	aesKey := make([]byte, keySize)
 	if _, err := io.ReadFull(rand.Reader, aesKey); err != nil {
 		return nil, errors.EncryptionFailed(constants.AlgorithmRSA, err)
 	}
+	defer func() {
+		for i := range aesKey {
+			aesKey[i] = 0
+		}
+	}()
+
+	// 为每个接收方分别用 RSA 封装同一个数据密钥
+	var wrappedKeys, fingerprints [][]byte
+	for _, publicKey := range r.recipientKeys() {
+		encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, aesKey, nil)
+		if err != nil {
+			return nil, errors.EncryptionFailed(constants.AlgorithmRSA, err)
+		}
+		wrappedKeys = append(wrappedKeys, encryptedKey)
+		fingerprints = append(fingerprints, rsaFingerprint(publicKey))
+	}
 
-	// RSA加密AES密钥
-	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, r.publicKey, aesKey, nil)
+	aead, err := newAEAD(header.AEAD, aesKey)
 	if err != nil {
 		return nil, errors.EncryptionFailed(constants.AlgorithmRSA, err)
 	}
 
-	// AES加密数据
-	ciphertext, err := encryptAESGCM(aesKey, plaintext)
+	// 封装后的数据密钥与接收方指纹记录在头部参数区，每个接收方各一项
+	header.Remove(format.TagWrappedKey)
+	header.Remove(format.TagKeyFingerprint)
+	for i, encryptedKey := range wrappedKeys {
+		header.Add(format.TagWrappedKey, encryptedKey)
+		header.Add(format.TagKeyFingerprint, fingerprints[i])
+	}
+	header.SetKeySize(keySize)
+
+	// 分块流式加密: [header][chunk...]
+	result, err := sealStream(ctx, header, aead, plaintext)
 	if err != nil {
 		return nil, errors.EncryptionFailed(constants.AlgorithmRSA, err)
 	}
+	return result, nil
+}
 
-	// 组合结果
-	result := make([]byte, 4+len(encryptedKey)+len(ciphertext))
-	binary.BigEndian.PutUint32(result[0:4], uint32(len(encryptedKey)))
-	copy(result[4:4+len(encryptedKey)], encryptedKey)
-	copy(result[4+len(encryptedKey):], ciphertext)
+// decryptWithHeader 解密带头部的混合加密数据
+func (r *RSAServiceInterface) decryptWithHeader(ctx context.Context, header *format.Header, body io.Reader) (io.Reader, error) {
+	// 分块流式格式
+	if header.IsStreaming() {
+		aead, err := r.openAEAD(header)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := openStream(ctx, header, aead, body)
+		if err != nil {
+			return nil, errors.InvalidFormat("rsa encrypted data", err)
+		}
+		return plaintext, nil
+	}
+
+	aesKey, err := r.headerKey(header)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range aesKey {
+			aesKey[i] = 0
+		}
+	}()
+
+	// 版本 1: 单块 AES-GCM
+	if header.AEAD != format.AEADAESGCM {
+		return nil, errors.InvalidFormat("rsa encrypted data",
+			fmt.Errorf("unsupported aead %s for format version %d", header.AEAD, header.Version))
+	}
+	ciphertext, err := io.ReadAll(body)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmRSA, err)
+	}
+
+	plaintext, err := decryptAESGCM(aesKey, ciphertext)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmRSA, err)
+	}
+	return bytes.NewReader(plaintext), nil
+}
+
+// openAEAD 校验头部与本地密钥，派生分块流式格式使用的 AEAD
+func (r *RSAServiceInterface) openAEAD(header *format.Header) (cipher.AEAD, error) {
+	// 随机访问时不经过 DecryptData，同样先核对指纹再加载私钥
+	if err := checkKeyFingerprint(header, constants.AlgorithmRSA, r.fingerprint()); err != nil {
+		return nil, err
+	}
+	if r.privateKey == nil {
+		if err := r.loadPrivateKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	aesKey, err := r.headerKey(header)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range aesKey {
+			aesKey[i] = 0
+		}
+	}()
 
-	// 清除内存中的密钥
-	for i := range aesKey {
-		aesKey[i] = 0
+	aead, err := newAEAD(header.AEAD, aesKey)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmRSA, err)
 	}
+	return aead, nil
+}
 
-	return strings.NewReader(string(result)), nil
+// headerKey 校验头部并恢复数据加密密钥
+func (r *RSAServiceInterface) headerKey(header *format.Header) ([]byte, error) {
+	if header.Algorithm != format.AlgorithmRSA {
+		return nil, errors.InvalidFormat("rsa encrypted data",
+			fmt.Errorf("file was encrypted with %s", header.Algorithm))
+	}
+	if !isSupportedAEAD(header.AEAD) {
+		return nil, errors.InvalidFormat("rsa encrypted data",
+			fmt.Errorf("unsupported aead: %d", header.AEAD))
+	}
+
+	wrappedKeys := header.GetAll(format.TagWrappedKey)
+	if len(wrappedKeys) == 0 {
+		return nil, errors.InvalidFormat("rsa encrypted data", fmt.Errorf("missing wrapped key"))
+	}
+
+	// RSA解密AES密钥，任一接收方的私钥均可
+	aesKey, err := r.unwrapKey(wrappedKeys)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmRSA, err)
+	}
+	return aesKey, nil
 }
 
 func (r *RSAServiceInterface) decryptHybridData(encryptedData []byte) ([]byte, error) {
@@ -216,3 +441,21 @@ func (r *RSAServiceInterface) isHybridEncrypted(data []byte) bool {
 	keyLen := binary.BigEndian.Uint32(data[0:4])
 	return keyLen > 0 && keyLen < uint32(len(data)) && keyLen <= 1024
 }
+
+// generateRSAKeys 生成 RSA 密钥对，私钥为 PKCS#1 PEM，公钥为 PKIX PEM
+func generateRSAKeys(params Parameters) (map[string][]byte, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, params.RSAKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		"public_key":  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes}),
+		"private_key": pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}),
+	}, nil
+}
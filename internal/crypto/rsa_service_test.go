@@ -0,0 +1,78 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+	"testing"
+)
+
+func TestRSAMultipleRecipients(t *testing.T) {
+	alice := writeTestKeys[RSAConfig](t, constants.AlgorithmRSA)
+	bob := writeTestKeys[RSAConfig](t, constants.AlgorithmRSA)
+	carol := writeTestKeys[RSAConfig](t, constants.AlgorithmRSA)
+
+	// alice 加密给自己和 bob（重复的接收方只封装一次）
+	sender := *alice
+	sender.RecipientPaths = []string{bob.PublicKeyPath, alice.PublicKeyPath}
+	encryptor, err := RSAService(&sender)
+	if err != nil {
+		t.Fatalf("RSAService failed: %v", err)
+	}
+
+	plaintext := bytes.Repeat([]byte("shared backup "), 10000)
+	encrypted, err := encryptor.EncryptData(context.Background(), bytes.NewReader(plaintext))
+	if err != nil {
+		t.Fatalf("EncryptData failed: %v", err)
+	}
+	sealed, _ := io.ReadAll(encrypted)
+
+	header, _, err := format.Open(bytes.NewReader(sealed))
+	if err != nil {
+		t.Fatalf("format.Open failed: %v", err)
+	}
+	if n := len(header.GetAll(format.TagWrappedKey)); n != 2 {
+		t.Errorf("expected 2 wrapped keys, got %d", n)
+	}
+	if n := len(header.GetAll(format.TagKeyFingerprint)); n != 2 {
+		t.Errorf("expected 2 key fingerprints, got %d", n)
+	}
+
+	tests := []struct {
+		name    string
+		config  *RSAConfig
+		wantErr bool
+	}{
+		{"sender", alice, false},
+		{"recipient", bob, false},
+		{"outsider", carol, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := RSAService(tt.config)
+			if err != nil {
+				t.Fatalf("RSAService failed: %v", err)
+			}
+			decrypted, err := service.DecryptData(context.Background(), bytes.NewReader(sealed))
+			var opened []byte
+			if err == nil {
+				opened, err = io.ReadAll(decrypted)
+			}
+			if tt.wantErr {
+				if cryptoErr, ok := err.(*errors.CryptoErrorInterface); !ok || cryptoErr.Code != errors.ErrWrongKey {
+					t.Errorf("expected wrong key error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decryption failed: %v", err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Error("round trip mismatch")
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Ed25519 签名密钥的文本编码前缀
+const (
+	Ed25519PublicKeyPrefix  = "ed25519:"
+	Ed25519PrivateKeyPrefix = "ED25519-SECRET:"
+)
+
+// signatureContext 签名消息的域分隔前缀
+const signatureContext = "hycrypt/v5 ed25519 signature"
+
+// signer 加密时对明文签名，解密时校验签名者是否受信任
+type signer struct {
+	privateKey ed25519.PrivateKey
+	trusted    []ed25519.PublicKey
+	config     *SigningConfig
+}
+
+func newSigner(config *SigningConfig) (*signer, error) {
+	s := &signer{config: config}
+
+	if data, err := os.ReadFile(config.PrivateKeyPath); err == nil {
+		privateKey, err := ParseEd25519PrivateKey(string(data))
+		if err != nil {
+			return nil, errors.InvalidFormat("signing key", err)
+		}
+		s.privateKey = privateKey
+		// 自身签名公钥默认受信任
+		s.trusted = append(s.trusted, privateKey.Public().(ed25519.PublicKey))
+	} else if config.Sign {
+		return nil, errors.KeyNotFound("signing", config.PrivateKeyPath)
+	}
+
+	for _, path := range config.TrustedKeyPaths {
+		keys, err := readTrustedSigners(path)
+		if err != nil {
+			return nil, err
+		}
+		s.trusted = append(s.trusted, keys...)
+	}
+
+	return s, nil
+}
+
+// prepare 在头部模板中标记签名并记录签名者公钥
+func (s *signer) prepare(template *format.Header) {
+	template.Flags |= format.FlagSigned
+	template.Set(format.TagSignerKey, s.privateKey.Public().(ed25519.PublicKey))
+}
+
+// signReader 返回在明文末尾追加签名的读取器，需在 prepare 之后调用
+// 签名覆盖加密服务写入的最终头部，读到明文结尾前须通过 bind 传入
+func (s *signer) signReader(src io.Reader) *signingReader {
+	return &signingReader{
+		src:  src,
+		hash: sha512.New(),
+		key:  s.privateKey,
+	}
+}
+
+// verify 检查签名者是否受信任，返回剥离签名并在读取结束时验签的明文读取器及签名者公钥
+func (s *signer) verify(header *format.Header, plaintext io.Reader) (io.Reader, string, error) {
+	raw, ok := header.Get(format.TagSignerKey)
+	if !ok || len(raw) != ed25519.PublicKeySize {
+		return nil, "", errors.SignatureInvalid("missing signer key", nil)
+	}
+	signerKey := ed25519.PublicKey(raw)
+	signerText := EncodeEd25519PublicKey(signerKey)
+
+	if !slices.ContainsFunc(s.trusted, func(k ed25519.PublicKey) bool { return k.Equal(signerKey) }) {
+		return nil, "", errors.SignatureInvalid("untrusted signer", nil).WithContext("signer", signerText)
+	}
+
+	prefix, err := signaturePrefix(header)
+	if err != nil {
+		return nil, "", errors.InvalidFormat("hycrypt header", err)
+	}
+
+	return &verifyingReader{
+		src:    bufio.NewReader(plaintext),
+		hash:   sha512.New(),
+		key:    signerKey,
+		prefix: prefix,
+		signer: signerText,
+	}, signerText, nil
+}
+
+// signaturePrefix 签名消息前缀 context | 关联数据，完整消息在其后追加明文的 SHA-512 摘要
+// 关联数据覆盖算法、标志位、文件名、数据加密算法、签名者等认证字段，但不含封装的数据密钥与密钥指纹，
+// 因此签名不绑定接收方：rekey 或他人把数据密钥另行封装给其他接收方后签名仍然有效
+func signaturePrefix(header *format.Header) ([]byte, error) {
+	aad, err := header.AssociatedData()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(signatureContext), aad...), nil
+}
+
+// signingReader 透传明文并计算摘要，读取结束时追加签名
+type signingReader struct {
+	src     io.Reader
+	hash    hash.Hash
+	key     ed25519.PrivateKey
+	header  *format.Header
+	trailer []byte
+	done    bool
+}
+
+// bind 记录加密服务写入的最终头部
+func (r *signingReader) bind(header *format.Header) {
+	r.header = header
+}
+
+func (r *signingReader) Read(p []byte) (int, error) {
+	if !r.done {
+		n, err := r.src.Read(p)
+		r.hash.Write(p[:n])
+		if err != io.EOF {
+			return n, err
+		}
+
+		if r.header == nil {
+			return n, fmt.Errorf("signature requires the sealed header")
+		}
+		prefix, err := signaturePrefix(r.header)
+		if err != nil {
+			return n, err
+		}
+		r.done = true
+		r.trailer = ed25519.Sign(r.key, r.hash.Sum(prefix))
+		if n > 0 {
+			return n, nil
+		}
+	}
+
+	if len(r.trailer) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.trailer)
+	r.trailer = r.trailer[n:]
+	return n, nil
+}
+
+// verifyingReader 保留末尾签名长度的数据，读取结束时验签
+type verifyingReader struct {
+	src     io.Reader
+	hash    hash.Hash
+	key     ed25519.PublicKey
+	prefix  []byte
+	signer  string
+	pending []byte
+	scratch [32 * 1024]byte
+	eof     bool
+	err     error
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	for {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		// 输出确定不属于签名的部分
+		if avail := len(r.pending) - ed25519.SignatureSize; avail > 0 {
+			n := copy(p, r.pending[:avail])
+			r.hash.Write(r.pending[:n])
+			r.pending = r.pending[n:]
+			return n, nil
+		}
+
+		if r.eof {
+			switch {
+			case len(r.pending) < ed25519.SignatureSize:
+				r.err = errors.SignatureInvalid("signature missing", nil).WithContext("signer", r.signer)
+			case !ed25519.Verify(r.key, r.hash.Sum(r.prefix), r.pending):
+				r.err = errors.SignatureInvalid("signature verification failed", nil).WithContext("signer", r.signer)
+			default:
+				r.err = io.EOF
+			}
+			continue
+		}
+
+		n, err := r.src.Read(r.scratch[:])
+		r.pending = append(r.pending, r.scratch[:n]...)
+		if err == io.EOF {
+			r.eof = true
+		} else if err != nil {
+			r.err = err
+		}
+	}
+}
+
+// readTrustedSigners 读取受信任签名者公钥文件，每行一个公钥，# 开头为注释
+func readTrustedSigners(path string) ([]ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.KeyNotFound("trusted signer", path)
+	}
+
+	var keys []ed25519.PublicKey
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := ParseEd25519PublicKey(line)
+		if err != nil {
+			return nil, errors.InvalidFormat("trusted signer", fmt.Errorf("%s: %w", path, err))
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// GenerateEd25519Key 生成新的 Ed25519 签名私钥
+func GenerateEd25519Key() (ed25519.PrivateKey, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	return privateKey, err
+}
+
+// EncodeEd25519PublicKey 将签名公钥编码为短文本，便于分享
+func EncodeEd25519PublicKey(key ed25519.PublicKey) string {
+	return Ed25519PublicKeyPrefix + base64.RawURLEncoding.EncodeToString(key)
+}
+
+// ParseEd25519PublicKey 解析文本编码的签名公钥
+func ParseEd25519PublicKey(s string) (ed25519.PublicKey, error) {
+	raw, err := decodeTextKey(s, Ed25519PublicKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// EncodeEd25519PrivateKey 将签名私钥（种子）编码为文本
+func EncodeEd25519PrivateKey(key ed25519.PrivateKey) string {
+	return Ed25519PrivateKeyPrefix + base64.RawURLEncoding.EncodeToString(key.Seed())
+}
+
+// ParseEd25519PrivateKey 解析文本编码的签名私钥
+func ParseEd25519PrivateKey(s string) (ed25519.PrivateKey, error) {
+	seed, err := decodeTextKey(s, Ed25519PrivateKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer clearBytes(seed)
+	return ed25519.NewKeyFromSeed(seed), nil
+}
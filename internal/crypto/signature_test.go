@@ -0,0 +1,87 @@
+package crypto
+
+import (
+	"bytes"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestSignatureVerification(t *testing.T) {
+	sender, err := newSigner(writeTestSigningKey(t))
+	if err != nil {
+		t.Fatalf("newSigner failed: %v", err)
+	}
+	stranger, err := newSigner(writeTestSigningKey(t))
+	if err != nil {
+		t.Fatalf("newSigner failed: %v", err)
+	}
+
+	plaintext := bytes.Repeat([]byte("signed "), 20000)
+	header := format.New(format.AlgorithmKMAC)
+	header.Set(format.TagFileName, []byte("report.pdf"))
+	sender.prepare(header)
+	signing := sender.signReader(bytes.NewReader(plaintext))
+	signing.bind(header)
+	signed, err := io.ReadAll(signing)
+	if err != nil {
+		t.Fatalf("signing failed: %v", err)
+	}
+
+	tampered := bytes.Clone(signed)
+	tampered[10] ^= 1
+
+	// 签名覆盖全部认证字段: 换用其他头部重新加密同一明文时验签失败
+	withHeader := func(change func(h *format.Header)) *format.Header {
+		h := *header
+		h.Fields = slices.Clone(header.Fields)
+		change(&h)
+		return &h
+	}
+	otherAlgorithm := withHeader(func(h *format.Header) { h.Algorithm = format.AlgorithmRSA })
+	otherName := withHeader(func(h *format.Header) { h.Set(format.TagFileName, []byte("invoice.pdf")) })
+	otherAEAD := withHeader(func(h *format.Header) { h.AEAD = format.AEADXChaCha20Poly1305 })
+	// 签名不绑定接收方: 接收方字段不计入关联数据，rekey 之后签名仍然有效
+	otherRecipient := withHeader(func(h *format.Header) { h.Set(format.TagKeyFingerprint, []byte("other recipient")) })
+
+	tests := []struct {
+		name    string
+		verify  *signer
+		header  *format.Header
+		data    []byte
+		wantErr bool
+	}{
+		{"valid", sender, header, signed, false},
+		{"tampered plaintext", sender, header, tampered, true},
+		{"truncated", sender, header, signed[:len(signed)-1], true},
+		{"algorithm changed", sender, otherAlgorithm, signed, true},
+		{"file name changed", sender, otherName, signed, true},
+		{"aead changed", sender, otherAEAD, signed, true},
+		{"recipient changed", sender, otherRecipient, signed, false},
+		{"untrusted signer", stranger, header, signed, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, _, err := tt.verify.verify(tt.header, bytes.NewReader(tt.data))
+			var opened []byte
+			if err == nil {
+				opened, err = io.ReadAll(reader)
+			}
+			if tt.wantErr {
+				cryptoErr, ok := err.(*errors.CryptoErrorInterface)
+				if !ok || cryptoErr.Code != errors.ErrSignatureInvalid {
+					t.Errorf("expected %s error, got %v", errors.ErrSignatureInvalid, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verification failed: %v", err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Error("plaintext mismatch after stripping signature")
+			}
+		})
+	}
+}
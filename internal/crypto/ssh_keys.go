@@ -0,0 +1,144 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"hycrypt/internal/errors"
+	"math/big"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// PassphraseFunc 读取受口令保护的私钥文件的口令
+type PassphraseFunc func(path string) ([]byte, error)
+
+// isSSHPublicKey 检查数据是否为 OpenSSH 公钥行（id_*.pub 或 authorized_keys）
+func isSSHPublicKey(data []byte) bool {
+	_, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	return err == nil
+}
+
+// parseSSHPublicKeys 解析 authorized_keys 格式的公钥，每行一个，忽略空行和注释
+// 证书、安全密钥（sk-*）等无法直接用于加密的条目跳过，没有可用公钥时报错
+func parseSSHPublicKeys(data []byte) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	var skipped []string
+	for len(bytes.TrimSpace(data)) > 0 {
+		publicKey, _, _, rest, err := ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			return nil, err
+		}
+		if cryptoKey, ok := publicKey.(ssh.CryptoPublicKey); ok {
+			keys = append(keys, cryptoKey.CryptoPublicKey())
+		} else {
+			skipped = append(skipped, publicKey.Type())
+		}
+		data = rest
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no usable ssh public key, unsupported types: %s", strings.Join(skipped, ", "))
+	}
+	return keys, nil
+}
+
+// readPrivateKey 读取 PEM 私钥（PKCS#1、PKCS#8、加密 PKCS#8 或 OpenSSH），受口令保护时通过 passphrase 获取口令
+func readPrivateKey(path string, passphrase PassphraseFunc) (crypto.PrivateKey, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.KeyNotFound("private", path)
+	}
+
+	if block, _ := pem.Decode(keyData); block != nil && block.Type == EncryptedPrivateKeyPEMType {
+		return decryptPKCS8PrivateKey(block, path, passphrase)
+	}
+
+	key, err := ssh.ParseRawPrivateKey(keyData)
+	if _, ok := err.(*ssh.PassphraseMissingError); ok {
+		if passphrase == nil {
+			return nil, errors.InvalidConfig(fmt.Sprintf("private key is passphrase protected: %s", path), nil)
+		}
+		secret, err := passphrase(path)
+		if err != nil {
+			return nil, err
+		}
+		defer clearBytes(secret)
+
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(keyData, secret)
+		if err == x509.IncorrectPasswordError {
+			return nil, incorrectPassphrase(path, err)
+		}
+		if err != nil {
+			return nil, errors.InvalidFormat("private key", err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, errors.InvalidFormat("private key", err)
+	}
+	return key, nil
+}
+
+// asEd25519PrivateKey 统一 ssh 与 x509 返回的 Ed25519 私钥形式
+func asEd25519PrivateKey(key crypto.PrivateKey) (ed25519.PrivateKey, bool) {
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return k, true
+	case *ed25519.PrivateKey:
+		return *k, true
+	}
+	return nil, false
+}
+
+// curve25519P 域素数 2^255 - 19
+var curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// ed25519PublicToX25519 将 Ed25519 公钥映射为等价的 X25519 公钥: u = (1 + y) / (1 - y)
+func ed25519PublicToX25519(publicKey ed25519.PublicKey) (*ecdh.PublicKey, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key length: %d", len(publicKey))
+	}
+
+	// 小端编码的 y 坐标，最高位是 x 的符号位
+	encoded := make([]byte, ed25519.PublicKeySize)
+	for i, b := range publicKey {
+		encoded[len(encoded)-1-i] = b
+	}
+	encoded[0] &= 0x7f
+	y := new(big.Int).SetBytes(encoded)
+	if y.Cmp(curve25519P) >= 0 {
+		return nil, fmt.Errorf("invalid ed25519 public key")
+	}
+
+	one := big.NewInt(1)
+	denominator := new(big.Int).Sub(one, y)
+	denominator.Mod(denominator, curve25519P)
+	if denominator.Sign() == 0 {
+		return nil, fmt.Errorf("invalid ed25519 public key")
+	}
+
+	u := new(big.Int).Add(one, y)
+	u.Mul(u, denominator.ModInverse(denominator, curve25519P))
+	u.Mod(u, curve25519P)
+
+	raw := make([]byte, 32)
+	u.FillBytes(raw)
+	for i, j := 0, len(raw)-1; i < j; i, j = i+1, j-1 {
+		raw[i], raw[j] = raw[j], raw[i]
+	}
+	return ecdh.X25519().NewPublicKey(raw)
+}
+
+// ed25519PrivateToX25519 将 Ed25519 私钥映射为 X25519 私钥（SHA-512(seed) 的前 32 字节）
+func ed25519PrivateToX25519(privateKey ed25519.PrivateKey) (*ecdh.PrivateKey, error) {
+	digest := sha512.Sum512(privateKey.Seed())
+	defer clearBytes(digest[:])
+	return ecdh.X25519().NewPrivateKey(digest[:32])
+}
@@ -0,0 +1,138 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"os"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHKeyRoundTrip(t *testing.T) {
+	_, ed25519Key, _ := ed25519.GenerateKey(rand.Reader)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		key        any
+		passphrase string
+	}{
+		{"ssh-ed25519", &ed25519Key, ""},
+		{"ssh-ed25519 protected", &ed25519Key, "hunter2"},
+		{"ssh-rsa", rsaKey, ""},
+		{"ssh-rsa protected", rsaKey, "hunter2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			publicPath, privatePath := writeTestSSHKeys(t, tc.key, tc.passphrase)
+
+			prompts := 0
+			secret := tc.passphrase
+			passphrase := func(string) ([]byte, error) {
+				prompts++
+				return []byte(secret), nil
+			}
+
+			var service CryptoService
+			if _, ok := tc.key.(*rsa.PrivateKey); ok {
+				service, err = RSAService(&RSAConfig{PublicKeyPath: publicPath, PrivateKeyPath: privatePath, AESKeySize: 32, Passphrase: passphrase})
+			} else {
+				service, err = X25519Service(&X25519Config{PublicKeyPath: publicPath, PrivateKeyPath: privatePath, Passphrase: passphrase})
+			}
+			if err != nil {
+				t.Fatalf("service failed: %v", err)
+			}
+
+			encrypted, err := service.EncryptData(context.Background(), bytes.NewReader([]byte("ssh secret")))
+			if err != nil {
+				t.Fatalf("EncryptData failed: %v", err)
+			}
+			sealed, _ := io.ReadAll(encrypted)
+
+			// 加密只需要公钥，不应提示输入私钥口令
+			if prompts != 0 {
+				t.Fatalf("passphrase requested during encryption")
+			}
+
+			if tc.passphrase != "" {
+				secret = "wrong"
+				if _, err := service.DecryptData(context.Background(), bytes.NewReader(sealed)); err == nil {
+					t.Fatal("wrong passphrase accepted")
+				}
+				secret = tc.passphrase
+			}
+
+			decrypted, err := service.DecryptData(context.Background(), bytes.NewReader(sealed))
+			if err != nil {
+				t.Fatalf("DecryptData failed: %v", err)
+			}
+			opened, _ := io.ReadAll(decrypted)
+			if string(opened) != "ssh secret" {
+				t.Errorf("round trip mismatch: %q", opened)
+			}
+		})
+	}
+}
+
+func TestX25519RejectsMultipleSSHKeys(t *testing.T) {
+	_, first, _ := ed25519.GenerateKey(rand.Reader)
+	_, second, _ := ed25519.GenerateKey(rand.Reader)
+	publicPath, privatePath := writeTestSSHKeys(t, &first, "")
+
+	// 追加第二个公钥行后不能只静默使用第一个
+	signer, _ := ssh.NewSignerFromKey(&second)
+	keyData, _ := os.ReadFile(publicPath)
+	os.WriteFile(publicPath, append(keyData, ssh.MarshalAuthorizedKey(signer.PublicKey())...), 0644)
+
+	if _, err := X25519Service(&X25519Config{PublicKeyPath: publicPath, PrivateKeyPath: privatePath}); err == nil {
+		t.Fatal("authorized_keys file with two keys accepted")
+	}
+}
+
+func TestParseSSHPublicKeysSkipsUnsupported(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	signer, _ := ssh.NewSignerFromKey(key)
+
+	// 用户证书不是可直接加密的公钥
+	cert := &ssh.Certificate{Key: signer.PublicKey(), CertType: ssh.UserCert, ValidBefore: ssh.CertTimeInfinity}
+	if err := cert.SignCert(rand.Reader, signer); err != nil {
+		t.Fatalf("SignCert failed: %v", err)
+	}
+	certLine := ssh.MarshalAuthorizedKey(cert)
+	keyLine := ssh.MarshalAuthorizedKey(signer.PublicKey())
+
+	keys, err := parseSSHPublicKeys(append(bytes.Clone(certLine), keyLine...))
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("parseSSHPublicKeys = %d keys, %v; expected certificate skipped", len(keys), err)
+	}
+	if _, err := parseSSHPublicKeys(certLine); err == nil {
+		t.Error("expected error when no usable key remains")
+	}
+}
+
+func TestEd25519ToX25519(t *testing.T) {
+	// 公钥映射与私钥映射必须得到同一个 X25519 公钥
+	for range 8 {
+		publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+		mapped, err := ed25519PublicToX25519(publicKey)
+		if err != nil {
+			t.Fatalf("ed25519PublicToX25519 failed: %v", err)
+		}
+		x25519Key, err := ed25519PrivateToX25519(privateKey)
+		if err != nil {
+			t.Fatalf("ed25519PrivateToX25519 failed: %v", err)
+		}
+		if !mapped.Equal(x25519Key.PublicKey()) {
+			t.Fatal("public and private key mappings disagree")
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// 流式加密参数
+const (
+	// DefaultChunkSize 默认明文块大小
+	DefaultChunkSize = 64 * 1024
+
+	// MaxChunkSize 允许的最大明文块大小，防止恶意头部导致超大内存分配
+	MaxChunkSize = 16 * 1024 * 1024
+
+	// MaxWorkers 分块并发加解密的最大块数，每块额外占用约两倍块大小的内存
+	MaxWorkers = 256
+
+	// streamNonceSuffixSize nonce 后缀长度: nonce = prefix | counter(4) | lastFlag(1)
+	streamNonceSuffixSize = 4 + 1
+)
+
+// streamPrefixSize 返回 nonce 前缀长度（96 位 nonce 为 7 字节，192 位 nonce 为 19 字节）
+func streamPrefixSize(aead cipher.AEAD) int {
+	return aead.NonceSize() - streamNonceSuffixSize
+}
+
+// sealStream 将流式参数写入头部，返回 [header][chunks] 形式的密文读取器
+// 头部记录了填充方案时，填充追加在明文末尾并一同分块加密；分块并发数取自 ctx
+func sealStream(ctx context.Context, header *format.Header, aead cipher.AEAD, src io.Reader) (io.Reader, error) {
+	if padding := header.Padding(); padding != format.PaddingNone {
+		if _, err := paddedSize(padding, 0); err != nil {
+			return nil, err
+		}
+		src = newPadReader(src, padding)
+	}
+
+	prefix := make([]byte, streamPrefixSize(aead))
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce prefix: %w", err)
+	}
+
+	header.SetChunkSize(DefaultChunkSize)
+	header.Set(format.TagNoncePrefix, prefix)
+
+	headerBytes, err := header.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	aad, err := header.AssociatedData()
+	if err != nil {
+		return nil, err
+	}
+	if bind, ok := ctx.Value(sealedHeaderKey{}).(func(*format.Header)); ok {
+		bind(header)
+	}
+
+	return io.MultiReader(bytes.NewReader(headerBytes), newStreamEncryptReader(aead, prefix, aad, DefaultChunkSize, streamWorkers(ctx), src)), nil
+}
+
+// openStream 根据头部中的流式参数返回明文读取器，分块并发数取自 ctx
+func openStream(ctx context.Context, header *format.Header, aead cipher.AEAD, body io.Reader) (io.Reader, error) {
+	chunkSize, prefix, aad, err := streamParams(header, aead)
+	if err != nil {
+		return nil, err
+	}
+	padding, err := streamPadding(header)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := io.Reader(newStreamDecryptReader(aead, prefix, aad, chunkSize, streamWorkers(ctx), body))
+	if padding != format.PaddingNone {
+		plaintext = newUnpadReader(plaintext)
+	}
+	return plaintext, nil
+}
+
+// streamParams 读取并校验头部中的分块大小、nonce 前缀和关联数据
+func streamParams(header *format.Header, aead cipher.AEAD) (int, []byte, []byte, error) {
+	chunkSize := header.ChunkSize()
+	if chunkSize <= 0 || chunkSize > MaxChunkSize {
+		return 0, nil, nil, fmt.Errorf("invalid chunk size: %d", chunkSize)
+	}
+
+	prefix, ok := header.Get(format.TagNoncePrefix)
+	if !ok || len(prefix) != streamPrefixSize(aead) {
+		return 0, nil, nil, fmt.Errorf("missing or invalid nonce prefix")
+	}
+
+	aad, err := header.AssociatedData()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return chunkSize, prefix, aad, nil
+}
+
+// streamPadding 校验头部记录的填充方案
+func streamPadding(header *format.Header) (format.PaddingID, error) {
+	value, ok := header.Get(format.TagPadding)
+	if !ok {
+		return format.PaddingNone, nil
+	}
+	if _, err := paddedSize(header.Padding(), 0); err != nil || len(value) != 1 {
+		return format.PaddingNone, fmt.Errorf("invalid padding field: %x", value)
+	}
+	return header.Padding(), nil
+}
+
+// streamNonce 计算第 counter 个块的 nonce
+func streamNonce(dst, prefix []byte, counter uint32, last bool) []byte {
+	dst = append(dst[:0], prefix...)
+	dst = binary.BigEndian.AppendUint32(dst, counter)
+	if last {
+		return append(dst, 1)
+	}
+	return append(dst, 0)
+}
+
+// streamWorkers 限定上下文中的分块并发数，未指定或不大于 0 时使用 GOMAXPROCS
+func streamWorkers(ctx context.Context) int {
+	workers, _ := ctx.Value(workersKey{}).(int)
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return min(workers, MaxWorkers)
+}
+
+// WithWorkers 返回指定分块加解密并发数的上下文，workers 不大于 0 时使用 GOMAXPROCS
+func WithWorkers(ctx context.Context, workers int) context.Context {
+	return context.WithValue(ctx, workersKey{}, workers)
+}
+
+type workersKey struct{}
+
+// withSealedHeader 返回在 sealStream 写出最终头部时调用 bind 的上下文，签名需覆盖该头部
+func withSealedHeader(ctx context.Context, bind func(*format.Header)) context.Context {
+	return context.WithValue(ctx, sealedHeaderKey{}, bind)
+}
+
+type sealedHeaderKey struct{}
+
+// parallelChunks 并发处理 n 个分块，n 为 1 时在当前 goroutine 中处理
+func parallelChunks(n int, process func(i int)) {
+	if n == 1 {
+		process(0)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() { process(i) })
+	}
+	wg.Wait()
+}
+
+// streamEncryptReader 分块加密读取器
+//
+// 明文按固定大小分块，每块独立使用 AEAD 加密。最后一块在 nonce 中带有结束标志，
+// 解密时据此发现截断或追加的数据。每次读取至多 workers 块并发加密后按顺序输出，
+// nonce 只取决于块序号，输出与并发数无关；内存占用不超过 workers 块明文与密文。
+type streamEncryptReader struct {
+	aead      cipher.AEAD
+	prefix    []byte
+	aad       []byte
+	src       *bufio.Reader
+	chunkSize int
+	workers   int
+	plain     []byte
+	sizes     []int
+	nonces    [][]byte
+	out       []byte
+	pending   []byte
+	counter   uint32
+	done      bool
+	err       error
+}
+
+// newStreamEncryptReader 创建分块加密读取器，aad 为每个分块的关联数据，workers 为并发加密的块数
+func newStreamEncryptReader(aead cipher.AEAD, prefix, aad []byte, chunkSize, workers int, src io.Reader) *streamEncryptReader {
+	workers = max(workers, 1)
+	return &streamEncryptReader{
+		aead:      aead,
+		prefix:    prefix,
+		aad:       aad,
+		src:       bufio.NewReader(src),
+		chunkSize: chunkSize,
+		workers:   workers,
+		plain:     make([]byte, workers*chunkSize),
+		nonces:    make([][]byte, workers),
+		out:       make([]byte, workers*(chunkSize+aead.Overhead())),
+	}
+}
+
+func (s *streamEncryptReader) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		if s.done {
+			return 0, io.EOF
+		}
+		s.err = s.sealBatch()
+	}
+
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// sealBatch 读取至多 workers 个明文块并发加密，密文按块序号连续存放
+func (s *streamEncryptReader) sealBatch() error {
+	s.sizes = s.sizes[:0]
+	last := false
+	for len(s.sizes) < s.workers && !last {
+		offset := len(s.sizes) * s.chunkSize
+		n, err := io.ReadFull(s.src, s.plain[offset:offset+s.chunkSize])
+		switch err {
+		case nil:
+			// 整块读满时预读一个字节判断是否已到结尾
+			if _, peekErr := s.src.Peek(1); peekErr == io.EOF {
+				last = true
+			} else if peekErr != nil {
+				return peekErr
+			}
+		case io.EOF, io.ErrUnexpectedEOF:
+			last = true
+		default:
+			return err
+		}
+
+		if !last && s.counter+uint32(len(s.sizes)) == ^uint32(0) {
+			return fmt.Errorf("stream too long: chunk counter overflow")
+		}
+		s.sizes = append(s.sizes, n)
+	}
+
+	sealedSize := s.chunkSize + s.aead.Overhead()
+	parallelChunks(len(s.sizes), func(i int) {
+		offset := i * s.chunkSize
+		s.nonces[i] = streamNonce(s.nonces[i], s.prefix, s.counter+uint32(i), last && i == len(s.sizes)-1)
+		s.aead.Seal(s.out[i*sealedSize:i*sealedSize:(i+1)*sealedSize], s.nonces[i], s.plain[offset:offset+s.sizes[i]], s.aad)
+	})
+
+	// 只有最后一块可能不满，密文连续
+	s.pending = s.out[:(len(s.sizes)-1)*sealedSize+s.sizes[len(s.sizes)-1]+s.aead.Overhead()]
+	s.counter += uint32(len(s.sizes))
+	s.done = last
+	return nil
+}
+
+// streamDecryptReader 分块解密读取器，每次读取至多 workers 块并发解密后按顺序输出
+type streamDecryptReader struct {
+	aead      cipher.AEAD
+	prefix    []byte
+	aad       []byte
+	src       *bufio.Reader
+	chunkSize int
+	workers   int
+	sealed    []byte
+	sizes     []int
+	nonces    [][]byte
+	errs      []error
+	out       []byte
+	pending   []byte
+	counter   uint32
+	done      bool
+	err       error
+}
+
+// newStreamDecryptReader 创建分块解密读取器，aad 必须与加密时一致，workers 为并发解密的块数
+func newStreamDecryptReader(aead cipher.AEAD, prefix, aad []byte, chunkSize, workers int, src io.Reader) *streamDecryptReader {
+	workers = max(workers, 1)
+	return &streamDecryptReader{
+		aead:      aead,
+		prefix:    prefix,
+		aad:       aad,
+		src:       bufio.NewReader(src),
+		chunkSize: chunkSize,
+		workers:   workers,
+		sealed:    make([]byte, workers*(chunkSize+aead.Overhead())),
+		nonces:    make([][]byte, workers),
+		errs:      make([]error, workers),
+		out:       make([]byte, workers*chunkSize),
+	}
+}
+
+func (s *streamDecryptReader) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		if s.done {
+			return 0, io.EOF
+		}
+		s.err = s.openBatch()
+	}
+
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// openBatch 读取至多 workers 个密文块并发解密
+//
+// 某块认证失败时，先输出它之前的明文，再返回该块的错误，与逐块解密的行为一致。
+func (s *streamDecryptReader) openBatch() error {
+	sealedSize := s.chunkSize + s.aead.Overhead()
+	s.sizes = s.sizes[:0]
+	last := false
+	for len(s.sizes) < s.workers && !last {
+		offset := len(s.sizes) * sealedSize
+		n, err := io.ReadFull(s.src, s.sealed[offset:offset+sealedSize])
+		switch err {
+		case nil:
+			if _, peekErr := s.src.Peek(1); peekErr == io.EOF {
+				last = true
+			} else if peekErr != nil {
+				return peekErr
+			}
+		case io.EOF:
+			// 在结束块之前数据就已耗尽
+			return errors.InvalidFormat("encrypted stream", fmt.Errorf("truncated before final chunk"))
+		case io.ErrUnexpectedEOF:
+			last = true
+		default:
+			return err
+		}
+
+		counter := s.counter + uint32(len(s.sizes))
+		if n < s.aead.Overhead() {
+			return fmt.Errorf("encrypted chunk %d too short", counter)
+		}
+		if !last && counter == ^uint32(0) {
+			return fmt.Errorf("stream too long: chunk counter overflow")
+		}
+		s.sizes = append(s.sizes, n)
+	}
+
+	parallelChunks(len(s.sizes), func(i int) {
+		counter := s.counter + uint32(i)
+		final := last && i == len(s.sizes)-1
+		offset := i * sealedSize
+		s.nonces[i] = streamNonce(s.nonces[i], s.prefix, counter, final)
+		_, err := s.aead.Open(s.out[i*s.chunkSize:i*s.chunkSize:(i+1)*s.chunkSize], s.nonces[i], s.sealed[offset:offset+s.sizes[i]], s.aad)
+		switch {
+		case err == nil:
+			s.errs[i] = nil
+		case final:
+			s.errs[i] = errors.InvalidFormat("encrypted stream",
+				fmt.Errorf("chunk %d authentication failed (stream may be truncated or header tampered): %w", counter, err))
+		default:
+			s.errs[i] = errors.InvalidFormat("encrypted stream",
+				fmt.Errorf("chunk %d authentication failed (header or data tampered): %w", counter, err))
+		}
+	})
+
+	// 只有最后一块可能不满，明文连续
+	for i, err := range s.errs[:len(s.sizes)] {
+		if err != nil {
+			s.pending = s.out[:i*s.chunkSize]
+			return err
+		}
+	}
+	s.pending = s.out[:(len(s.sizes)-1)*s.chunkSize+s.sizes[len(s.sizes)-1]-s.aead.Overhead()]
+	s.counter += uint32(len(s.sizes))
+	s.done = last
+	return nil
+}
@@ -0,0 +1,182 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func sealTestStream(t *testing.T, plaintext []byte, chunkSize int) ([]byte, []byte, []byte) {
+	t.Helper()
+
+	key := make([]byte, 32)
+	rand.Read(key)
+
+	aead, err := newAESGCM(key)
+	if err != nil {
+		t.Fatalf("newAESGCM failed: %v", err)
+	}
+
+	prefix := make([]byte, streamPrefixSize(aead))
+	rand.Read(prefix)
+
+	sealed, err := io.ReadAll(newStreamEncryptReader(aead, prefix, nil, chunkSize, 1, bytes.NewReader(plaintext)))
+	if err != nil {
+		t.Fatalf("stream encryption failed: %v", err)
+	}
+	return key, prefix, sealed
+}
+
+func openTestStream(key, prefix, sealed []byte, chunkSize int) ([]byte, error) {
+	aead, _ := newAESGCM(key)
+	return io.ReadAll(newStreamDecryptReader(aead, prefix, nil, chunkSize, 1, bytes.NewReader(sealed)))
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	const chunkSize = 16
+
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, chunkSize * 3} {
+		plaintext := make([]byte, size)
+		rand.Read(plaintext)
+
+		key, prefix, sealed := sealTestStream(t, plaintext, chunkSize)
+		opened, err := openTestStream(key, prefix, sealed, chunkSize)
+		if err != nil {
+			t.Fatalf("size %d: stream decryption failed: %v", size, err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Errorf("size %d: round trip mismatch", size)
+		}
+	}
+}
+
+func TestStreamDetectsTampering(t *testing.T) {
+	const chunkSize = 16
+	sealedChunk := chunkSize + 16
+
+	plaintext := bytes.Repeat([]byte("x"), chunkSize*3)
+	key, prefix, sealed := sealTestStream(t, plaintext, chunkSize)
+
+	flipped := append([]byte(nil), sealed...)
+	flipped[sealedChunk+1] ^= 0x01
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated at chunk boundary", sealed[:sealedChunk*2]},
+		{"truncated mid chunk", sealed[:len(sealed)-5]},
+		{"trailing data", append(append([]byte(nil), sealed...), 0x00)},
+		{"modified chunk", flipped},
+		{"empty", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := openTestStream(key, prefix, tc.data, chunkSize); err == nil {
+				t.Errorf("Expected decryption to fail for %s", tc.name)
+			}
+		})
+	}
+}
+
+func TestStreamAuthenticatesHeader(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	aead, _ := newAESGCM(key)
+
+	header := format.New(format.AlgorithmKMAC)
+	header.AEAD = format.AEADAESGCM
+	header.Set(format.TagFileName, []byte("report.pdf"))
+	sealed, err := sealStream(context.Background(), header, aead, bytes.NewReader([]byte("metadata bound")))
+	if err != nil {
+		t.Fatalf("sealStream failed: %v", err)
+	}
+	data, _ := io.ReadAll(sealed)
+
+	testCases := []struct {
+		name   string
+		tamper func(h *format.Header)
+	}{
+		{"unchanged", func(h *format.Header) {}},
+		{"algorithm", func(h *format.Header) { h.Algorithm = format.AlgorithmRSA }},
+		{"version", func(h *format.Header) { h.Version = format.Version3 }},
+		{"file name", func(h *format.Header) { h.Set(format.TagFileName, []byte("other.pdf")) }},
+		{"directory flag", func(h *format.Header) { h.Flags |= format.FlagDirectory }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, body, err := format.Open(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("format.Open failed: %v", err)
+			}
+			tc.tamper(h)
+
+			plaintext, err := openStream(context.Background(), h, aead, body)
+			if err == nil {
+				_, err = io.ReadAll(plaintext)
+			}
+			if tc.name == "unchanged" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			cryptoErr, ok := err.(*errors.CryptoErrorInterface)
+			if !ok || cryptoErr.Code != errors.ErrInvalidFormat {
+				t.Errorf("expected %s error, got %v", errors.ErrInvalidFormat, err)
+			}
+		})
+	}
+}
+
+func TestStreamWorkers(t *testing.T) {
+	const chunkSize = 16
+
+	key := make([]byte, 32)
+	rand.Read(key)
+	aead, _ := newAESGCM(key)
+	prefix := make([]byte, streamPrefixSize(aead))
+	rand.Read(prefix)
+
+	for _, size := range []int{0, 1, chunkSize * 5, chunkSize*5 + 3, chunkSize * 7} {
+		plaintext := make([]byte, size)
+		rand.Read(plaintext)
+
+		// 相同 nonce 前缀下密文与并发数无关，任意并发数加密的流都能以任意并发数解密
+		var want []byte
+		for _, sealWorkers := range []int{1, 2, 3, 8} {
+			sealed, err := io.ReadAll(iotest.HalfReader(newStreamEncryptReader(aead, prefix, nil, chunkSize, sealWorkers, bytes.NewReader(plaintext))))
+			if err != nil {
+				t.Fatalf("size %d, %d workers: stream encryption failed: %v", size, sealWorkers, err)
+			}
+			if want == nil {
+				want = sealed
+			} else if !bytes.Equal(sealed, want) {
+				t.Errorf("size %d: output with %d workers differs from sequential output", size, sealWorkers)
+			}
+
+			for _, openWorkers := range []int{1, 2, 3, 8} {
+				opened, err := io.ReadAll(newStreamDecryptReader(aead, prefix, nil, chunkSize, openWorkers, iotest.HalfReader(bytes.NewReader(sealed))))
+				if err != nil || !bytes.Equal(opened, plaintext) {
+					t.Errorf("size %d: sealed with %d workers, opened with %d: round trip mismatch: %v", size, sealWorkers, openWorkers, err)
+				}
+			}
+		}
+	}
+
+	// 认证失败的块之前的明文仍按顺序输出
+	plaintext := bytes.Repeat([]byte("0123456789abcdef"), 7)
+	sealed, _ := io.ReadAll(newStreamEncryptReader(aead, prefix, nil, chunkSize, 4, bytes.NewReader(plaintext)))
+	sealed[2*(chunkSize+16)+1] ^= 1
+	opened, err := io.ReadAll(newStreamDecryptReader(aead, prefix, nil, chunkSize, 4, bytes.NewReader(sealed)))
+	if err == nil || !bytes.Equal(opened, plaintext[:2*chunkSize]) {
+		t.Errorf("expected 2 chunks before the tampered one, got %d bytes, %v", len(opened), err)
+	}
+}
@@ -0,0 +1,373 @@
+package crypto
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/errors"
+	"hycrypt/internal/format"
+	"io"
+	"os"
+	"strings"
+)
+
+func init() {
+	Register(&Algorithm{
+		Name:        constants.AlgorithmX25519,
+		HeaderID:    format.AlgorithmX25519,
+		DisplayName: "X25519",
+		Icon:        "⚡",
+		Order:       40,
+		KeyFiles:    []KeyFile{{Name: "x25519_public_key"}, {Name: "x25519_private_key", Private: true}},
+		Configure: func(settings Settings, options ConfigureOptions) (any, error) {
+			return &X25519Config{
+				PublicKeyPath:  settings.GetKeyFilePath("x25519_public_key"),
+				PrivateKeyPath: settings.GetKeyFilePath("x25519_private_key"),
+				Passphrase:     options.Passphrase,
+			}, nil
+		},
+		NewService: newServiceFunc(X25519Service),
+		GenerateKeys: func(Parameters) (map[string][]byte, error) {
+			privateKey, err := GenerateX25519Key()
+			if err != nil {
+				return nil, err
+			}
+			return map[string][]byte{
+				"x25519_public_key":  []byte(EncodeX25519PublicKey(privateKey.PublicKey()) + "\n"),
+				"x25519_private_key": []byte(EncodeX25519PrivateKey(privateKey) + "\n"),
+			}, nil
+		},
+		Fingerprint: func(paths map[string]string, passphrase PassphraseFunc) ([]byte, error) {
+			service, err := X25519Service(&X25519Config{
+				PublicKeyPath:  paths["x25519_public_key"],
+				PrivateKeyPath: paths["x25519_private_key"],
+				Passphrase:     passphrase,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return service.fingerprint(), nil
+		},
+	})
+}
+
+// X25519 密钥的文本编码前缀
+const (
+	X25519PublicKeyPrefix  = "x25519:"
+	X25519PrivateKeyPrefix = "X25519-SECRET:"
+)
+
+// hybridAESKeySize 基于密钥协商的混合加密固定使用 AES-256
+const hybridAESKeySize = 32
+
+// x25519Info HKDF info，绑定算法与用途，标签固定不随数据加密算法变化，原因见 mlkemInfo
+const x25519Info = "hycrypt/v3 x25519 aes-256-gcm"
+
+// X25519ServiceInterface X25519 混合加密服务
+type X25519ServiceInterface struct {
+	*BaseService
+	publicKey  *ecdh.PublicKey
+	privateKey *ecdh.PrivateKey
+	config     *X25519Config
+}
+
+func X25519Service(config *X25519Config) (*X25519ServiceInterface, error) {
+	service := &X25519ServiceInterface{
+		BaseService: &BaseService{config: config},
+		config:      config,
+	}
+
+	// 尝试加载私钥（解密需要），受口令保护的 OpenSSH 私钥在解密时才加载
+	if !IsProtectedKeyFile(config.PrivateKeyPath) {
+		service.loadPrivateKey() // 忽略错误，私钥可选
+	}
+
+	// 加载公钥，文件缺失时由私钥推导，存在但无法使用时报错
+	if err := service.loadPublicKey(); err != nil {
+		if _, statErr := os.Stat(config.PublicKeyPath); service.privateKey == nil || !os.IsNotExist(statErr) {
+			return nil, err
+		}
+		service.publicKey = service.privateKey.PublicKey()
+	}
+
+	return service, nil
+}
+
+func (x *X25519ServiceInterface) EncryptData(ctx context.Context, data io.Reader) (io.Reader, error) {
+	// 每个文件生成一次性临时密钥
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmX25519, err)
+	}
+
+	shared, err := ephemeral.ECDH(x.publicKey)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmX25519, err)
+	}
+
+	ephemeralPublic := ephemeral.PublicKey().Bytes()
+	aesKey, err := deriveX25519Key(shared, ephemeralPublic, x.publicKey.Bytes())
+	clearBytes(shared)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmX25519, err)
+	}
+
+	// 派生密钥固定 256 位，适用于所有 AEAD
+	header := format.FromContext(ctx, format.AlgorithmX25519)
+	selectAEAD(header, hybridAESKeySize)
+	aead, err := newAEAD(header.AEAD, aesKey)
+	clearBytes(aesKey)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmX25519, err)
+	}
+
+	// 构建头部: 临时公钥与接收方指纹记录在参数区
+	header.KDF = format.KDFHKDFSHA256
+	header.Set(format.TagEphemeralKey, ephemeralPublic)
+	header.Set(format.TagKeyFingerprint, x25519Fingerprint(x.publicKey))
+	header.SetKeySize(hybridAESKeySize)
+
+	result, err := sealStream(ctx, header, aead, data)
+	if err != nil {
+		return nil, errors.EncryptionFailed(constants.AlgorithmX25519, err)
+	}
+	return result, nil
+}
+
+func (x *X25519ServiceInterface) DecryptData(ctx context.Context, data io.Reader) (io.Reader, error) {
+	header, body, err := format.Open(data)
+	if err != nil {
+		return nil, errors.InvalidFormat("x25519 encrypted data", err)
+	}
+	if header == nil {
+		return nil, errors.InvalidFormat("x25519 encrypted data", fmt.Errorf("missing hycrypt header"))
+	}
+
+	aead, err := x.openAEAD(header)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := openStream(ctx, header, aead, body)
+	if err != nil {
+		return nil, errors.InvalidFormat("x25519 encrypted data", err)
+	}
+	return plaintext, nil
+}
+
+// openAEAD 校验头部与本地密钥，派生分块流式格式使用的 AEAD
+func (x *X25519ServiceInterface) openAEAD(header *format.Header) (cipher.AEAD, error) {
+	if header.Algorithm != format.AlgorithmX25519 {
+		return nil, errors.InvalidFormat("x25519 encrypted data",
+			fmt.Errorf("file was encrypted with %s", header.Algorithm))
+	}
+
+	// 先核对密钥指纹，密钥不符时无需输入私钥口令
+	if err := checkKeyFingerprint(header, constants.AlgorithmX25519, x.fingerprint()); err != nil {
+		return nil, err
+	}
+	if x.privateKey == nil {
+		if err := x.loadPrivateKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	if header.KDF != format.KDFHKDFSHA256 || !isSupportedAEAD(header.AEAD) {
+		return nil, errors.InvalidFormat("x25519 encrypted data",
+			fmt.Errorf("unsupported kdf/aead: %d/%d", header.KDF, header.AEAD))
+	}
+
+	ephemeralPublic, ok := header.Get(format.TagEphemeralKey)
+	if !ok {
+		return nil, errors.InvalidFormat("x25519 encrypted data", fmt.Errorf("missing ephemeral key"))
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralPublic)
+	if err != nil {
+		return nil, errors.InvalidFormat("x25519 encrypted data", err)
+	}
+
+	shared, err := x.privateKey.ECDH(ephemeral)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmX25519, err)
+	}
+
+	aesKey, err := deriveX25519Key(shared, ephemeralPublic, x.privateKey.PublicKey().Bytes())
+	clearBytes(shared)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmX25519, err)
+	}
+
+	aead, err := newAEAD(header.AEAD, aesKey)
+	clearBytes(aesKey)
+	if err != nil {
+		return nil, errors.DecryptionFailed(constants.AlgorithmX25519, err)
+	}
+	return aead, nil
+}
+
+// fingerprint 本地密钥指纹，私钥已加载时取私钥对应的公钥，否则取配置的公钥
+func (x *X25519ServiceInterface) fingerprint() []byte {
+	if x.privateKey != nil {
+		return x25519Fingerprint(x.privateKey.PublicKey())
+	}
+	return x25519Fingerprint(x.publicKey)
+}
+
+func (x *X25519ServiceInterface) ValidateKeys() error {
+	if x.publicKey == nil {
+		return errors.KeyNotFound("public", x.config.PublicKeyPath)
+	}
+	return nil
+}
+
+func (x *X25519ServiceInterface) loadPublicKey() error {
+	publicKey, err := readX25519PublicKey(x.config.PublicKeyPath)
+	if err != nil {
+		return err
+	}
+
+	x.publicKey = publicKey
+	return nil
+}
+
+func (x *X25519ServiceInterface) loadPrivateKey() error {
+	privateKey, err := readX25519PrivateKey(x.config.PrivateKeyPath, x.config.Passphrase)
+	if err != nil {
+		return err
+	}
+
+	x.privateKey = privateKey
+	return nil
+}
+
+// readX25519PublicKey 读取文本编码的 X25519 公钥，或 ssh-ed25519 公钥行（映射为 X25519 公钥）
+// X25519 只封装给一个公钥，authorized_keys 格式的文件包含多个公钥时报错
+func readX25519PublicKey(path string) (*ecdh.PublicKey, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.KeyNotFound("public", path)
+	}
+
+	if isSSHPublicKey(keyData) {
+		sshKeys, err := parseSSHPublicKeys(keyData)
+		if err != nil {
+			return nil, errors.InvalidFormat("ssh public key", err)
+		}
+		if len(sshKeys) != 1 {
+			return nil, errors.InvalidFormat("x25519 public key",
+				fmt.Errorf("expected exactly one ssh-ed25519 public key, found %d: %s", len(sshKeys), path))
+		}
+		ed25519Key, ok := sshKeys[0].(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.InvalidFormat("x25519 public key", fmt.Errorf("not an ssh-ed25519 public key: %s", path))
+		}
+		publicKey, err := ed25519PublicToX25519(ed25519Key)
+		if err != nil {
+			return nil, errors.InvalidFormat("ssh public key", err)
+		}
+		return publicKey, nil
+	}
+
+	publicKey, err := ParseX25519PublicKey(string(keyData))
+	if err != nil {
+		return nil, errors.InvalidFormat("x25519 public key", err)
+	}
+	return publicKey, nil
+}
+
+// readX25519PrivateKey 读取文本编码的 X25519 私钥，或 OpenSSH Ed25519 私钥（映射为 X25519 私钥）
+func readX25519PrivateKey(path string, passphrase PassphraseFunc) (*ecdh.PrivateKey, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.KeyNotFound("private", path)
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(string(keyData)), X25519PrivateKeyPrefix) {
+		key, err := readPrivateKey(path, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		ed25519Key, ok := asEd25519PrivateKey(key)
+		if !ok {
+			return nil, errors.InvalidFormat("x25519 private key", fmt.Errorf("not an Ed25519 private key: %s", path))
+		}
+		privateKey, err := ed25519PrivateToX25519(ed25519Key)
+		if err != nil {
+			return nil, errors.InvalidFormat("x25519 private key", err)
+		}
+		return privateKey, nil
+	}
+
+	privateKey, err := ParseX25519PrivateKey(string(keyData))
+	if err != nil {
+		return nil, errors.InvalidFormat("x25519 private key", err)
+	}
+	return privateKey, nil
+}
+
+// deriveX25519Key 使用 HKDF-SHA256 从共享密钥派生 AES-256 密钥
+// salt 绑定临时公钥与接收方公钥，防止密钥被挪用到其他接收方
+func deriveX25519Key(shared, ephemeralPublic, recipientPublic []byte) ([]byte, error) {
+	salt := make([]byte, 0, len(ephemeralPublic)+len(recipientPublic))
+	salt = append(salt, ephemeralPublic...)
+	salt = append(salt, recipientPublic...)
+	return hkdf.Key(sha256.New, shared, salt, x25519Info, hybridAESKeySize)
+}
+
+// GenerateX25519Key 生成新的 X25519 私钥
+func GenerateX25519Key() (*ecdh.PrivateKey, error) {
+	return ecdh.X25519().GenerateKey(rand.Reader)
+}
+
+// EncodeX25519PublicKey 将公钥编码为短文本，便于分享
+func EncodeX25519PublicKey(key *ecdh.PublicKey) string {
+	return X25519PublicKeyPrefix + base64.RawURLEncoding.EncodeToString(key.Bytes())
+}
+
+// ParseX25519PublicKey 解析文本编码的公钥
+func ParseX25519PublicKey(s string) (*ecdh.PublicKey, error) {
+	raw, err := decodeTextKey(s, X25519PublicKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return ecdh.X25519().NewPublicKey(raw)
+}
+
+// EncodeX25519PrivateKey 将私钥编码为文本
+func EncodeX25519PrivateKey(key *ecdh.PrivateKey) string {
+	return X25519PrivateKeyPrefix + base64.RawURLEncoding.EncodeToString(key.Bytes())
+}
+
+// ParseX25519PrivateKey 解析文本编码的私钥
+func ParseX25519PrivateKey(s string) (*ecdh.PrivateKey, error) {
+	raw, err := decodeTextKey(s, X25519PrivateKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer clearBytes(raw)
+	return ecdh.X25519().NewPrivateKey(raw)
+}
+
+// decodeTextKey 解码 "前缀 + base64" 形式的 32 字节密钥
+func decodeTextKey(s, prefix string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, prefix) {
+		return nil, fmt.Errorf("missing %q prefix", prefix)
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, prefix))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("invalid key length: %d", len(raw))
+	}
+	return raw, nil
+}
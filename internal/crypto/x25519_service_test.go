@@ -0,0 +1,111 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"hycrypt/internal/constants"
+	"hycrypt/internal/format"
+	"io"
+	"testing"
+)
+
+func TestX25519RoundTrip(t *testing.T) {
+	config := writeTestKeys[X25519Config](t, constants.AlgorithmX25519)
+	service, err := X25519Service(config)
+	if err != nil {
+		t.Fatalf("X25519Service failed: %v", err)
+	}
+
+	plaintext := bytes.Repeat([]byte("x25519 "), 20000)
+	encrypted, err := service.EncryptData(context.Background(), bytes.NewReader(plaintext))
+	if err != nil {
+		t.Fatalf("EncryptData failed: %v", err)
+	}
+	sealed, _ := io.ReadAll(encrypted)
+
+	decrypted, err := service.DecryptData(context.Background(), bytes.NewReader(sealed))
+	if err != nil {
+		t.Fatalf("DecryptData failed: %v", err)
+	}
+	opened, err := io.ReadAll(decrypted)
+	if err != nil {
+		t.Fatalf("reading plaintext failed: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Error("round trip mismatch")
+	}
+
+	// 其他接收方的私钥无法解密
+	other, err := X25519Service(writeTestKeys[X25519Config](t, constants.AlgorithmX25519))
+	if err != nil {
+		t.Fatalf("X25519Service failed: %v", err)
+	}
+	decrypted, err = other.DecryptData(context.Background(), bytes.NewReader(sealed))
+	if err == nil {
+		_, err = io.ReadAll(decrypted)
+	}
+	if err == nil {
+		t.Error("expected decryption with another key to fail")
+	}
+}
+
+func TestX25519KeyEncoding(t *testing.T) {
+	privateKey, err := GenerateX25519Key()
+	if err != nil {
+		t.Fatalf("GenerateX25519Key failed: %v", err)
+	}
+
+	encoded := EncodeX25519PublicKey(privateKey.PublicKey())
+	publicKey, err := ParseX25519PublicKey(" " + encoded + "\n")
+	if err != nil {
+		t.Fatalf("ParseX25519PublicKey failed: %v", err)
+	}
+	if !publicKey.Equal(privateKey.PublicKey()) {
+		t.Error("public key mismatch after encoding")
+	}
+
+	parsed, err := ParseX25519PrivateKey(EncodeX25519PrivateKey(privateKey))
+	if err != nil {
+		t.Fatalf("ParseX25519PrivateKey failed: %v", err)
+	}
+	if !parsed.Equal(privateKey) {
+		t.Error("private key mismatch after encoding")
+	}
+
+	for _, invalid := range []string{"", "x25519:", "x25519:AAAA", encoded[len(X25519PublicKeyPrefix):], EncodeX25519PrivateKey(privateKey)} {
+		if _, err := ParseX25519PublicKey(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestX25519AEADSelection(t *testing.T) {
+	service, err := X25519Service(writeTestKeys[X25519Config](t, constants.AlgorithmX25519))
+	if err != nil {
+		t.Fatalf("X25519Service failed: %v", err)
+	}
+
+	plaintext := bytes.Repeat([]byte("aead "), 30000)
+	for _, id := range []format.AEADID{format.AEADAESGCM, format.AEADChaCha20Poly1305, format.AEADXChaCha20Poly1305} {
+		ctx := format.NewContext(context.Background(), &format.Header{AEAD: id})
+		encrypted, err := service.EncryptData(ctx, bytes.NewReader(plaintext))
+		if err != nil {
+			t.Fatalf("%s: EncryptData failed: %v", id, err)
+		}
+		sealed, _ := io.ReadAll(encrypted)
+
+		// 解密时从头部识别 AEAD
+		header, _, err := format.Open(bytes.NewReader(sealed))
+		if err != nil || header.AEAD != id {
+			t.Fatalf("%s: header records wrong aead: %v", id, err)
+		}
+		decrypted, err := service.DecryptData(context.Background(), bytes.NewReader(sealed))
+		if err != nil {
+			t.Fatalf("%s: DecryptData failed: %v", id, err)
+		}
+		opened, err := io.ReadAll(decrypted)
+		if err != nil || !bytes.Equal(opened, plaintext) {
+			t.Errorf("%s: round trip mismatch: %v", id, err)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"hycrypt/internal/age"
 	"hycrypt/internal/domain"
 	"hycrypt/internal/errors"
 	"io"
@@ -50,11 +51,47 @@ func (f *FileSinkInterface) Path() string {
 	return f.path
 }
 
+// Close 关闭输出文件，可重复调用
 func (f *FileSinkInterface) Close() error {
-	if f.file != nil {
-		return f.file.Close()
+	if f.file == nil {
+		return nil
 	}
-	return nil
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// AgeSinkInterface age 文件输出，写入的明文加密为 age v1 格式
+type AgeSinkInterface struct {
+	*FileSinkInterface
+	recipients []age.Recipient
+}
+
+func AgeSink(path string, recipients []age.Recipient) (*AgeSinkInterface, error) {
+	if len(recipients) == 0 {
+		return nil, errors.InvalidConfig("age output requires at least one recipient", nil)
+	}
+
+	fileSink, err := FileSink(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AgeSinkInterface{
+		FileSinkInterface: fileSink,
+		recipients:        recipients,
+	}, nil
+}
+
+func (a *AgeSinkInterface) Write(ctx context.Context, data io.Reader) error {
+	writer, err := age.Encrypt(a.file, a.recipients...)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(writer, data); err != nil {
+		return err
+	}
+	return writer.Close()
 }
 
 // HexSink 十六进制输出
@@ -100,6 +137,74 @@ func (h *HexSink) GetResult() string {
 	return h.result
 }
 
+// JWESink JWE 文本输出
+type JWESink struct {
+	result string
+}
+
+func CreateJWESink() *JWESink {
+	return &JWESink{}
+}
+
+func (j *JWESink) Write(ctx context.Context, data io.Reader) error {
+	bytes, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+
+	j.result = string(bytes)
+
+	// 输出到控制台
+	fmt.Printf("🔑 加密结果（JWE）:\n")
+	fmt.Printf("%s", "="+fmt.Sprintf("%*s", 60, "")+"\n")
+	fmt.Printf("%s\n", j.result)
+	fmt.Printf("%s", "="+fmt.Sprintf("%*s", 60, "")+"\n")
+
+	return nil
+}
+
+func (j *JWESink) Path() string {
+	return "stdout"
+}
+
+func (j *JWESink) Close() error {
+	return nil
+}
+
+func (j *JWESink) GetResult() string {
+	return j.result
+}
+
+// TextSink 内存文本输出，不打印到控制台（用于界面展示结果）
+type TextSink struct {
+	result string
+}
+
+func CreateTextSink() *TextSink {
+	return &TextSink{}
+}
+
+func (t *TextSink) Write(ctx context.Context, data io.Reader) error {
+	bytes, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	t.result = string(bytes)
+	return nil
+}
+
+func (t *TextSink) Path() string {
+	return "memory"
+}
+
+func (t *TextSink) Close() error {
+	return nil
+}
+
+func (t *TextSink) GetResult() string {
+	return t.result
+}
+
 // ConsoleSink 控制台输出（用于解密的文本结果）
 type ConsoleSink struct {
 	result string
@@ -139,6 +244,34 @@ func (c *ConsoleSink) GetResult() string {
 	return c.result
 }
 
+// DiscardSink 读取并丢弃全部数据（用于只校验不输出明文）
+type DiscardSink struct {
+	written int64
+}
+
+func CreateDiscardSink() *DiscardSink {
+	return &DiscardSink{}
+}
+
+func (d *DiscardSink) Write(ctx context.Context, data io.Reader) error {
+	n, err := io.Copy(io.Discard, data)
+	d.written += n
+	return err
+}
+
+func (d *DiscardSink) Path() string {
+	return "discard"
+}
+
+func (d *DiscardSink) Close() error {
+	return nil
+}
+
+// Written 返回已丢弃的字节数
+func (d *DiscardSink) Written() int64 {
+	return d.written
+}
+
 // CreateSink 根据输出类型创建数据输出
 func CreateSink(outputPath string, outputType domain.OutputFormat) (domain.DataSink, error) {
 	switch outputType {
@@ -146,6 +279,8 @@ func CreateSink(outputPath string, outputType domain.OutputFormat) (domain.DataS
 		return FileSink(outputPath)
 	case domain.OutputHex:
 		return CreateHexSink(), nil
+	case domain.OutputJWE, domain.OutputJWEJSON:
+		return CreateJWESink(), nil
 	default:
 		return nil, errors.InvalidFormat("output", fmt.Errorf("unsupported output type: %v", outputType))
 	}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 
+	"hycrypt/internal/age"
 	"hycrypt/internal/domain"
 	"hycrypt/internal/errors"
 	"hycrypt/internal/utils"
@@ -50,6 +51,57 @@ func (f *FileSourceInterface) Type() string {
 	return "file"
 }
 
+// AgeSourceInterface age 文件数据源，读取时用身份解开文件密钥并返回明文
+type AgeSourceInterface struct {
+	path       string
+	size       int64
+	identities []age.Identity
+}
+
+func AgeSource(path string, identities []age.Identity) (*AgeSourceInterface, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.FileNotFound(path)
+	}
+
+	return &AgeSourceInterface{
+		path:       path,
+		size:       info.Size(),
+		identities: identities,
+	}, nil
+}
+
+func (a *AgeSourceInterface) Read(ctx context.Context) (io.ReadCloser, error) {
+	file, err := os.Open(a.path)
+	if err != nil {
+		return nil, errors.FileNotFound(a.path)
+	}
+
+	plaintext, err := age.Decrypt(file, a.identities...)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// 明文流读取完毕后关闭底层文件
+	return &cleanupReader{
+		ReadCloser: file,
+		reader:     plaintext,
+	}, nil
+}
+
+func (a *AgeSourceInterface) Size() int64 {
+	return a.size
+}
+
+func (a *AgeSourceInterface) Name() string {
+	return a.path
+}
+
+func (a *AgeSourceInterface) Type() string {
+	return "age"
+}
+
 // DirectorySource 目录数据源（通过zip压缩）
 type DirectorySource struct {
 	dirPath     string
@@ -57,7 +109,8 @@ type DirectorySource struct {
 	size        int64
 }
 
-func CreateDirectorySource(dirPath string) (*DirectorySource, error) {
+// CreateDirectorySource 创建目录数据源，method 为 zip 条目的压缩方式
+func CreateDirectorySource(dirPath string, method uint16) (*DirectorySource, error) {
 	// 检查目录是否存在
 	info, err := os.Stat(dirPath)
 	if err != nil {
@@ -69,7 +122,7 @@ func CreateDirectorySource(dirPath string) (*DirectorySource, error) {
 	}
 
 	// 创建临时zip文件
-	tempZipPath, err := utils.CreateTempZipFile(dirPath)
+	tempZipPath, err := utils.CreateTempZipFile(dirPath, method)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp zip for directory: %w", err)
 	}
@@ -115,12 +168,20 @@ func (d *DirectorySource) Type() string {
 	return "directory"
 }
 
-// cleanupReader 带清理功能的读取器
+// cleanupReader 带清理功能的读取器，reader 非空时从 reader 读取
 type cleanupReader struct {
 	io.ReadCloser
+	reader  io.Reader
 	cleanup func()
 }
 
+func (c *cleanupReader) Read(p []byte) (int, error) {
+	if c.reader != nil {
+		return c.reader.Read(p)
+	}
+	return c.ReadCloser.Read(p)
+}
+
 func (c *cleanupReader) Close() error {
 	err := c.ReadCloser.Close()
 	if c.cleanup != nil {
@@ -213,8 +274,8 @@ func cleanHexInput(input string) string {
 	return strings.ToLower(cleaned)
 }
 
-// CreateSource 根据输入类型创建数据源
-func CreateSource(input string, inputType domain.InputFormat) (domain.DataSource, error) {
+// CreateSource 根据输入类型创建数据源，archiveMethod 为目录压缩包条目的压缩方式
+func CreateSource(input string, inputType domain.InputFormat, archiveMethod uint16) (domain.DataSource, error) {
 	switch inputType {
 	case domain.InputFile:
 		// 检查是文件还是目录
@@ -224,7 +285,7 @@ func CreateSource(input string, inputType domain.InputFormat) (domain.DataSource
 		}
 
 		if info.IsDir() {
-			return CreateDirectorySource(input)
+			return CreateDirectorySource(input, archiveMethod)
 		} else {
 			return FileSource(input)
 		}
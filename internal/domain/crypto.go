@@ -19,6 +19,12 @@ type OutputFormat int
 const (
 	OutputFile OutputFormat = iota
 	OutputHex
+	// OutputAge age v1 格式文件，可由 age 工具解密
+	OutputAge
+	// OutputJWE JWE 紧凑序列化文本
+	OutputJWE
+	// OutputJWEJSON JWE JSON 序列化文本，支持多个接收方
+	OutputJWEJSON
 )
 
 // InputFormat 输入格式
@@ -28,6 +34,8 @@ const (
 	InputFile InputFormat = iota
 	InputHex
 	InputText
+	// InputJWE JWE 紧凑或 JSON 序列化文本
+	InputJWE
 )
 
 // CryptoResult 加密/解密结果
@@ -38,6 +46,10 @@ type CryptoResult struct {
 	Method        string
 	ProcessTime   int64
 	Error         error
+	// Signer 已验证的签名者公钥，未签名时为空
+	Signer string
+	// Compression 加密前使用的压缩编码，未压缩时为空
+	Compression string
 }
 
 // DataSource 数据源接口
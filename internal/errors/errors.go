@@ -17,6 +17,8 @@ const (
 	ErrInvalidFormat    ErrorCode = "INVALID_FORMAT"
 	ErrPermissionDenied ErrorCode = "PERMISSION_DENIED"
 	ErrInvalidInput     ErrorCode = "INVALID_INPUT"
+	ErrSignatureInvalid ErrorCode = "SIGNATURE_INVALID"
+	ErrWrongKey         ErrorCode = "WRONG_KEY"
 )
 
 // CryptoErrorInterface 加密相关错误
@@ -95,7 +97,17 @@ func FileNotFound(path string) *CryptoErrorInterface {
 		WithContext("path", path)
 }
 
+func SignatureInvalid(message string, cause error) *CryptoErrorInterface {
+	return CryptoError(ErrSignatureInvalid, message, cause)
+}
+
 func InvalidFormat(format string, cause error) *CryptoErrorInterface {
 	return CryptoError(ErrInvalidFormat, fmt.Sprintf("invalid %s format", format), cause).
 		WithContext("format", format)
 }
+
+// WrongKey 文件头部记录的接收方密钥指纹与本地密钥不符
+func WrongKey(method string, expected []string, actual string) *CryptoErrorInterface {
+	return CryptoError(ErrWrongKey, fmt.Sprintf("file was encrypted for key %s, you have key %s", strings.Join(expected, ", "), actual), nil).
+		WithContext("method", method)
+}
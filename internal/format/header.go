@@ -0,0 +1,658 @@
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"hycrypt/internal/constants"
+)
+
+// Magic .hycrypt 文件魔数
+var Magic = []byte("HYCRYPT\x00")
+
+// 格式版本
+const (
+	// Version1 带自描述头部的单块 AES-GCM 格式
+	Version1 uint8 = 1
+
+	// Version2 分块流式 AEAD 格式，块大小和 nonce 前缀记录在参数区
+	Version2 uint8 = 2
+
+	// Version3 KMAC 算法改用 NIST SP 800-185 KMAC256 派生密钥
+	Version3 uint8 = 3
+
+	// Version4 完整头部（含原始文件名）作为每个分块的 AEAD 关联数据
+	Version4 uint8 = 4
+
+	// Version5 接收方相关字段（封装的数据密钥与密钥指纹）不计入关联数据，轮换接收方时无需重新加密载荷；
+	// 签名覆盖关联数据
+	Version5 uint8 = 5
+
+	// CurrentVersion 当前写入的格式版本
+	CurrentVersion = Version5
+)
+
+// 头部尺寸限制
+const (
+	// fixedSize 固定部分长度: magic + version + algorithm + kdf + aead + flags + fieldsLen
+	fixedSize = 8 + 1 + 1 + 1 + 1 + 2 + 4
+
+	// MaxFieldsSize 参数区最大长度
+	MaxFieldsSize = 1 << 20
+)
+
+// AlgorithmID 算法标识
+type AlgorithmID uint8
+
+const (
+	AlgorithmUnknown AlgorithmID = iota
+	AlgorithmRSA
+	AlgorithmKMAC
+	AlgorithmPassword
+	AlgorithmX25519
+	AlgorithmMLKEM
+)
+
+var (
+	algorithmNamesMu sync.RWMutex
+	// algorithmNames 算法标识与名称的映射，由 crypto 注册算法时登记
+	algorithmNames = map[AlgorithmID]string{}
+)
+
+// RegisterAlgorithm 登记算法标识对应的名称，标识无效或已登记时 panic
+func RegisterAlgorithm(id AlgorithmID, name string) {
+	algorithmNamesMu.Lock()
+	defer algorithmNamesMu.Unlock()
+	if id == AlgorithmUnknown || name == "" {
+		panic(fmt.Sprintf("format: invalid algorithm registration %d/%q", uint8(id), name))
+	}
+	if existing, ok := algorithmNames[id]; ok {
+		panic(fmt.Sprintf("format: algorithm id %d already registered as %s", uint8(id), existing))
+	}
+	algorithmNames[id] = name
+}
+
+// name 返回已登记的算法名称
+func (a AlgorithmID) name() (string, bool) {
+	algorithmNamesMu.RLock()
+	defer algorithmNamesMu.RUnlock()
+	name, ok := algorithmNames[a]
+	return name, ok
+}
+
+// String 返回算法名称
+func (a AlgorithmID) String() string {
+	if name, ok := a.name(); ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(a))
+}
+
+// AlgorithmByName 根据算法名称获取标识
+func AlgorithmByName(name string) AlgorithmID {
+	algorithmNamesMu.RLock()
+	defer algorithmNamesMu.RUnlock()
+	for id, n := range algorithmNames {
+		if n == name {
+			return id
+		}
+	}
+	return AlgorithmUnknown
+}
+
+// KDFID 密钥派生函数标识
+type KDFID uint8
+
+const (
+	// KDFNone 不使用密钥派生（如 RSA 直接封装数据密钥）
+	KDFNone KDFID = iota
+	// KDFShake256 旧版 SHAKE256(key || salt || label) 派生，仅用于解密 Version3 之前的文件
+	KDFShake256
+	// KDFKMAC256 NIST SP 800-185 KMAC256(key, salt, L, S) 派生
+	KDFKMAC256
+	// KDFArgon2id 由口令通过 Argon2id 派生，参数记录在 TagArgon2Params 中
+	KDFArgon2id
+	// KDFHKDFSHA256 由 ECDH 共享密钥通过 HKDF-SHA256 派生
+	KDFHKDFSHA256
+)
+
+// AEADID 认证加密算法标识
+type AEADID uint8
+
+const (
+	AEADNone AEADID = iota
+	// AEADAESGCM AES-GCM，密钥长度记录在 TagKeySize 中
+	AEADAESGCM
+	// AEADChaCha20Poly1305 ChaCha20-Poly1305，96 位 nonce
+	AEADChaCha20Poly1305
+	// AEADXChaCha20Poly1305 XChaCha20-Poly1305，192 位 nonce
+	AEADXChaCha20Poly1305
+)
+
+// aeadNames AEAD 标识与名称的映射
+var aeadNames = map[AEADID]string{
+	AEADAESGCM:            constants.AEADAESGCM,
+	AEADChaCha20Poly1305:  constants.AEADChaCha20Poly1305,
+	AEADXChaCha20Poly1305: constants.AEADXChaCha20Poly1305,
+}
+
+// String 返回 AEAD 名称
+func (a AEADID) String() string {
+	if name, ok := aeadNames[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(a))
+}
+
+// AEADByName 根据名称获取 AEAD 标识
+func AEADByName(name string) AEADID {
+	for id, n := range aeadNames {
+		if n == name {
+			return id
+		}
+	}
+	return AEADNone
+}
+
+// PaddingID 长度隐藏填充方案标识
+type PaddingID uint8
+
+const (
+	PaddingNone PaddingID = iota
+	// PaddingPadme PADMÉ: 长度取整到只保留 log2(log2(L))+1 位有效位
+	PaddingPadme
+	// PaddingBucket 长度取整到 2 的幂次，最小 1 KiB
+	PaddingBucket
+)
+
+// paddingNames 填充方案标识与名称的映射
+var paddingNames = map[PaddingID]string{
+	PaddingNone:   constants.PaddingNone,
+	PaddingPadme:  constants.PaddingPadme,
+	PaddingBucket: constants.PaddingBucket,
+}
+
+// String 返回填充方案名称
+func (p PaddingID) String() string {
+	if name, ok := paddingNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(p))
+}
+
+// PaddingByName 根据名称获取填充方案标识，空名称视为不填充
+func PaddingByName(name string) (PaddingID, bool) {
+	if name == "" {
+		return PaddingNone, true
+	}
+	for id, n := range paddingNames {
+		if n == name {
+			return id, true
+		}
+	}
+	return PaddingNone, false
+}
+
+// CompressionID 加密前压缩编码标识
+type CompressionID uint8
+
+const (
+	CompressionNone CompressionID = iota
+	// CompressionGzip RFC 1952 gzip
+	CompressionGzip
+	// CompressionZstd RFC 8878 Zstandard
+	CompressionZstd
+)
+
+// compressionNames 压缩编码标识与名称的映射
+var compressionNames = map[CompressionID]string{
+	CompressionNone: constants.CompressionNone,
+	CompressionGzip: constants.CompressionGzip,
+	CompressionZstd: constants.CompressionZstd,
+}
+
+// String 返回压缩编码名称
+func (c CompressionID) String() string {
+	if name, ok := compressionNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(c))
+}
+
+// CompressionByName 根据名称获取压缩编码标识，空名称视为不压缩
+func CompressionByName(name string) (CompressionID, bool) {
+	if name == "" {
+		return CompressionNone, true
+	}
+	for id, n := range compressionNames {
+		if n == name {
+			return id, true
+		}
+	}
+	return CompressionNone, false
+}
+
+// Flags 头部标志位
+type Flags uint16
+
+const (
+	// FlagDirectory 载荷是目录压缩包
+	FlagDirectory Flags = 1 << iota
+	// FlagSigned 明文末尾附带 Ed25519 签名，签名者公钥记录在 TagSignerKey 中
+	FlagSigned
+)
+
+// Tag 参数字段标签
+type Tag uint8
+
+const (
+	// TagSalt 密钥派生 salt
+	TagSalt Tag = iota + 1
+	// TagWrappedKey 使用公钥封装的数据密钥
+	TagWrappedKey
+	// TagKeySize 数据密钥长度（字节）
+	TagKeySize
+	// TagChunkSize 流式加密的明文块大小（uint32）
+	TagChunkSize
+	// TagNoncePrefix 流式加密的 nonce 前缀
+	TagNoncePrefix
+	// TagArgon2Params Argon2id 参数: time(4) | memoryKiB(4) | threads(1)
+	TagArgon2Params
+	// TagEphemeralKey 每个文件随机生成的 X25519 临时公钥
+	TagEphemeralKey
+	// TagEncapsulation ML-KEM 封装密文
+	TagEncapsulation
+	// TagSignerKey 签名者的 Ed25519 公钥
+	TagSignerKey
+	// TagFileName 原始文件名（目录为目录名）
+	TagFileName
+	// TagKeyFingerprint 接收方密钥指纹，每个接收方一项
+	TagKeyFingerprint
+	// TagPadding 明文末尾的长度隐藏填充方案（1 字节 PaddingID），缺省为不填充
+	TagPadding
+	// TagCompression 明文加密前的压缩编码（1 字节 CompressionID），缺省为不压缩
+	TagCompression
+)
+
+// Field 头部参数字段（TLV）
+type Field struct {
+	Tag   Tag
+	Value []byte
+}
+
+// Header 加密文件头部
+//
+// 二进制布局:
+//
+//	magic(8) | version(1) | algorithm(1) | kdf(1) | aead(1) | flags(2) | fieldsLen(4) | fields
+//
+// 每个参数字段编码为 tag(1) | len(2) | value。
+type Header struct {
+	Version   uint8
+	Algorithm AlgorithmID
+	KDF       KDFID
+	AEAD      AEADID
+	Flags     Flags
+	Fields    []Field
+}
+
+// New 创建指定算法的新头部
+func New(algorithm AlgorithmID) *Header {
+	return &Header{
+		Version:   CurrentVersion,
+		Algorithm: algorithm,
+	}
+}
+
+// HasFlag 检查是否设置了标志位
+func (h *Header) HasFlag(flag Flags) bool {
+	return h.Flags&flag != 0
+}
+
+// IsDirectory 载荷是否为目录压缩包
+func (h *Header) IsDirectory() bool {
+	return h.HasFlag(FlagDirectory)
+}
+
+// Get 获取第一个匹配标签的字段值
+func (h *Header) Get(tag Tag) ([]byte, bool) {
+	for _, f := range h.Fields {
+		if f.Tag == tag {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// GetAll 获取所有匹配标签的字段值
+func (h *Header) GetAll(tag Tag) [][]byte {
+	var values [][]byte
+	for _, f := range h.Fields {
+		if f.Tag == tag {
+			values = append(values, f.Value)
+		}
+	}
+	return values
+}
+
+// Set 设置字段值（替换同标签的已有字段）
+func (h *Header) Set(tag Tag, value []byte) {
+	h.Remove(tag)
+	h.Add(tag, value)
+}
+
+// Add 追加字段（允许同标签重复出现）
+func (h *Header) Add(tag Tag, value []byte) {
+	h.Fields = append(h.Fields, Field{Tag: tag, Value: append([]byte(nil), value...)})
+}
+
+// Remove 删除所有匹配标签的字段
+func (h *Header) Remove(tag Tag) {
+	fields := h.Fields[:0]
+	for _, f := range h.Fields {
+		if f.Tag != tag {
+			fields = append(fields, f)
+		}
+	}
+	h.Fields = fields
+}
+
+// KeySize 获取记录的数据密钥长度
+func (h *Header) KeySize() int {
+	if v, ok := h.Get(TagKeySize); ok && len(v) == 1 {
+		return int(v[0])
+	}
+	return 0
+}
+
+// SetKeySize 记录数据密钥长度
+func (h *Header) SetKeySize(size int) {
+	h.Set(TagKeySize, []byte{byte(size)})
+}
+
+// ChunkSize 获取记录的明文块大小
+func (h *Header) ChunkSize() int {
+	if v, ok := h.Get(TagChunkSize); ok && len(v) == 4 {
+		return int(binary.BigEndian.Uint32(v))
+	}
+	return 0
+}
+
+// SetChunkSize 记录明文块大小
+func (h *Header) SetChunkSize(size int) {
+	var v [4]byte
+	binary.BigEndian.PutUint32(v[:], uint32(size))
+	h.Set(TagChunkSize, v[:])
+}
+
+// Argon2Params 获取记录的 Argon2id 参数
+func (h *Header) Argon2Params() (time, memory uint32, threads uint8, ok bool) {
+	v, found := h.Get(TagArgon2Params)
+	if !found || len(v) != 9 {
+		return 0, 0, 0, false
+	}
+	return binary.BigEndian.Uint32(v[0:4]), binary.BigEndian.Uint32(v[4:8]), v[8], true
+}
+
+// SetArgon2Params 记录 Argon2id 参数
+func (h *Header) SetArgon2Params(time, memory uint32, threads uint8) {
+	v := make([]byte, 9)
+	binary.BigEndian.PutUint32(v[0:4], time)
+	binary.BigEndian.PutUint32(v[4:8], memory)
+	v[8] = threads
+	h.Set(TagArgon2Params, v)
+}
+
+// Padding 获取记录的填充方案
+func (h *Header) Padding() PaddingID {
+	if v, ok := h.Get(TagPadding); ok && len(v) == 1 {
+		return PaddingID(v[0])
+	}
+	return PaddingNone
+}
+
+// SetPadding 记录填充方案，不填充时删除该字段
+func (h *Header) SetPadding(padding PaddingID) {
+	if padding == PaddingNone {
+		h.Remove(TagPadding)
+		return
+	}
+	h.Set(TagPadding, []byte{byte(padding)})
+}
+
+// Compression 获取记录的压缩编码
+func (h *Header) Compression() CompressionID {
+	if v, ok := h.Get(TagCompression); ok && len(v) == 1 {
+		return CompressionID(v[0])
+	}
+	return CompressionNone
+}
+
+// SetCompression 记录压缩编码，不压缩时删除该字段
+func (h *Header) SetCompression(compression CompressionID) {
+	if compression == CompressionNone {
+		h.Remove(TagCompression)
+		return
+	}
+	h.Set(TagCompression, []byte{byte(compression)})
+}
+
+// FileName 返回头部记录的原始文件名，仅保留最后一级路径
+func (h *Header) FileName() string {
+	name, ok := h.Get(TagFileName)
+	if !ok {
+		return ""
+	}
+	base := filepath.Base(string(name))
+	if base == "." || base == ".." || base == string(filepath.Separator) {
+		return ""
+	}
+	return base
+}
+
+// recipientTags 接收方相关字段，Version5 起不计入关联数据
+var recipientTags = []Tag{TagWrappedKey, TagKeyFingerprint}
+
+// AssociatedData 返回分块加密使用的关联数据
+// Version4 起为序列化后的完整头部，篡改算法、版本、标志位或文件名都会导致认证失败；
+// Version5 起去掉接收方相关字段，换用其他密钥封装数据密钥后载荷仍可认证
+func (h *Header) AssociatedData() ([]byte, error) {
+	if h.Version < Version4 {
+		return nil, nil
+	}
+	if h.Version < Version5 {
+		return h.Marshal()
+	}
+
+	authenticated := *h
+	authenticated.Fields = slices.DeleteFunc(slices.Clone(h.Fields), func(f Field) bool {
+		return slices.Contains(recipientTags, f.Tag)
+	})
+	return authenticated.Marshal()
+}
+
+// CanRewrapKeys 是否可以只替换封装的数据密钥而不重新加密载荷
+// Version4 的关联数据包含封装的数据密钥，替换后载荷无法通过认证
+func (h *Header) CanRewrapKeys() bool {
+	return h.Version != Version4
+}
+
+// IsStreaming 是否为分块流式格式
+func (h *Header) IsStreaming() bool {
+	return h.Version >= Version2
+}
+
+// Marshal 序列化头部
+func (h *Header) Marshal() ([]byte, error) {
+	var fields bytes.Buffer
+	for _, f := range h.Fields {
+		if len(f.Value) > 0xFFFF {
+			return nil, fmt.Errorf("header field %d too large: %d bytes", f.Tag, len(f.Value))
+		}
+		fields.WriteByte(byte(f.Tag))
+		binary.Write(&fields, binary.BigEndian, uint16(len(f.Value)))
+		fields.Write(f.Value)
+	}
+	if fields.Len() > MaxFieldsSize {
+		return nil, fmt.Errorf("header fields too large: %d bytes", fields.Len())
+	}
+
+	buf := make([]byte, fixedSize, fixedSize+fields.Len())
+	copy(buf, Magic)
+	buf[8] = h.Version
+	buf[9] = byte(h.Algorithm)
+	buf[10] = byte(h.KDF)
+	buf[11] = byte(h.AEAD)
+	binary.BigEndian.PutUint16(buf[12:14], uint16(h.Flags))
+	binary.BigEndian.PutUint32(buf[14:18], uint32(fields.Len()))
+
+	return append(buf, fields.Bytes()...), nil
+}
+
+// HasMagic 检查数据是否以魔数开头
+func HasMagic(data []byte) bool {
+	return bytes.HasPrefix(data, Magic)
+}
+
+// Read 从流中读取并解析头部
+func Read(r io.Reader) (*Header, error) {
+	fixed := make([]byte, fixedSize)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if !HasMagic(fixed) {
+		return nil, fmt.Errorf("missing hycrypt magic bytes")
+	}
+
+	h := &Header{
+		Version:   fixed[8],
+		Algorithm: AlgorithmID(fixed[9]),
+		KDF:       KDFID(fixed[10]),
+		AEAD:      AEADID(fixed[11]),
+		Flags:     Flags(binary.BigEndian.Uint16(fixed[12:14])),
+	}
+	if h.Version == 0 || h.Version > CurrentVersion {
+		return nil, fmt.Errorf("unsupported format version: %d", h.Version)
+	}
+
+	fieldsLen := binary.BigEndian.Uint32(fixed[14:18])
+	if fieldsLen > MaxFieldsSize {
+		return nil, fmt.Errorf("header fields too large: %d bytes", fieldsLen)
+	}
+
+	fields := make([]byte, fieldsLen)
+	if _, err := io.ReadFull(r, fields); err != nil {
+		return nil, fmt.Errorf("failed to read header fields: %w", err)
+	}
+
+	for len(fields) > 0 {
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("truncated header field")
+		}
+		tag := Tag(fields[0])
+		size := int(binary.BigEndian.Uint16(fields[1:3]))
+		if len(fields) < 3+size {
+			return nil, fmt.Errorf("truncated header field %d", tag)
+		}
+		h.Add(tag, fields[3:3+size])
+		fields = fields[3+size:]
+	}
+
+	return h, nil
+}
+
+// Parse 从字节切片解析头部，返回头部和剩余的载荷数据
+func Parse(data []byte) (*Header, []byte, error) {
+	r := bytes.NewReader(data)
+	h, err := Read(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return h, data[len(data)-r.Len():], nil
+}
+
+// Sniff 检查流是否带有头部
+//
+// 返回解析出的头部（旧格式文件返回 nil）以及一个可以从头重新读取完整数据的读取器。
+func Sniff(r io.Reader) (*Header, io.Reader, error) {
+	h, body, err := Open(r)
+	if err != nil || h == nil {
+		return h, body, err
+	}
+
+	raw, err := h.Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return h, io.MultiReader(bytes.NewReader(raw), body), nil
+}
+
+// Open 读取流开头的头部
+//
+// 与 Sniff 不同，返回的读取器定位在头部之后的载荷处；旧格式文件返回 nil 头部和完整数据。
+func Open(r io.Reader) (*Header, io.Reader, error) {
+	br := bufio.NewReader(r)
+	prefix, err := br.Peek(len(Magic))
+	if err != nil || !HasMagic(prefix) {
+		// 数据过短或没有魔数，按旧格式处理
+		return nil, br, nil
+	}
+
+	h, err := Read(br)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return h, br, nil
+}
+
+// ReadFile 读取文件头部，旧格式文件返回 nil
+func ReadFile(path string) (*Header, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	h, _, err := Sniff(file)
+	return h, err
+}
+
+// DetectAlgorithm 从文件头部检测算法，无法识别时返回空字符串
+func DetectAlgorithm(path string) string {
+	h, err := ReadFile(path)
+	if err != nil || h == nil {
+		return ""
+	}
+	name, _ := h.Algorithm.name()
+	return name
+}
+
+type contextKey struct{}
+
+// NewContext 将头部模板（如标志位）附加到上下文，供加密服务写入头部时使用
+func NewContext(ctx context.Context, h *Header) context.Context {
+	return context.WithValue(ctx, contextKey{}, h)
+}
+
+// FromContext 基于上下文中的模板创建指定算法的新头部
+func FromContext(ctx context.Context, algorithm AlgorithmID) *Header {
+	h := New(algorithm)
+	if tmpl, ok := ctx.Value(contextKey{}).(*Header); ok && tmpl != nil {
+		h.Flags = tmpl.Flags
+		h.AEAD = tmpl.AEAD
+		for _, f := range tmpl.Fields {
+			h.Add(f.Tag, f.Value)
+		}
+	}
+	return h
+}
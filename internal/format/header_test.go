@@ -0,0 +1,164 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"hycrypt/internal/constants"
+)
+
+func TestHeaderRoundTrip(t *testing.T) {
+	h := New(AlgorithmKMAC)
+	h.KDF = KDFShake256
+	h.AEAD = AEADAESGCM
+	h.Flags |= FlagDirectory
+	h.Set(TagSalt, []byte("0123456789abcdef"))
+	h.SetKeySize(32)
+
+	raw, err := h.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if !HasMagic(raw) {
+		t.Fatal("Marshalled header should start with magic bytes")
+	}
+
+	parsed, body, err := Parse(append(raw, []byte("payload")...))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if string(body) != "payload" {
+		t.Errorf("Expected remaining payload 'payload', got %q", body)
+	}
+	if parsed.Version != CurrentVersion {
+		t.Errorf("Expected version %d, got %d", CurrentVersion, parsed.Version)
+	}
+	if parsed.Algorithm != AlgorithmKMAC || parsed.KDF != KDFShake256 || parsed.AEAD != AEADAESGCM {
+		t.Errorf("Algorithm parameters mismatch: %+v", parsed)
+	}
+	if !parsed.IsDirectory() {
+		t.Error("Expected directory flag to be set")
+	}
+	if salt, _ := parsed.Get(TagSalt); string(salt) != "0123456789abcdef" {
+		t.Errorf("Salt mismatch: %q", salt)
+	}
+	if parsed.KeySize() != 32 {
+		t.Errorf("Expected key size 32, got %d", parsed.KeySize())
+	}
+}
+
+func TestHeaderRejectsInvalidData(t *testing.T) {
+	valid, _ := New(AlgorithmRSA).Marshal()
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"no magic", []byte("not a hycrypt file at all")},
+		{"truncated", valid[:len(valid)-1]},
+		{"future version", append(append([]byte{}, valid[:8]...), append([]byte{CurrentVersion + 1}, valid[9:]...)...)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := Parse(tc.data); err == nil {
+				t.Errorf("Expected Parse to fail for %s", tc.name)
+			}
+		})
+	}
+}
+
+func TestSniffLegacyData(t *testing.T) {
+	legacy := []byte("legacy ciphertext without header")
+
+	h, r, err := Sniff(bytes.NewReader(legacy))
+	if err != nil {
+		t.Fatalf("Sniff failed: %v", err)
+	}
+	if h != nil {
+		t.Error("Expected nil header for legacy data")
+	}
+
+	replayed, _ := io.ReadAll(r)
+	if !bytes.Equal(replayed, legacy) {
+		t.Error("Sniff should replay legacy data unchanged")
+	}
+}
+
+func TestSniffReplaysHeader(t *testing.T) {
+	h := New(AlgorithmRSA)
+	h.Set(TagWrappedKey, bytes.Repeat([]byte{0xAB}, 512))
+	raw, _ := h.Marshal()
+	data := append(raw, []byte("body")...)
+
+	sniffed, r, err := Sniff(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Sniff failed: %v", err)
+	}
+	if sniffed == nil || sniffed.Algorithm != AlgorithmRSA {
+		t.Fatal("Expected RSA header")
+	}
+
+	replayed, _ := io.ReadAll(r)
+	if !bytes.Equal(replayed, data) {
+		t.Error("Sniff should replay header and body unchanged")
+	}
+}
+
+func TestAlgorithmNames(t *testing.T) {
+	RegisterAlgorithm(AlgorithmRSA, constants.AlgorithmRSA)
+	RegisterAlgorithm(AlgorithmKMAC, constants.AlgorithmKMAC)
+
+	if AlgorithmByName(constants.AlgorithmRSA) != AlgorithmRSA {
+		t.Error("Expected rsa to map to AlgorithmRSA")
+	}
+	if AlgorithmByName(constants.AlgorithmKMAC) != AlgorithmKMAC {
+		t.Error("Expected kmac to map to AlgorithmKMAC")
+	}
+	if AlgorithmByName("unknown") != AlgorithmUnknown {
+		t.Error("Expected unknown algorithm to map to AlgorithmUnknown")
+	}
+	if AlgorithmKMAC.String() != constants.AlgorithmKMAC {
+		t.Errorf("Expected %s, got %s", constants.AlgorithmKMAC, AlgorithmKMAC.String())
+	}
+}
+
+func TestFromContextCopiesTemplate(t *testing.T) {
+	ctx := NewContext(context.Background(), &Header{Flags: FlagDirectory})
+
+	h := FromContext(ctx, AlgorithmKMAC)
+	if h.Algorithm != AlgorithmKMAC || !h.IsDirectory() {
+		t.Errorf("Expected KMAC directory header, got %+v", h)
+	}
+
+	plain := FromContext(context.Background(), AlgorithmRSA)
+	if plain.IsDirectory() {
+		t.Error("Header without template should not carry flags")
+	}
+}
+
+func TestFileNameStripsPath(t *testing.T) {
+	testCases := []struct {
+		stored   string
+		expected string
+	}{
+		{"report.pdf", "report.pdf"},
+		{"../../etc/passwd", "passwd"},
+		{"/abs/dir/", "dir"},
+		{"..", ""},
+		{"", ""},
+	}
+
+	for _, tc := range testCases {
+		h := New(AlgorithmRSA)
+		h.Set(TagFileName, []byte(tc.stored))
+		if got := h.FileName(); got != tc.expected {
+			t.Errorf("FileName(%q) = %q, expected %q", tc.stored, got, tc.expected)
+		}
+	}
+}
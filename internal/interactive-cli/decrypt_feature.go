@@ -2,11 +2,8 @@ package interactivecli
 
 import (
 	"strings"
-	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-
-	"hycrypt/internal/constants"
 )
 
 // getDecryptAlgorithmChoices 从配置中获取解密算法选择项
@@ -15,14 +12,7 @@ func getDecryptAlgorithmChoices(m Model) []string {
 	choices := make([]string, len(supportedAlgorithms))
 
 	for i, algorithm := range supportedAlgorithms {
-		switch strings.ToLower(algorithm) {
-		case constants.AlgorithmRSA:
-			choices[i] = "🔐 RSA-4096 解密"
-		case constants.AlgorithmKMAC:
-			choices[i] = "🔑 KMAC 解密"
-		default:
-			choices[i] = "🔒 " + strings.ToUpper(algorithm) + " 解密"
-		}
+		choices[i] = algorithmChoice(algorithm, "解密")
 	}
 
 	return choices
@@ -90,6 +80,7 @@ func (d *DecryptFeatureStruct) HandleFileInput(m Model, msg tea.KeyMsg) (Model,
 			if detectedAlgorithm != "" && m.config.IsAlgorithmSupported(detectedAlgorithm) {
 				// 自动检测到算法，直接设置并跳过算法选择
 				m.algorithm = detectedAlgorithm
+				selectMatchingKey(m, inputPath)
 				m.state = stateOutput
 				m.outputInput.Focus()
 			} else {
@@ -129,10 +120,8 @@ func (d *DecryptFeatureStruct) HandleTextInput(m Model, msg tea.KeyMsg) (Model,
 	onSubmit := func(m Model) (Model, tea.Cmd) {
 		textContent := strings.TrimSpace(m.textArea.Value())
 		if textContent != "" {
-			// 文本解密直接处理
-			m.state = stateProcessing
-			m.progress = 0.0
-			return m, d.startProcessing(m)
+			// 文本解密直接处理（口令算法先输入口令）
+			return enterPasswordOrProcess(m)
 		}
 		return m, nil
 	}
@@ -195,9 +184,7 @@ func (d *DecryptFeatureStruct) HandleOutput(m Model, msg tea.KeyMsg) (Model, tea
 	onSubmit := func(m Model) (Model, tea.Cmd) {
 		outputPath := strings.TrimSpace(m.outputInput.Value())
 		m.outputInput.SetValue(outputPath)
-		m.state = stateProcessing
-		m.progress = 0.0
-		return m, d.startProcessing(m)
+		return enterPasswordOrProcess(m)
 	}
 
 	onEscape := func(m Model) Model {
@@ -225,10 +212,3 @@ func (d *DecryptFeatureStruct) HandleOutput(m Model, msg tea.KeyMsg) (Model, tea
 	m.outputInput, cmd = m.outputInput.Update(msg)
 	return m, cmd
 }
-
-// startProcessing 开始解密处理流程
-func (d *DecryptFeatureStruct) startProcessing(m Model) tea.Cmd {
-	return tea.Tick(time.Millisecond*50, func(t time.Time) tea.Msg {
-		return progressMsg(m.progress + 0.02)
-	})
-}
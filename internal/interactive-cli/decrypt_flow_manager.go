@@ -6,13 +6,15 @@ import (
 
 // DecryptFlowManager 解密流程管理器
 type DecryptFlowManager struct {
-	decryptFeature *DecryptFeatureStruct
+	decryptFeature  *DecryptFeatureStruct
+	passwordFeature *PasswordFeatureStruct
 }
 
 // NewDecryptFlowManager 创建解密流程管理器
 func NewDecryptFlowManager() *DecryptFlowManager {
 	return &DecryptFlowManager{
-		decryptFeature: DecryptFeature(),
+		decryptFeature:  DecryptFeature(),
+		passwordFeature: PasswordFeature(),
 	}
 }
 
@@ -29,6 +31,8 @@ func (f *DecryptFlowManager) HandleDecryptFlow(m Model, msg tea.KeyMsg) (Model,
 		return f.decryptFeature.HandleAlgorithmForDecrypt(m, msg)
 	case stateOutput:
 		return f.decryptFeature.HandleOutput(m, msg)
+	case statePasswordInput:
+		return f.passwordFeature.HandlePasswordInput(m, msg)
 	default:
 		return m, nil
 	}
@@ -44,5 +48,6 @@ func (f *DecryptFlowManager) HandleMainMenuDecrypt(m Model) Model {
 	m.pathInput.Reset()
 	m.textArea.Reset()
 	m.outputInput.Reset()
+	clearPassword(&m)
 	return m
 }
@@ -8,7 +8,8 @@ import (
 	"strings"
 	"time"
 
-	"hycrypt/internal/constants"
+	"hycrypt/internal/crypto"
+	"hycrypt/internal/jwe"
 )
 
 // DecryptProcessor 解密处理器
@@ -28,11 +29,6 @@ func (p *DecryptProcessor) ProcessDecryption(m Model) operationResult {
 		return p.processTextDecryption(m, startTime)
 	}
 
-	cryptoService, err := CryptoService(m.config)
-	if err != nil {
-		return newOperationResult(false, fmt.Sprintf("初始化加密服务失败: %v", err))
-	}
-
 	outputDir := p.resolveOutputDirectory(m)
 
 	var targetPath string
@@ -46,13 +42,10 @@ func (p *DecryptProcessor) ProcessDecryption(m Model) operationResult {
 	// 使用已检测到的算法，如果没有则尝试从路径检测
 	detectedAlgorithm := strings.ToUpper(m.algorithm)
 	if detectedAlgorithm == "" {
-		// 回退到路径检测
-		if strings.Contains(targetPath, "-rsa"+m.config.Encryption.FileExtension) {
-			detectedAlgorithm = constants.AlgorithmRSA
-			m.config.Encryption.Method = constants.AlgorithmRSA
-		} else if strings.Contains(targetPath, "-kmac"+m.config.Encryption.FileExtension) {
-			detectedAlgorithm = constants.AlgorithmKMAC
-			m.config.Encryption.Method = constants.AlgorithmKMAC
+		// 回退到文件头部和文件名检测
+		if method := m.config.DetectAlgorithmFromPath(targetPath); method != "" {
+			detectedAlgorithm = strings.ToUpper(method)
+			m.config.Encryption.Method = method
 		} else {
 			detectedAlgorithm = "未知"
 		}
@@ -61,10 +54,10 @@ func (p *DecryptProcessor) ProcessDecryption(m Model) operationResult {
 		m.config.Encryption.Method = m.algorithm
 	}
 
-	// 重新创建加密服务以使用正确的算法
-	cryptoService, err = CryptoService(m.config)
+	// 按检测到的算法创建加密服务
+	cryptoService, err := CryptoServiceWithPassword(m.config, m.password)
 	if err != nil {
-		return newOperationResult(false, fmt.Sprintf("重新初始化加密服务失败: %v", err))
+		return newOperationResult(false, fmt.Sprintf("初始化加密服务失败: %v", err))
 	}
 
 	actualOutputPath, err := cryptoService.DecryptPath(targetPath, outputDir)
@@ -132,6 +125,11 @@ func (p *DecryptProcessor) processTextDecryption(m Model, startTime time.Time) o
 		return newOperationResult(false, "输入为空")
 	}
 
+	// JWE 根据 alg 选择解密方法
+	if jwe.IsJWE(textContent) {
+		return p.processJWEDecryptionFromText(m, startTime, strings.TrimSpace(textContent))
+	}
+
 	// 使用统一的清理函数检查输入是否为十六进制格式
 	cleanedText := cleanHexInput(textContent)
 
@@ -160,7 +158,7 @@ func (p *DecryptProcessor) processHexDecryptionFromText(m Model, startTime time.
 
 	// 注意：这里不需要再次更新算法，因为调用者已经设置了
 	// 创建加密服务
-	cryptoService, err := CryptoService(m.config)
+	cryptoService, err := CryptoServiceWithPassword(m.config, m.password)
 	if err != nil {
 		return newOperationResult(false, fmt.Sprintf("初始化加密服务失败: %v", err))
 	}
@@ -168,16 +166,11 @@ func (p *DecryptProcessor) processHexDecryptionFromText(m Model, startTime time.
 	// 直接解密数据
 	var decryptedData []byte
 
-	// 根据不同的加密方法进行解密
-	switch m.config.Encryption.Method {
-	case constants.AlgorithmRSA:
-		decryptedData, err = decryptDataWithRSA(cryptoService, encryptedData)
-	case constants.AlgorithmKMAC:
-		decryptedData, err = decryptDataWithKMAC(cryptoService, encryptedData)
-	default:
+	// 使用注册表中的加密方法进行解密
+	if !crypto.IsRegisteredAlgorithm(m.config.Encryption.Method) {
 		return newOperationResult(false, fmt.Sprintf("不支持的加密方法: %s", m.config.Encryption.Method))
 	}
-
+	decryptedData, err = decryptDataWithMethod(cryptoService, encryptedData, m.config.Encryption.Method)
 	if err != nil {
 		return newOperationResult(false, fmt.Sprintf("解密失败: %v", err))
 	}
@@ -199,11 +192,49 @@ func (p *DecryptProcessor) processHexDecryptionFromText(m Model, startTime time.
 	}
 }
 
+// processJWEDecryptionFromText 从文本输入处理 JWE 解密
+func (p *DecryptProcessor) processJWEDecryptionFromText(m Model, startTime time.Time, jweText string) operationResult {
+	method := crypto.DetectJWEMethod(jweText)
+	if method == "" {
+		return newOperationResult(false, "不支持的 JWE 算法，仅支持 RSA-OAEP-256 和 dir")
+	}
+
+	// 按 JWE 的算法创建加密服务
+	originalMethod := m.config.Encryption.Method
+	m.config.Encryption.Method = method
+	defer func() {
+		m.config.Encryption.Method = originalMethod
+	}()
+
+	cryptoService, err := CryptoServiceWithPassword(m.config, m.password)
+	if err != nil {
+		return newOperationResult(false, fmt.Sprintf("初始化加密服务失败: %v", err))
+	}
+
+	decryptedData, err := decryptTextJWE(cryptoService, jweText)
+	if err != nil {
+		return newOperationResult(false, fmt.Sprintf("解密失败: %v", err))
+	}
+
+	algorithm := strings.ToUpper(method) + " 解密"
+	return operationResult{
+		success: true,
+		message: "HEX_OUTPUT_TRIGGER",
+		resultInfo: EncryptionResult{
+			FileName:       "JWE_OUTPUT",
+			FileSize:       0,
+			Algorithm:      algorithm,
+			EncryptionTime: time.Since(startTime).String(),
+			OutputPath:     fmt.Sprintf("%s|%s|%s", string(decryptedData), jweText, algorithm),
+		},
+	}
+}
+
 // processDirectTextDecryption 直接解密文本内容（非十六进制）
 func (p *DecryptProcessor) processDirectTextDecryption(m Model, startTime time.Time, textContent string) operationResult {
 	// 这个功能主要用于解密从其他来源获得的加密文本
 	// 目前我们主要支持十六进制解密，所以这里返回提示
-	return newOperationResult(false, "文本解密需要输入十六进制格式的加密数据")
+	return newOperationResult(false, "文本解密需要输入十六进制或 JWE 格式的加密数据")
 }
 
 // isHexString 检查字符串是否为有效的十六进制字符串
@@ -2,11 +2,10 @@ package interactivecli
 
 import (
 	"strings"
-	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
+	"hycrypt/internal/crypto"
 
-	"hycrypt/internal/constants"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 // getEncryptAlgorithmChoices 从配置中获取加密算法选择项
@@ -15,19 +14,43 @@ func getEncryptAlgorithmChoices(m Model) []string {
 	choices := make([]string, len(supportedAlgorithms))
 
 	for i, algorithm := range supportedAlgorithms {
-		switch strings.ToLower(algorithm) {
-		case constants.AlgorithmRSA:
-			choices[i] = "🔐 RSA-4096 加密"
-		case constants.AlgorithmKMAC:
-			choices[i] = "🔑 KMAC 加密"
-		default:
-			choices[i] = "🔒 " + strings.ToUpper(algorithm) + " 加密"
-		}
+		choices[i] = algorithmChoice(algorithm, "加密")
 	}
 
 	return choices
 }
 
+// outputFormats 文本加密的输出格式及对应的选择项
+var outputFormats = []struct {
+	value  string
+	choice string
+}{
+	{"file", "📁 保存为文件"},
+	{"hex", "🔤 输出十六进制"},
+	{"jwe", "🌐 输出 JWE（紧凑格式）"},
+	{"jwe-json", "🧾 输出 JWE（JSON 格式）"},
+}
+
+// getOutputFormatChoices 获取输出格式选择项，JWE 只列出注册了 JWE 算法的加密方法
+func getOutputFormatChoices(m Model) []string {
+	algorithm, ok := crypto.LookupAlgorithm(m.algorithm)
+	supportsJWE := ok && algorithm.JWEAlgorithm != ""
+
+	var choices []string
+	for _, format := range outputFormats {
+		if isJWEFormat(format.value) && !supportsJWE {
+			continue
+		}
+		choices = append(choices, format.choice)
+	}
+	return choices
+}
+
+// isJWEFormat 检查输出格式是否为 JWE
+func isJWEFormat(outputFormat string) bool {
+	return outputFormat == "jwe" || outputFormat == "jwe-json"
+}
+
 // EncryptFeatureInterface 加密功能处理器
 type EncryptFeatureInterface struct {
 	keyHandler *CommonKeyHandlerStruct
@@ -47,11 +70,8 @@ func (e *EncryptFeatureInterface) HandleAlgorithm(m Model, msg tea.KeyMsg) (Mode
 		if m.cursor < len(supportedAlgorithms) {
 			m.algorithm = supportedAlgorithms[m.cursor]
 		}
-		// 加密操作进入输入类型选择
-		m.state = stateInputType
-		m.choices = inputTypeEncryptChoices
-		m.cursor = 0
-		return m, nil
+		// 加密操作先选择密钥（密钥环中有多个密钥时），再进入输入类型选择
+		return enterKeySelection(m), nil
 	}
 
 	onEscape := func(m Model) Model {
@@ -82,7 +102,7 @@ func (e *EncryptFeatureInterface) HandleInputType(m Model, msg tea.KeyMsg) (Mode
 			// 文本加密 - 需要选择输出格式
 			m.inputType = "text"
 			m.state = stateOutputFormat
-			m.choices = []string{"📁 保存为文件", "🔤 输出十六进制"}
+			m.choices = getOutputFormatChoices(m)
 		}
 		if m.state != stateOutputFormat {
 			m.choices = []string{}
@@ -92,6 +112,9 @@ func (e *EncryptFeatureInterface) HandleInputType(m Model, msg tea.KeyMsg) (Mode
 	}
 
 	onEscape := func(m Model) Model {
+		if len(getKeyNames(m)) > 1 {
+			return enterKeySelection(m)
+		}
 		HandleEscapeToState(&m, stateAlgorithm, getEncryptAlgorithmChoices(m))
 		return m
 	}
@@ -147,11 +170,9 @@ func (e *EncryptFeatureInterface) HandleTextInput(m Model, msg tea.KeyMsg) (Mode
 	onSubmit := func(m Model) (Model, tea.Cmd) {
 		textContent := strings.TrimSpace(m.textArea.Value())
 		if textContent != "" {
-			if m.outputFormat == "hex" {
-				// 十六进制输出，直接处理
-				m.state = stateProcessing
-				m.progress = 0.0
-				return m, e.startProcessing(m)
+			if m.outputFormat != "file" {
+				// 十六进制或 JWE 输出，直接处理（口令算法先输入口令）
+				return enterPasswordOrProcess(m)
 			} else {
 				// 文件输出，进入输出目录选择
 				m.state = stateOutput
@@ -162,7 +183,7 @@ func (e *EncryptFeatureInterface) HandleTextInput(m Model, msg tea.KeyMsg) (Mode
 	}
 
 	onEscape := func(m Model) Model {
-		HandleEscapeToState(&m, stateOutputFormat, []string{"📁 保存为文件", "🔤 输出十六进制"})
+		HandleEscapeToState(&m, stateOutputFormat, getOutputFormatChoices(m))
 		m.textArea.Reset()
 		return m
 	}
@@ -179,10 +200,12 @@ func (e *EncryptFeatureInterface) HandleTextInput(m Model, msg tea.KeyMsg) (Mode
 // HandleOutputFormat 处理加密流程的输出格式选择
 func (e *EncryptFeatureInterface) HandleOutputFormat(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
 	onConfirm := func(m Model) (Model, tea.Cmd) {
-		if m.cursor == 0 {
-			m.outputFormat = "file"
-		} else {
-			m.outputFormat = "hex"
+		// 选择项与 outputFormats 顺序一致，不支持的格式已被过滤
+		choice := m.choices[m.cursor]
+		for _, format := range outputFormats {
+			if format.choice == choice {
+				m.outputFormat = format.value
+			}
 		}
 		m.state = stateTextInput
 		// 激活文本区域组件
@@ -214,9 +237,7 @@ func (e *EncryptFeatureInterface) HandleOutput(m Model, msg tea.KeyMsg) (Model,
 	onSubmit := func(m Model) (Model, tea.Cmd) {
 		outputPath := strings.TrimSpace(m.outputInput.Value())
 		m.outputInput.SetValue(outputPath)
-		m.state = stateProcessing
-		m.progress = 0.0
-		return m, e.startProcessing(m)
+		return enterPasswordOrProcess(m)
 	}
 
 	onEscape := func(m Model) Model {
@@ -244,10 +265,3 @@ func (e *EncryptFeatureInterface) HandleOutput(m Model, msg tea.KeyMsg) (Model,
 	m.outputInput, cmd = m.outputInput.Update(msg)
 	return m, cmd
 }
-
-// startProcessing 开始加密处理流程
-func (e *EncryptFeatureInterface) startProcessing(m Model) tea.Cmd {
-	return tea.Tick(time.Millisecond*50, func(t time.Time) tea.Msg {
-		return progressMsg(m.progress + 0.02)
-	})
-}
@@ -6,13 +6,17 @@ import (
 
 // EncryptFlowManager 加密流程管理器
 type EncryptFlowManager struct {
-	encryptFeature *EncryptFeatureInterface
+	encryptFeature  *EncryptFeatureInterface
+	keyringFeature  *KeyringFeatureStruct
+	passwordFeature *PasswordFeatureStruct
 }
 
 // NewEncryptFlowManager 创建加密流程管理器
 func NewEncryptFlowManager() *EncryptFlowManager {
 	return &EncryptFlowManager{
-		encryptFeature: EncryptFeature(),
+		encryptFeature:  EncryptFeature(),
+		keyringFeature:  KeyringFeature(),
+		passwordFeature: PasswordFeature(),
 	}
 }
 
@@ -21,6 +25,8 @@ func (f *EncryptFlowManager) HandleEncryptFlow(m Model, msg tea.KeyMsg) (Model,
 	switch m.state {
 	case stateAlgorithm:
 		return f.encryptFeature.HandleAlgorithm(m, msg)
+	case stateKeySelection:
+		return f.keyringFeature.HandleKeySelection(m, msg)
 	case stateInputType:
 		return f.encryptFeature.HandleInputType(m, msg)
 	case stateFileInput:
@@ -31,6 +37,8 @@ func (f *EncryptFlowManager) HandleEncryptFlow(m Model, msg tea.KeyMsg) (Model,
 		return f.encryptFeature.HandleOutputFormat(m, msg)
 	case stateOutput:
 		return f.encryptFeature.HandleOutput(m, msg)
+	case statePasswordInput:
+		return f.passwordFeature.HandlePasswordInput(m, msg)
 	default:
 		return m, nil
 	}
@@ -46,5 +54,6 @@ func (f *EncryptFlowManager) HandleMainMenuEncrypt(m Model) Model {
 	m.pathInput.Reset()
 	m.textArea.Reset()
 	m.outputInput.Reset()
+	clearPassword(&m)
 	return m
 }
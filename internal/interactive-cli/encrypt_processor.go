@@ -7,7 +7,8 @@ import (
 	"strings"
 	"time"
 
-	"hycrypt/internal/constants"
+	"hycrypt/internal/crypto"
+	"hycrypt/internal/domain"
 )
 
 // EncryptProcessor 加密处理器
@@ -29,7 +30,7 @@ func (p *EncryptProcessor) ProcessEncryption(m Model) operationResult {
 		m.config.Encryption.Method = originalMethod
 	}()
 
-	cryptoService, err := CryptoService(m.config)
+	cryptoService, err := CryptoServiceWithPassword(m.config, m.password)
 	if err != nil {
 		return newOperationResult(false, fmt.Sprintf("初始化加密服务失败: %v", err))
 	}
@@ -44,6 +45,11 @@ func (p *EncryptProcessor) ProcessEncryption(m Model) operationResult {
 		return p.processTextEncryptionHex(m, startTime)
 	}
 
+	// 处理文本加密的 JWE 输出
+	if m.inputType == "text" && isJWEFormat(m.outputFormat) {
+		return p.processTextEncryptionJWE(m, startTime)
+	}
+
 	// 准备目标路径
 	if m.inputType == "text" {
 		textContent := m.textArea.Value()
@@ -134,7 +140,7 @@ func (p *EncryptProcessor) processTextEncryptionHex(m Model, startTime time.Time
 	}()
 
 	// 创建加密服务
-	cryptoService, err := CryptoService(m.config)
+	cryptoService, err := CryptoServiceWithPassword(m.config, m.password)
 	if err != nil {
 		return newOperationResult(false, fmt.Sprintf("初始化加密服务失败: %v", err))
 	}
@@ -143,16 +149,11 @@ func (p *EncryptProcessor) processTextEncryptionHex(m Model, startTime time.Time
 	var encryptedData []byte
 	plaintext := []byte(textContent)
 
-	// 根据不同的加密方法进行加密
-	switch m.config.Encryption.Method {
-	case constants.AlgorithmRSA:
-		encryptedData, err = encryptTextWithRSA(cryptoService, plaintext)
-	case constants.AlgorithmKMAC:
-		encryptedData, err = encryptTextWithKMAC(cryptoService, plaintext)
-	default:
+	// 使用注册表中的加密方法进行加密
+	if !crypto.IsRegisteredAlgorithm(m.config.Encryption.Method) {
 		return newOperationResult(false, fmt.Sprintf("不支持的加密方法: %s", m.config.Encryption.Method))
 	}
-
+	encryptedData, err = encryptTextWithMethod(cryptoService, plaintext, m.config.Encryption.Method)
 	if err != nil {
 		return newOperationResult(false, fmt.Sprintf("加密失败: %v", err))
 	}
@@ -176,3 +177,46 @@ func (p *EncryptProcessor) processTextEncryptionHex(m Model, startTime time.Time
 		},
 	}
 }
+
+// processTextEncryptionJWE 处理文本加密的 JWE 输出，结果在终端显示
+func (p *EncryptProcessor) processTextEncryptionJWE(m Model, startTime time.Time) operationResult {
+	textContent := m.textArea.Value()
+
+	if strings.TrimSpace(textContent) == "" {
+		return newOperationResult(false, "输入为空")
+	}
+
+	// 更新配置中的算法为用户选择的算法
+	originalMethod := m.config.Encryption.Method
+	m.config.Encryption.Method = m.algorithm
+	defer func() {
+		m.config.Encryption.Method = originalMethod
+	}()
+
+	cryptoService, err := CryptoServiceWithPassword(m.config, m.password)
+	if err != nil {
+		return newOperationResult(false, fmt.Sprintf("初始化加密服务失败: %v", err))
+	}
+
+	outputFormat := domain.OutputJWE
+	if m.outputFormat == "jwe-json" {
+		outputFormat = domain.OutputJWEJSON
+	}
+	jweOutput, err := encryptTextJWE(cryptoService, []byte(textContent), m.algorithm, outputFormat)
+	if err != nil {
+		return newOperationResult(false, fmt.Sprintf("加密失败: %v", err))
+	}
+
+	// 与十六进制输出共用结果视图，FileName 区分输出格式
+	return operationResult{
+		success: true,
+		message: "HEX_OUTPUT_TRIGGER",
+		resultInfo: EncryptionResult{
+			FileName:       "JWE_OUTPUT",
+			FileSize:       0,
+			Algorithm:      strings.ToUpper(m.algorithm),
+			EncryptionTime: time.Since(startTime).String(),
+			OutputPath:     fmt.Sprintf("%s|%s|%s", jweOutput, textContent, strings.ToUpper(m.algorithm)),
+		},
+	}
+}
@@ -20,8 +20,10 @@ type FlowManagerInterface struct {
 	decryptFlowManager *DecryptFlowManager
 
 	// 功能模块
-	keygenFeature *KeygenFeatureStruct
-	configFeature *ConfigFeatureStruct
+	keygenFeature   *KeygenFeatureStruct
+	shareFeature    *ShareFeatureStruct
+	mnemonicFeature *MnemonicFeatureStruct
+	configFeature   *ConfigFeatureStruct
 }
 
 // FlowManager 创建流程管理器
@@ -37,8 +39,10 @@ func FlowManager() *FlowManagerInterface {
 		decryptFlowManager: NewDecryptFlowManager(),
 
 		// 初始化功能模块
-		keygenFeature: KeygenFeature(),
-		configFeature: ConfigFeature(),
+		keygenFeature:   KeygenFeature(),
+		shareFeature:    ShareFeature(),
+		mnemonicFeature: MnemonicFeature(),
+		configFeature:   ConfigFeature(),
 	}
 }
 
@@ -56,6 +60,8 @@ func (f *FlowManagerInterface) HandleUpdate(m Model, msg tea.Msg) (Model, tea.Cm
 		}
 		return m, nil
 	case operationResult:
+		// 操作结束后不再保留口令
+		clearPassword(&m)
 		// 特殊处理：十六进制输出
 		if msg.success && msg.message == "HEX_OUTPUT_TRIGGER" {
 			// 切换到十六进制输出完成状态
@@ -99,6 +105,8 @@ func (f *FlowManagerInterface) HandleView(m Model) string {
 		return f.viewRenderer.RenderMainMenu(m)
 	case stateAlgorithm:
 		return f.viewRenderer.RenderAlgorithm(m)
+	case stateKeySelection:
+		return f.viewRenderer.RenderKeySelection(m)
 	case stateInputType:
 		return f.viewRenderer.RenderInputType(m)
 	case stateFileInput:
@@ -109,6 +117,8 @@ func (f *FlowManagerInterface) HandleView(m Model) string {
 		return f.viewRenderer.RenderOutputFormat(m)
 	case stateOutput:
 		return f.viewRenderer.RenderOutput(m)
+	case statePasswordInput:
+		return f.viewRenderer.RenderPasswordInput(m)
 	case stateProcessing:
 		return f.viewRenderer.RenderProcessing(m)
 	case stateComplete:
@@ -117,6 +127,20 @@ func (f *FlowManagerInterface) HandleView(m Model) string {
 		return f.viewRenderer.RenderHexOutputComplete(m)
 	case stateKeyGeneration:
 		return f.viewRenderer.RenderKeyGeneration(m)
+	case stateShareTarget:
+		return f.viewRenderer.RenderShareTarget(m)
+	case stateShareParams:
+		return f.viewRenderer.RenderShareParams(m)
+	case stateShareOutput:
+		return f.viewRenderer.RenderShareOutput(m)
+	case stateShareCombine:
+		return f.viewRenderer.RenderShareCombine(m)
+	case stateMnemonicPassphrase:
+		return f.viewRenderer.RenderMnemonicPassphrase(m)
+	case stateMnemonicShow:
+		return f.viewRenderer.RenderMnemonicShow(m)
+	case stateMnemonicInput:
+		return f.viewRenderer.RenderMnemonicInput(m)
 	default:
 		return "未知状态"
 	}
@@ -205,10 +229,22 @@ func (f *FlowManagerInterface) handleKeygenFlow(m Model, msg tea.KeyMsg) (Model,
 	switch m.state {
 	case stateKeyGeneration:
 		return f.keygenFeature.HandleKeyGeneration(m, msg)
-	case stateRSAKeyConfirm:
-		return f.keygenFeature.HandleRSAKeyConfirm(m, msg)
-	case stateKMACKeyConfirm:
-		return f.keygenFeature.HandleKMACKeyConfirm(m, msg)
+	case stateKeyConfirm:
+		return f.keygenFeature.HandleKeyConfirm(m, msg)
+	case stateShareTarget:
+		return f.shareFeature.HandleShareTarget(m, msg)
+	case stateShareParams:
+		return f.shareFeature.HandleShareParams(m, msg)
+	case stateShareOutput:
+		return f.shareFeature.HandleShareOutput(m, msg)
+	case stateShareCombine:
+		return f.shareFeature.HandleShareCombine(m, msg)
+	case stateMnemonicPassphrase:
+		return f.mnemonicFeature.HandleMnemonicPassphrase(m, msg)
+	case stateMnemonicShow:
+		return f.mnemonicFeature.HandleMnemonicShow(m, msg)
+	case stateMnemonicInput:
+		return f.mnemonicFeature.HandleMnemonicInput(m, msg)
 	default:
 		return f.handleCommonStates(m, msg)
 	}
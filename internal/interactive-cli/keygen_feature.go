@@ -1,11 +1,9 @@
 package interactivecli
 
 import (
-	"strings"
-
 	tea "github.com/charmbracelet/bubbletea"
 
-	"hycrypt/internal/constants"
+	"hycrypt/internal/crypto"
 )
 
 // KeygenFeatureStruct 密钥生成功能处理器
@@ -36,67 +34,32 @@ func (k *KeygenFeatureStruct) HandleKeyGeneration(m Model, msg tea.KeyMsg) (Mode
 			m.cursor++
 		}
 	case "enter", " ":
-		supportedAlgorithms := m.config.GetSupportedAlgorithms()
-		if m.cursor < len(supportedAlgorithms) {
-			algorithm := supportedAlgorithms[m.cursor]
-			switch strings.ToLower(algorithm) {
-			case constants.AlgorithmRSA:
-				// 生成 RSA 密钥对
-				if m.checkRSAKeysExist() {
-					// 密钥已存在，询问是否覆盖
-					m.state = stateRSAKeyConfirm
-				} else {
-					// 直接生成
-					m.state = stateProcessing
-					return m, tea.Cmd(func() tea.Msg {
-						return m.generateRSAKeys(false)
-					})
-				}
-			case constants.AlgorithmKMAC:
-				// 生成 KMAC 密钥
-				if m.checkKMACKeyExists() {
-					// 密钥已存在，询问是否覆盖
-					m.state = stateKMACKeyConfirm
-				} else {
-					// 直接生成
-					m.state = stateProcessing
-					return m, tea.Cmd(func() tea.Msg {
-						return m.generateKMACKey(false)
-					})
-				}
+		algorithms := getKeyGenAlgorithms(m.config)
+		if m.cursor < len(algorithms) {
+			algorithm := algorithms[m.cursor]
+			m.algorithm = algorithm.Name
+			if m.config.CheckAlgorithmKeysExist(algorithm) {
+				// 密钥已存在，询问是否覆盖
+				m.state = stateKeyConfirm
+			} else {
+				// 直接生成
+				m.state = stateProcessing
+				return m, tea.Cmd(func() tea.Msg {
+					return m.generateKeys(algorithm)
+				})
 			}
+		} else if index := m.cursor - len(algorithms); index < len(shareMenuChoices) {
+			// 算法之后依次是分片与助记词操作
+			return ShareFeature().HandleShareMenu(m, index)
+		} else {
+			return MnemonicFeature().HandleMnemonicMenu(m, index-len(shareMenuChoices))
 		}
 	}
 	return m, nil
 }
 
-// HandleRSAKeyConfirm 处理RSA密钥确认
-func (k *KeygenFeatureStruct) HandleRSAKeyConfirm(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q":
-		m.quitting = true
-		return m, tea.Quit
-	case "esc":
-		m.state = stateKeyGeneration
-		m.choices = getKeyGenMenuChoices(m.config)
-		m.cursor = 0
-	case "y", "Y":
-		// 确认覆盖，开始生成
-		m.state = stateProcessing
-		return m, tea.Cmd(func() tea.Msg {
-			return m.generateRSAKeys(true)
-		})
-	case "n", "N":
-		// 取消操作，返回密钥生成菜单
-		m.state = stateKeyGeneration
-		m.choices = getKeyGenMenuChoices(m.config)
-		m.cursor = 0
-	}
-	return m, nil
-}
-
-// HandleKMACKeyConfirm 处理KMAC密钥确认
-func (k *KeygenFeatureStruct) HandleKMACKeyConfirm(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+// HandleKeyConfirm 处理密钥覆盖确认
+func (k *KeygenFeatureStruct) HandleKeyConfirm(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "q":
 		m.quitting = true
@@ -107,9 +70,13 @@ func (k *KeygenFeatureStruct) HandleKMACKeyConfirm(m Model, msg tea.KeyMsg) (Mod
 		m.cursor = 0
 	case "y", "Y":
 		// 确认覆盖，开始生成
+		algorithm, ok := crypto.LookupAlgorithm(m.algorithm)
+		if !ok {
+			return m, nil
+		}
 		m.state = stateProcessing
 		return m, tea.Cmd(func() tea.Msg {
-			return m.generateKMACKey(true)
+			return m.generateKeys(algorithm)
 		})
 	case "n", "N":
 		// 取消操作，返回密钥生成菜单
@@ -0,0 +1,113 @@
+package interactivecli
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"hycrypt/internal/config"
+	"hycrypt/internal/crypto"
+)
+
+// getKeyNames 获取当前算法在密钥环中的密钥名称，口令类算法没有密钥文件
+func getKeyNames(m Model) []string {
+	algorithm, ok := crypto.LookupAlgorithm(m.algorithm)
+	if !ok || algorithm.Fingerprint == nil {
+		return nil
+	}
+	return m.config.KeyNames(algorithm)
+}
+
+// getKeySelectionChoices 获取密钥选择项，显示名称与指纹并标记默认密钥
+func getKeySelectionChoices(m Model) []string {
+	algorithm, _ := crypto.LookupAlgorithm(m.algorithm)
+	defaultName := m.config.Keys.DefaultKeys[m.algorithm]
+	if defaultName == "" {
+		defaultName = config.DefaultKeyName
+	}
+
+	names := getKeyNames(m)
+	choices := make([]string, len(names))
+	for i, name := range names {
+		choices[i] = "🔑 " + name + "（指纹 " + namedKeyFingerprintText(m.config, algorithm, name) + "）"
+		if name == defaultName {
+			choices[i] += " - 默认"
+		}
+	}
+	return choices
+}
+
+// keySourceText 返回当前密钥来源说明，使用密钥环中的命名密钥时显示密钥名称
+func keySourceText(m Model) string {
+	if algorithm, ok := crypto.LookupAlgorithm(m.algorithm); ok && algorithm.Fingerprint != nil {
+		if name := m.config.KeyName(m.algorithm); name != config.DefaultKeyName {
+			return "密钥来源: 密钥环 " + name
+		}
+	}
+	return "密钥来源: 本地密钥文件"
+}
+
+// enterKeySelection 密钥环中有多个密钥时进入密钥选择，否则直接进入输入类型选择
+func enterKeySelection(m Model) Model {
+	names := getKeyNames(m)
+	if len(names) <= 1 {
+		m.state = stateInputType
+		m.choices = inputTypeEncryptChoices
+		m.cursor = 0
+		return m
+	}
+
+	m.state = stateKeySelection
+	m.choices = getKeySelectionChoices(m)
+	m.cursor = 0
+	current := m.config.KeyName(m.algorithm)
+	for i, name := range names {
+		if name == current {
+			m.cursor = i
+		}
+	}
+	return m
+}
+
+// KeyringFeatureStruct 密钥选择功能处理器
+type KeyringFeatureStruct struct {
+	keyHandler *CommonKeyHandlerStruct
+}
+
+// KeyringFeature 创建密钥选择功能处理器
+func KeyringFeature() *KeyringFeatureStruct {
+	return &KeyringFeatureStruct{
+		keyHandler: CommonKeyHandler(),
+	}
+}
+
+// HandleKeySelection 处理加密流程的密钥选择，选择在本次运行中保持
+func (k *KeyringFeatureStruct) HandleKeySelection(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	onConfirm := func(m Model) (Model, tea.Cmd) {
+		names := getKeyNames(m)
+		if m.cursor < len(names) {
+			m.config.SelectKey(m.algorithm, names[m.cursor])
+		}
+		m.state = stateInputType
+		m.choices = inputTypeEncryptChoices
+		m.cursor = 0
+		return m, nil
+	}
+
+	onEscape := func(m Model) Model {
+		HandleEscapeToState(&m, stateAlgorithm, getEncryptAlgorithmChoices(m))
+		return m
+	}
+
+	newM, cmd, handled := k.keyHandler.HandleMenuKeys(m, msg, onConfirm, onEscape)
+	if handled {
+		return newM, cmd
+	}
+
+	return m, nil
+}
+
+// selectMatchingKey 按加密文件头部记录的接收方指纹选择本地密钥，未找到时保持当前密钥
+func selectMatchingKey(m Model, path string) {
+	if method, name, ok := m.config.MatchingKeyForFile(path); ok && method == m.algorithm {
+		m.config.SelectKey(method, name)
+	}
+}
@@ -12,26 +12,46 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"hycrypt/internal/config"
-	"hycrypt/internal/constants"
+	"hycrypt/internal/crypto"
 )
 
+// algorithmChoice 根据算法注册表生成菜单项，如 "🔐 RSA 加密"
+func algorithmChoice(name, action string) string {
+	if algorithm, ok := crypto.LookupAlgorithm(strings.ToLower(name)); ok {
+		return algorithm.Icon + " " + algorithm.DisplayName + " " + action
+	}
+	return "🔒 " + strings.ToUpper(name) + " " + action
+}
+
 // getKeyGenMenuChoices 从配置中获取密钥生成菜单选择项
 func getKeyGenMenuChoices(cfg *config.Config) []string {
-	supportedAlgorithms := cfg.GetSupportedAlgorithms()
+	supportedAlgorithms := getKeyGenAlgorithms(cfg)
 	choices := make([]string, len(supportedAlgorithms))
 
 	for i, algorithm := range supportedAlgorithms {
-		switch strings.ToLower(algorithm) {
-		case constants.AlgorithmRSA:
-			choices[i] = "🔐 生成 RSA-4096 密钥对"
-		case constants.AlgorithmKMAC:
-			choices[i] = "🔑 生成 KMAC 密钥"
-		default:
-			choices[i] = "🔒 生成 " + strings.ToUpper(algorithm) + " 密钥"
+		kind := "密钥"
+		if len(algorithm.KeyFiles) > 1 {
+			kind = "密钥对"
+		}
+		choices[i] = algorithm.Icon + " 生成 " + algorithm.DisplayName + " " + kind
+		if cfg.CheckAlgorithmKeysExist(algorithm) {
+			choices[i] += "（当前指纹 " + keyFingerprintText(cfg, algorithm) + "）"
 		}
 	}
 
-	return choices
+	choices = append(choices, shareMenuChoices...)
+	return append(choices, mnemonicMenuChoices...)
+}
+
+// getKeyGenAlgorithms 获取需要密钥文件的已注册算法（口令算法无需生成密钥）
+func getKeyGenAlgorithms(cfg *config.Config) []*crypto.Algorithm {
+	var algorithms []*crypto.Algorithm
+	for _, name := range cfg.GetSupportedAlgorithms() {
+		if algorithm, ok := crypto.LookupAlgorithm(strings.ToLower(name)); ok && algorithm.GenerateKeys != nil {
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+	return algorithms
 }
 
 // UI 状态
@@ -54,17 +74,25 @@ const (
 	statePrivacyToggle          // 隐私输出开关设置
 	stateCleanupConfirm         // 清理隐私目录确认
 	stateAlgorithm              // 1. 选择加密算法
+	stateKeySelection           // 1.5. 选择密钥（密钥环中有多个密钥时）
 	stateInputType              // 2. 选择加密模式
 	stateFileInput              // 3. 选择文件路径
 	stateTextInput              // 3. 输入文本内容
 	stateOutputFormat           // 3.5. 选择输出格式（文本加密时）
 	stateOutput
+	statePasswordInput // 4.5. 输入口令（password 算法）
 	stateKeyGeneration
-	stateRSAKeyConfirm     // RSA 密钥覆盖确认
-	stateKMACKeyConfirm    // KMAC 密钥覆盖确认
-	stateProcessing        // 4. 显示进度条
-	stateHexOutputComplete // 十六进制输出完成状态
-	stateComplete          // 5. 显示结果
+	stateKeyConfirm         // 密钥覆盖确认
+	stateShareTarget        // 选择要拆分的密钥文件
+	stateShareParams        // 输入分片门限与份数
+	stateShareOutput        // 设置分片输出目录
+	stateShareCombine       // 输入要合并的分片
+	stateMnemonicPassphrase // 输入 KMAC 密钥口令以导出助记词
+	stateMnemonicShow       // 显示助记词
+	stateMnemonicInput      // 输入助记词恢复 KMAC 密钥
+	stateProcessing         // 4. 显示进度条
+	stateHexOutputComplete  // 十六进制输出完成状态
+	stateComplete           // 5. 显示结果
 )
 
 // Model Bubble Tea 模型
@@ -75,15 +103,29 @@ type Model struct {
 	cursor  int
 
 	// 输入组件
-	pathInput   textinput.Model
-	textArea    textarea.Model
-	outputInput textinput.Model
+	pathInput     textinput.Model
+	textArea      textarea.Model
+	outputInput   textinput.Model
+	passwordInput textinput.Model
 
 	// 用户选择
 	operation    string // "encrypt", "decrypt", "generate-keys", "config"
-	algorithm    string // constants.AlgorithmRSA, constants.AlgorithmKMAC
+	algorithm    string // 已注册的算法名称
 	inputType    string // "file", "text"
-	outputFormat string // "file", "hex" (for text encryption)
+	outputFormat string // "file", "hex", "jwe", "jwe-json" (for text encryption)
+
+	// 口令（password 算法），处理完成后清除
+	password        string
+	pendingPassword string // 加密时第一次输入的口令，等待确认
+	passwordError   string
+
+	// 密钥分片（Shamir）
+	shareKeyPath   string // 待拆分的密钥文件
+	shareThreshold int
+	shareTotal     int
+
+	mnemonic   string // 待显示的助记词，离开页面时清除
+	inputError string // 分片参数、助记词等输入的校验错误
 
 	// UI 状态
 	quitting     bool
@@ -175,6 +217,8 @@ func InitialModel(cfg *config.Config) Model {
 	textArea.Placeholder = "Enter text to encrypt..."
 	textArea.SetWidth(60)
 	textArea.SetHeight(5)
+	// 不限制长度：粘贴的十六进制和 JWE 密文常超过默认的 400 字符
+	textArea.CharLimit = 0
 
 	// 初始化输出目录输入组件
 	outputInput := textinput.New()
@@ -182,6 +226,14 @@ func InitialModel(cfg *config.Config) Model {
 	outputInput.CharLimit = 300
 	outputInput.Width = 60
 
+	// 初始化口令输入组件（不回显）
+	passwordInput := textinput.New()
+	passwordInput.Placeholder = "Password..."
+	passwordInput.CharLimit = 256
+	passwordInput.Width = 60
+	passwordInput.EchoMode = textinput.EchoPassword
+	passwordInput.EchoCharacter = '•'
+
 	// 检查是否需要配置初始化
 	var initialState uiState
 	var initialChoices []string
@@ -198,14 +250,15 @@ func InitialModel(cfg *config.Config) Model {
 	}
 
 	return Model{
-		state:        initialState,
-		config:       cfg,
-		choices:      initialChoices,
-		pathInput:    pathInput,
-		textArea:     textArea,
-		outputInput:  outputInput,
-		outputFormat: "file",        // 默认为文件输出
-		flowManager:  FlowManager(), // 初始化流程管理器
+		state:         initialState,
+		config:        cfg,
+		choices:       initialChoices,
+		pathInput:     pathInput,
+		textArea:      textArea,
+		outputInput:   outputInput,
+		passwordInput: passwordInput,
+		outputFormat:  "file",        // 默认为文件输出
+		flowManager:   FlowManager(), // 初始化流程管理器
 	}
 }
 
@@ -273,10 +326,8 @@ func (m Model) View() string {
 		return m.viewPrivacyToggle()
 	case stateCleanupConfirm:
 		return m.viewCleanupConfirm()
-	case stateRSAKeyConfirm:
-		return m.viewRSAKeyConfirm()
-	case stateKMACKeyConfirm:
-		return m.viewKMACKeyConfirm()
+	case stateKeyConfirm:
+		return m.viewKeyConfirm()
 	default:
 		// 其他状态使用FlowManager处理
 		if m.flowManager == nil {
@@ -0,0 +1,160 @@
+package interactivecli
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"hycrypt/internal/constants"
+	"hycrypt/internal/crypto"
+)
+
+// 密钥生成菜单中分片操作之后的助记词操作
+var mnemonicMenuChoices = []string{"📝 导出 KMAC 密钥助记词", "📝 从助记词恢复 KMAC 密钥"}
+
+// kmacAlgorithm 助记词菜单操作的 KMAC 算法
+func kmacAlgorithm() *crypto.Algorithm {
+	algorithm, _ := crypto.LookupAlgorithm(constants.AlgorithmKMAC)
+	return algorithm
+}
+
+// MnemonicFeatureStruct 助记词备份功能处理器
+type MnemonicFeatureStruct struct{}
+
+// MnemonicFeature 创建助记词备份功能处理器
+func MnemonicFeature() *MnemonicFeatureStruct {
+	return &MnemonicFeatureStruct{}
+}
+
+// HandleMnemonicMenu 处理密钥生成菜单中的助记词操作，index 为操作在 mnemonicMenuChoices 中的序号
+func (f *MnemonicFeatureStruct) HandleMnemonicMenu(m Model, index int) (Model, tea.Cmd) {
+	switch index {
+	case 0:
+		if !m.config.CheckKMACKeyExists() {
+			return showError(m, "未找到 KMAC 密钥文件: "+m.config.GetKMACKeyPath())
+		}
+		// 受保护的密钥且没有无需交互的口令来源时先输入口令
+		if m.config.IsKMACKeyProtected() && !m.config.HasKeyPassphraseSource() {
+			m.state = stateMnemonicPassphrase
+			m.passwordInput.Reset()
+			m.passwordInput.Focus()
+			m.passwordError = ""
+			return m, nil
+		}
+		words, err := m.config.KeyMnemonic(kmacAlgorithm(), m.config.KeyPassphraseSource(nil))
+		if err != nil {
+			return showError(m, fmt.Sprintf("导出助记词失败: %v", err))
+		}
+		m.state = stateMnemonicShow
+		m.mnemonic = words
+	case 1:
+		if m.config.CheckKMACKeyExists() {
+			return showError(m, "KMAC 密钥文件已存在，恢复前请先移走现有文件: "+m.config.GetKMACKeyPath())
+		}
+		m.state = stateMnemonicInput
+		m.textArea.Reset()
+		m.textArea.Focus()
+		m.pathInput.Blur()
+		m.outputInput.Blur()
+		m.inputError = ""
+	}
+	m.choices = []string{}
+	m.cursor = 0
+	return m, nil
+}
+
+// HandleMnemonicPassphrase 处理导出助记词前的 KMAC 密钥口令输入
+func (f *MnemonicFeatureStruct) HandleMnemonicPassphrase(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case KeyQuit:
+		clearPassword(&m)
+		return HandleQuitAction(&m)
+	case KeyEscape:
+		clearPassword(&m)
+		m.passwordInput.Blur()
+		return backToKeyGeneration(m), nil
+	case KeyConfirm:
+		value := m.passwordInput.Value()
+		if value == "" {
+			m.passwordError = "口令不能为空"
+			return m, nil
+		}
+		words, err := m.config.KeyMnemonic(kmacAlgorithm(), func(string) ([]byte, error) {
+			return []byte(value), nil
+		})
+		clearPassword(&m)
+		if err != nil {
+			m.passwordError = err.Error()
+			return m, nil
+		}
+		m.passwordInput.Blur()
+		m.state = stateMnemonicShow
+		m.mnemonic = words
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.passwordInput, cmd = m.passwordInput.Update(msg)
+	return m, cmd
+}
+
+// HandleMnemonicShow 处理助记词显示页面，任意键清除助记词并返回
+func (f *MnemonicFeatureStruct) HandleMnemonicShow(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	m.mnemonic = ""
+	if msg.String() == KeyQuit {
+		return HandleQuitAction(&m)
+	}
+	return backToKeyGeneration(m), nil
+}
+
+// HandleMnemonicInput 处理助记词输入，Ctrl+D 校验并恢复，校验失败时保留输入以便修改
+func (f *MnemonicFeatureStruct) HandleMnemonicInput(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case KeyQuit:
+		m.textArea.Reset()
+		return HandleQuitAction(&m)
+	case KeyEscape:
+		m.textArea.Reset()
+		m.textArea.Blur()
+		m.inputError = ""
+		return backToKeyGeneration(m), nil
+	case KeySubmit:
+		if strings.TrimSpace(m.textArea.Value()) == "" {
+			return m, nil
+		}
+		if err := m.config.RestoreKeyFromMnemonic(kmacAlgorithm(), m.textArea.Value(), nil); err != nil {
+			m.inputError = err.Error()
+			return m, nil
+		}
+		m.textArea.Reset()
+		m.textArea.Blur()
+		m.inputError = ""
+		m.state = stateComplete
+		m.result = "已从助记词恢复 KMAC 密钥: " + m.config.GetKMACKeyPath() + "\n可使用 hycrypt keys protect kmac 为密钥设置口令"
+		m.error = ""
+		m.firstDisplay = true
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textArea, cmd = m.textArea.Update(msg)
+	return m, cmd
+}
+
+// showError 显示错误结果
+func showError(m Model, message string) (Model, tea.Cmd) {
+	m.state = stateComplete
+	m.error = message
+	m.result = ""
+	m.firstDisplay = true
+	return m, nil
+}
+
+// backToKeyGeneration 返回密钥生成菜单
+func backToKeyGeneration(m Model) Model {
+	m.state = stateKeyGeneration
+	m.choices = getKeyGenMenuChoices(m.config)
+	m.cursor = 0
+	return m
+}
@@ -0,0 +1,150 @@
+package interactivecli
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"hycrypt/internal/crypto"
+)
+
+// PasswordFeatureStruct 口令输入功能处理器
+type PasswordFeatureStruct struct{}
+
+// PasswordFeature 创建口令输入功能处理器
+func PasswordFeature() *PasswordFeatureStruct {
+	return &PasswordFeatureStruct{}
+}
+
+// usesPasswordAlgorithm 当前算法是否为口令类算法
+func usesPasswordAlgorithm(m Model) bool {
+	algorithm, ok := crypto.LookupAlgorithm(m.algorithm)
+	return ok && algorithm.NeedsPassword
+}
+
+// protectedKeyPath 当前操作需要解锁的受口令保护密钥文件，无需解锁或已配置环境变量、passphrase_command 时返回空
+func protectedKeyPath(m Model) string {
+	if m.config.HasKeyPassphraseSource() {
+		return ""
+	}
+
+	algorithm := m.algorithm
+	if m.operation == "decrypt" && m.inputType == "text" {
+		if method := crypto.DetectJWEMethod(m.textArea.Value()); method != "" {
+			algorithm = method
+		}
+	}
+
+	// 使用密钥对的算法只有解密需要私钥，对称密钥加解密都需要
+	registered, ok := crypto.LookupAlgorithm(algorithm)
+	if !ok {
+		return ""
+	}
+	for _, keyFile := range registered.KeyFilesFor(m.operation == "decrypt") {
+		if path := m.config.GetKeyFilePath(keyFile.Name); keyFile.Private && crypto.IsProtectedKeyFile(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// needsPassword 当前操作是否需要输入口令（口令类算法或受保护的密钥文件）
+func needsPassword(m Model) bool {
+	return usesPasswordAlgorithm(m) || protectedKeyPath(m) != ""
+}
+
+// enterPasswordOrProcess 口令算法先进入口令输入，其余算法直接开始处理
+func enterPasswordOrProcess(m Model) (Model, tea.Cmd) {
+	if needsPassword(m) {
+		m.state = statePasswordInput
+		m.passwordInput.Reset()
+		m.passwordInput.Focus()
+		m.pendingPassword = ""
+		m.passwordError = ""
+		m.pathInput.Blur()
+		m.textArea.Blur()
+		m.outputInput.Blur()
+		return m, nil
+	}
+
+	m.state = stateProcessing
+	m.progress = 0.0
+	return m, startProgress(m)
+}
+
+// clearPassword 清除模型中保存的口令
+func clearPassword(m *Model) {
+	m.password = ""
+	m.pendingPassword = ""
+	m.passwordError = ""
+	m.passwordInput.Reset()
+}
+
+// HandlePasswordInput 处理口令输入
+//
+// 口令输入框不使用通用输入按键映射，避免 q、空格等字符被当作退出或确认。
+func (p *PasswordFeatureStruct) HandlePasswordInput(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case KeyQuit:
+		clearPassword(&m)
+		return HandleQuitAction(&m)
+	case KeyEscape:
+		clearPassword(&m)
+		m.passwordInput.Blur()
+		if m.inputType == "text" && (m.operation == "decrypt" || m.outputFormat != "file") {
+			m.state = stateTextInput
+			m.textArea.Focus()
+		} else {
+			m.state = stateOutput
+			m.outputInput.Focus()
+		}
+		return m, nil
+	case KeyConfirm:
+		return p.submit(m)
+	}
+
+	m.passwordInput, cmd = m.passwordInput.Update(msg)
+	return m, cmd
+}
+
+// submit 提交口令，口令类算法加密时需要再次输入确认
+func (p *PasswordFeatureStruct) submit(m Model) (Model, tea.Cmd) {
+	value := m.passwordInput.Value()
+	if value == "" {
+		m.passwordError = "口令不能为空"
+		return m, nil
+	}
+
+	if m.operation == "encrypt" && usesPasswordAlgorithm(m) {
+		if m.pendingPassword == "" {
+			m.pendingPassword = value
+			m.passwordError = ""
+			m.passwordInput.Reset()
+			return m, nil
+		}
+		if m.pendingPassword != value {
+			m.pendingPassword = ""
+			m.passwordError = "两次输入的口令不一致，请重新输入"
+			m.passwordInput.Reset()
+			return m, nil
+		}
+	}
+
+	m.password = value
+	m.pendingPassword = ""
+	m.passwordError = ""
+	m.passwordInput.Reset()
+	m.passwordInput.Blur()
+	m.state = stateProcessing
+	m.progress = 0.0
+	return m, startProgress(m)
+}
+
+// startProgress 开始处理进度
+func startProgress(m Model) tea.Cmd {
+	return tea.Tick(time.Millisecond*50, func(t time.Time) tea.Msg {
+		return progressMsg(m.progress + 0.02)
+	})
+}
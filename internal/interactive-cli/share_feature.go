@@ -0,0 +1,290 @@
+package interactivecli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"hycrypt/internal/crypto"
+	"hycrypt/internal/shamir"
+)
+
+// 密钥生成菜单中算法之后的分片操作
+var shareMenuChoices = []string{"🧩 拆分密钥为 Shamir 分片", "🧩 从 Shamir 分片恢复密钥"}
+
+// shareTarget 可拆分的私钥或对称密钥文件
+type shareTarget struct {
+	label string
+	path  string
+}
+
+// getShareTargets 获取已存在且可拆分的私钥或对称密钥文件
+func getShareTargets(m Model) []shareTarget {
+	var targets []shareTarget
+	for _, algorithm := range getKeyGenAlgorithms(m.config) {
+		for _, keyFile := range algorithm.KeyFiles {
+			if !keyFile.Private {
+				continue
+			}
+			path := m.config.GetKeyFilePath(keyFile.Name)
+			if path == "" || crypto.CheckProtectableKeyFile(path) != nil {
+				continue
+			}
+			targets = append(targets, shareTarget{
+				label: algorithm.Icon + " " + algorithm.DisplayName + ": " + path,
+				path:  path,
+			})
+		}
+	}
+	return targets
+}
+
+// getShareTargetChoices 获取可拆分密钥的选择项
+func getShareTargetChoices(m Model) []string {
+	targets := getShareTargets(m)
+	choices := make([]string, len(targets))
+	for i, target := range targets {
+		choices[i] = target.label
+	}
+	return choices
+}
+
+// ShareFeatureStruct 密钥分片功能处理器
+type ShareFeatureStruct struct{}
+
+// ShareFeature 创建密钥分片功能处理器
+func ShareFeature() *ShareFeatureStruct {
+	return &ShareFeatureStruct{}
+}
+
+// HandleShareMenu 处理密钥生成菜单中的分片操作，index 为分片操作在 shareMenuChoices 中的序号
+func (s *ShareFeatureStruct) HandleShareMenu(m Model, index int) (Model, tea.Cmd) {
+	switch index {
+	case 0:
+		choices := getShareTargetChoices(m)
+		if len(choices) == 0 {
+			return showError(m, "没有可拆分的密钥文件，请先生成 RSA 或 KMAC 密钥")
+		}
+		m.state = stateShareTarget
+		m.choices = choices
+		m.cursor = 0
+	case 1:
+		m.state = stateShareCombine
+		m.textArea.Reset()
+		m.textArea.Focus()
+		m.pathInput.Blur()
+		m.outputInput.Blur()
+		m.choices = []string{}
+		m.cursor = 0
+	}
+	return m, nil
+}
+
+// HandleShareTarget 处理要拆分的密钥文件选择
+func (s *ShareFeatureStruct) HandleShareTarget(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m = backToKeyGeneration(m)
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.choices)-1 {
+			m.cursor++
+		}
+	case "enter", " ":
+		targets := getShareTargets(m)
+		if m.cursor < len(targets) {
+			m.shareKeyPath = targets[m.cursor].path
+			m.state = stateShareParams
+			m.pathInput.SetValue("3/5")
+			m.pathInput.CursorEnd()
+			m.pathInput.Focus()
+			m.inputError = ""
+		}
+	}
+	return m, nil
+}
+
+// HandleShareParams 处理门限与份数输入，格式为 门限/份数
+func (s *ShareFeatureStruct) HandleShareParams(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.state = stateShareTarget
+		m.choices = getShareTargetChoices(m)
+		m.cursor = 0
+		m.pathInput.Reset()
+		m.inputError = ""
+		return m, nil
+	case "enter":
+		threshold, total, err := parseShareParams(m.pathInput.Value())
+		if err != nil {
+			m.inputError = err.Error()
+			return m, nil
+		}
+		m.shareThreshold, m.shareTotal = threshold, total
+		m.inputError = ""
+		m.state = stateShareOutput
+		m.pathInput.Blur()
+		m.outputInput.Reset()
+		m.outputInput.Focus()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.pathInput, cmd = m.pathInput.Update(msg)
+	return m, cmd
+}
+
+// HandleShareOutput 处理分片输出目录输入，回车后开始拆分
+func (s *ShareFeatureStruct) HandleShareOutput(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.state = stateShareParams
+		m.outputInput.Blur()
+		m.pathInput.Focus()
+		return m, nil
+	case "enter":
+		m.outputInput.SetValue(strings.TrimSpace(m.outputInput.Value()))
+		m.state = stateProcessing
+		return m, tea.Cmd(func() tea.Msg {
+			return m.splitKeyShares()
+		})
+	}
+
+	var cmd tea.Cmd
+	m.outputInput, cmd = m.outputInput.Update(msg)
+	return m, cmd
+}
+
+// HandleShareCombine 处理分片输入，回车换行，Ctrl+D 开始恢复
+func (s *ShareFeatureStruct) HandleShareCombine(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.textArea.Reset()
+		m.textArea.Blur()
+		return backToKeyGeneration(m), nil
+	case "ctrl+d":
+		if strings.TrimSpace(m.textArea.Value()) == "" {
+			return m, nil
+		}
+		m.state = stateProcessing
+		return m, tea.Cmd(func() tea.Msg {
+			return m.combineKeyShares()
+		})
+	}
+
+	var cmd tea.Cmd
+	m.textArea, cmd = m.textArea.Update(msg)
+	return m, cmd
+}
+
+// parseShareParams 解析 门限/份数，如 3/5
+func parseShareParams(value string) (int, int, error) {
+	thresholdText, totalText, ok := strings.Cut(strings.TrimSpace(value), "/")
+	threshold, err1 := strconv.Atoi(strings.TrimSpace(thresholdText))
+	total, err2 := strconv.Atoi(strings.TrimSpace(totalText))
+	if !ok || err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("格式应为 门限/份数，例如 3/5")
+	}
+	if threshold < 2 || threshold > total || total > shamir.MaxShares {
+		return 0, 0, fmt.Errorf("需要 2 ≤ 门限 ≤ 份数 ≤ %d", shamir.MaxShares)
+	}
+	return threshold, total, nil
+}
+
+// splitKeyShares 拆分选中的密钥文件并写出分片
+func (m Model) splitKeyShares() operationResult {
+	shares, err := crypto.SplitKeyFile(m.shareKeyPath, m.shareThreshold, m.shareTotal)
+	if err != nil {
+		return newOperationResult(false, fmt.Sprintf("拆分密钥失败: %v", err))
+	}
+
+	paths, err := crypto.WriteKeyShares(shares, m.outputInput.Value())
+	if err != nil {
+		return newOperationResult(false, fmt.Sprintf("写入分片失败: %v", err))
+	}
+
+	return newOperationResult(true, fmt.Sprintf("已将 %s 拆分为 %d 个分片，任意 %d 个可恢复（分片 ID: %s）\n%s\n\n请将分片分别交给不同的保管人，并删除本地的分片副本",
+		m.shareKeyPath, m.shareTotal, m.shareThreshold, shares[0].ID, strings.Join(paths, "\n")))
+}
+
+// combineKeyShares 合并输入的分片，将密钥文件恢复到配置中的对应位置
+func (m Model) combineKeyShares() operationResult {
+	shares, err := shamir.ParseShares(readShareInput(m.textArea.Value()))
+	if err != nil {
+		return newOperationResult(false, fmt.Sprintf("解析分片失败: %v", err))
+	}
+	if len(shares) == 0 {
+		return newOperationResult(false, "未找到分片")
+	}
+
+	path := m.recoveredKeyPath(shares[0])
+	if path == "" {
+		return newOperationResult(false, fmt.Sprintf("无法确定 %s 的恢复位置，请使用 hycrypt keys combine <密钥文件路径>", shares[0].Name))
+	}
+
+	keyData, err := crypto.CombineKeyShares(shares)
+	if err != nil {
+		return newOperationResult(false, fmt.Sprintf("恢复密钥失败: %v", err))
+	}
+	defer clear(keyData)
+
+	if err := crypto.WriteRecoveredKeyFile(path, keyData); err != nil {
+		return newOperationResult(false, fmt.Sprintf("写入密钥文件失败: %v", err))
+	}
+	return newOperationResult(true, fmt.Sprintf("已从 %d 个分片恢复密钥文件:\n%s", len(shares), path))
+}
+
+// recoveredKeyPath 按分片记录的文件名匹配配置中的私钥文件，找不到时按分片记录的密钥类型选择
+func (m Model) recoveredKeyPath(share *shamir.Share) string {
+	for _, algorithm := range getKeyGenAlgorithms(m.config) {
+		for _, keyFile := range algorithm.KeyFiles {
+			path := m.config.GetKeyFilePath(keyFile.Name)
+			if keyFile.Private && path != "" && filepath.Base(path) == share.Name {
+				return path
+			}
+		}
+	}
+
+	for _, algorithm := range crypto.Algorithms() {
+		if keyFile, ok := algorithm.ShareKeyFile(); ok && keyFile.ShareKind == share.Kind {
+			return m.config.GetKeyFilePath(keyFile.Name)
+		}
+	}
+	return ""
+}
+
+// readShareInput 展开输入中的分片文件路径（每行一个），粘贴的分片文本原样保留
+func readShareInput(input string) []byte {
+	text := []byte(input)
+	for _, line := range strings.Split(input, "\n") {
+		path := strings.TrimSpace(line)
+		if path == "" || strings.HasPrefix(path, "-----") {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			if data, err := os.ReadFile(path); err == nil {
+				text = append(append(text, '\n'), data...)
+			}
+		}
+	}
+	return text
+}
@@ -10,9 +10,8 @@ type UIState int
 
 const (
 	StateMainMenu UIState = iota
-	StateKeySource
-	StateKeySelection
 	StateAlgorithm
+	StateKeySelection
 	StateInputType
 	StateFileInput
 	StateTextInput
@@ -20,8 +19,7 @@ const (
 	StateOutputFormat
 	StateOutput
 	StateKeyGeneration
-	StateRSAKeyConfirm
-	StateKMACKeyConfirm
+	StateKeyConfirm
 	StateProcessing
 	StateComplete
 )
@@ -63,21 +61,19 @@ func UIStateManager() *UIStateManagerStruct {
 	return &UIStateManagerStruct{
 		currentState: StateMainMenu,
 		stepNames: map[UIState]string{
-			StateMainMenu:       "选择操作",
-			StateKeySource:      "选择密钥来源",
-			StateKeySelection:   "选择密钥",
-			StateAlgorithm:      "选择算法",
-			StateInputType:      "选择输入类型",
-			StateFileInput:      "选择文件",
-			StateTextInput:      "输入文本",
-			StateHexInput:       "输入十六进制",
-			StateOutputFormat:   "选择输出格式",
-			StateOutput:         "设置输出目录",
-			StateProcessing:     "处理中",
-			StateComplete:       "完成",
-			StateKeyGeneration:  "密钥管理",
-			StateRSAKeyConfirm:  "确认RSA密钥",
-			StateKMACKeyConfirm: "确认KMAC密钥",
+			StateMainMenu:      "选择操作",
+			StateKeySelection:  "选择密钥",
+			StateAlgorithm:     "选择算法",
+			StateInputType:     "选择输入类型",
+			StateFileInput:     "选择文件",
+			StateTextInput:     "输入文本",
+			StateHexInput:      "输入十六进制",
+			StateOutputFormat:  "选择输出格式",
+			StateOutput:        "设置输出目录",
+			StateProcessing:    "处理中",
+			StateComplete:      "完成",
+			StateKeyGeneration: "密钥管理",
+			StateKeyConfirm:    "确认覆盖密钥",
 		},
 	}
 }
@@ -206,10 +202,8 @@ func (sm *UIStateManagerStruct) getStepNumber(state UIState) int {
 	switch state {
 	case StateMainMenu:
 		return 1
-	case StateKeySource, StateAlgorithm:
+	case StateAlgorithm, StateKeySelection:
 		return 2
-	case StateKeySelection:
-		return 3
 	case StateInputType:
 		return 3
 	case StateFileInput, StateTextInput, StateHexInput:
@@ -230,7 +224,7 @@ func (sm *UIStateManagerStruct) getStepNumber(state UIState) int {
 		return sm.totalSteps
 	case StateKeyGeneration:
 		return 1
-	case StateRSAKeyConfirm, StateKMACKeyConfirm:
+	case StateKeyConfirm:
 		return 2
 	default:
 		return 1
@@ -283,7 +277,7 @@ func getKeyGenIcon(state UIState) string {
 	switch state {
 	case StateKeyGeneration:
 		return "🔑"
-	case StateRSAKeyConfirm, StateKMACKeyConfirm:
+	case StateKeyConfirm:
 		return "⚠️"
 	case StateProcessing:
 		return "⏳"
@@ -10,8 +10,9 @@ import (
 	"time"
 
 	"hycrypt/internal/config"
-	"hycrypt/internal/constants"
 	"hycrypt/internal/crypto"
+	"hycrypt/internal/datasink"
+	"hycrypt/internal/datasource"
 	"hycrypt/internal/domain"
 )
 
@@ -53,40 +54,56 @@ type UICryptoService struct {
 
 // CryptoService creates a crypto service using UnifiedProcessor
 func CryptoService(cfg interface{}) (CryptoServiceInterface, error) {
+	return CryptoServiceWithPassword(cfg, "")
+}
+
+// CryptoServiceWithPassword 创建加密服务，口令非空时启用 password 算法
+func CryptoServiceWithPassword(cfg interface{}, password string) (CryptoServiceInterface, error) {
 	config, ok := cfg.(*config.Config)
 	if !ok {
 		return nil, fmt.Errorf("invalid config type")
 	}
 
 	// 构建处理器配置
-	processorConfig := &crypto.ProcessorConfig{}
-
-	// 配置RSA
-	if config.Encryption.Method == constants.AlgorithmRSA || config.Keys.PublicKey != "" {
-		processorConfig.RSAConfig = &crypto.RSAConfig{
-			PublicKeyPath:  config.GetPublicKeyPath(),
-			PrivateKeyPath: config.GetPrivateKeyPath(),
-			KeySize:        config.Encryption.RSAKeySize,
-			AESKeySize:     config.Encryption.AESKeySize,
-		}
+	processorConfig := &crypto.ProcessorConfig{
+		AEAD:             config.Encryption.AEAD,
+		Padding:          config.Encryption.Padding,
+		Compression:      config.Encryption.Compression,
+		CompressionLevel: config.Encryption.CompressionLevel,
+		Workers:          config.Encryption.Workers,
 	}
 
-	// 配置KMAC
-	if config.Encryption.Method == constants.AlgorithmKMAC || config.CheckKMACKeyExists() {
-		if config.CheckKMACKeyExists() {
-			kmacKey, err := config.LoadKMACKey()
-			if err != nil {
-				return nil, fmt.Errorf("加载 KMAC 密钥失败: %w", err)
-			}
+	// 受保护的密钥文件优先使用环境变量或 passphrase_command，其次使用界面中输入的口令
+	var prompt crypto.PassphraseFunc
+	if password != "" {
+		prompt = func(string) ([]byte, error) { return []byte(password), nil }
+	}
+	keyPassphrase := config.KeyPassphraseSource(prompt)
+
+	// 配置签名: 启用签名或存在签名密钥、受信任签名者时验签
+	if config.Encryption.Sign || config.Encryption.RequireSignature ||
+		len(config.Keys.TrustedSigners) > 0 || config.CheckSigningKeyExists() {
+		processorConfig.SigningConfig = &crypto.SigningConfig{
+			PrivateKeyPath:   config.GetSigningKeyPath(),
+			Sign:             config.Encryption.Sign,
+			TrustedKeyPaths:  config.GetTrustedSignerPaths(),
+			RequireSignature: config.Encryption.RequireSignature,
+		}
+	}
 
-			processorConfig.KMACConfig = &crypto.KMACConfig{
-				Key:        kmacKey,
-				KeySize:    config.Encryption.KMACKeySize,
-				AESKeySize: config.Encryption.AESKeySize,
-			}
+	// 配置 age 身份: 解密 age 文件时与 X25519 私钥一起尝试
+	if len(config.Keys.AgeRecipients) > 0 || len(config.Keys.AgeIdentities) > 0 {
+		processorConfig.AgeConfig = &crypto.AgeConfig{
+			Recipients:    config.GetAgeRecipients(),
+			IdentityPaths: config.GetAgeIdentityPaths(),
 		}
 	}
 
+	// 配置当前算法、已有密钥的其他算法及口令加密，以便解密任意算法加密的文件
+	if err := config.ConfigureAlgorithms(processorConfig, keyPassphrase, []byte(password), true); err != nil {
+		return nil, fmt.Errorf("加载密钥失败: %w", err)
+	}
+
 	processor, err := crypto.NewUnifiedProcessor(processorConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create processor: %w", err)
@@ -139,22 +156,6 @@ func (s *UICryptoService) DecryptPath(targetPath, outputDir string) (string, err
 }
 
 // Text encryption/decryption functions
-func encryptTextWithRSA(service interface{}, data []byte) ([]byte, error) {
-	return encryptTextWithMethod(service, data, constants.AlgorithmRSA)
-}
-
-func encryptTextWithKMAC(service interface{}, data []byte) ([]byte, error) {
-	return encryptTextWithMethod(service, data, constants.AlgorithmKMAC)
-}
-
-func decryptDataWithRSA(service interface{}, data []byte) ([]byte, error) {
-	return decryptDataWithMethod(service, data, constants.AlgorithmRSA)
-}
-
-func decryptDataWithKMAC(service interface{}, data []byte) ([]byte, error) {
-	return decryptDataWithMethod(service, data, constants.AlgorithmKMAC)
-}
-
 // encryptTextWithMethod 使用指定方法加密文本
 func encryptTextWithMethod(service interface{}, data []byte, method string) ([]byte, error) {
 	uiService, ok := service.(*UICryptoService)
@@ -269,3 +270,42 @@ func decryptDataWithMethod(service interface{}, data []byte, method string) ([]b
 
 	return decryptedData, nil
 }
+
+// encryptTextJWE 将文本加密为 JWE 紧凑或 JSON 序列化文本
+func encryptTextJWE(service interface{}, data []byte, method string, outputFormat domain.OutputFormat) (string, error) {
+	uiService, ok := service.(*UICryptoService)
+	if !ok {
+		return "", fmt.Errorf("invalid service type")
+	}
+
+	opts := domain.CryptoOptions{
+		Method:       method,
+		OutputFormat: outputFormat,
+		InputFormat:  domain.InputText,
+	}
+
+	sink := datasink.CreateTextSink()
+	if _, err := uiService.processor.Encrypt(context.Background(), datasource.TextSource(data, "text"), sink, opts); err != nil {
+		return "", fmt.Errorf("encryption failed: %w", err)
+	}
+	return sink.GetResult(), nil
+}
+
+// decryptTextJWE 解密 JWE 文本，解密方法由 JWE 的 alg 决定
+func decryptTextJWE(service interface{}, text string) ([]byte, error) {
+	uiService, ok := service.(*UICryptoService)
+	if !ok {
+		return nil, fmt.Errorf("invalid service type")
+	}
+
+	opts := domain.CryptoOptions{
+		OutputFormat: domain.OutputFile,
+		InputFormat:  domain.InputJWE,
+	}
+
+	sink := datasink.CreateTextSink()
+	if _, err := uiService.processor.Decrypt(context.Background(), datasource.TextSource([]byte(text), "text"), sink, opts); err != nil {
+		return nil, fmt.Errorf("decryption failed: %w", err)
+	}
+	return []byte(sink.GetResult()), nil
+}
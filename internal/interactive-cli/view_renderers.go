@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"hycrypt/internal/mnemonic"
 	"hycrypt/internal/utils"
 )
 
@@ -43,7 +44,7 @@ func (r *ViewRendererStruct) RenderFileInput(m Model) string {
 	if m.operation == "decrypt" {
 		s += infoStyle.Render("支持解密单个文件或整个加密文件夹") + "\n\n"
 	} else {
-		s += infoStyle.Render("密钥来源: 本地密钥文件") + "\n"
+		s += infoStyle.Render(keySourceText(m)) + "\n"
 		s += infoStyle.Render(fmt.Sprintf("算法: %s", strings.ToUpper(m.algorithm))) + "\n\n"
 	}
 
@@ -110,7 +111,7 @@ func (r *ViewRendererStruct) RenderTextInput(m Model) string {
 		title = "📝 步骤 3/5: 输入文本"
 	}
 	s := titleStyle.Render(title) + "\n\n"
-	s += infoStyle.Render("密钥来源: 本地密钥文件") + "\n"
+	s += infoStyle.Render(keySourceText(m)) + "\n"
 	s += infoStyle.Render(fmt.Sprintf("算法: %s", strings.ToUpper(m.algorithm))) + "\n\n"
 
 	s += "请输入要加密的文本内容：\n\n"
@@ -133,7 +134,7 @@ func (r *ViewRendererStruct) RenderOutputFormat(m Model) string {
 	stateManager.SetState(StateOutputFormat)
 
 	s := titleStyle.Render("📤 步骤 3/5: 选择输出格式") + "\n\n"
-	s += infoStyle.Render("密钥来源: 本地密钥文件") + "\n"
+	s += infoStyle.Render(keySourceText(m)) + "\n"
 	s += infoStyle.Render(fmt.Sprintf("算法: %s", strings.ToUpper(m.algorithm))) + "\n"
 	s += infoStyle.Render("输入类型: 文本内容") + "\n\n"
 
@@ -179,7 +180,7 @@ func (r *ViewRendererStruct) RenderOutput(m Model) string {
 	}
 	s := titleStyle.Render(title) + "\n\n"
 
-	s += infoStyle.Render("密钥来源: 本地密钥文件") + "\n"
+	s += infoStyle.Render(keySourceText(m)) + "\n"
 	s += infoStyle.Render(fmt.Sprintf("算法: %s", strings.ToUpper(m.algorithm))) + "\n"
 
 	if m.operation == "decrypt" {
@@ -207,6 +208,53 @@ func (r *ViewRendererStruct) RenderOutput(m Model) string {
 	return s
 }
 
+// RenderPasswordInput 渲染口令输入视图
+func (r *ViewRendererStruct) RenderPasswordInput(m Model) string {
+	if !usesPasswordAlgorithm(m) {
+		return r.renderKeyPassphraseInput(m)
+	}
+
+	s := titleStyle.Render("🔏 输入口令") + "\n\n"
+
+	s += infoStyle.Render("密钥来源: 口令 (Argon2id 派生)") + "\n"
+	s += infoStyle.Render(fmt.Sprintf("算法: %s", strings.ToUpper(m.algorithm))) + "\n\n"
+
+	if m.operation == "encrypt" && m.pendingPassword != "" {
+		s += "请再次输入口令以确认：\n\n"
+	} else {
+		s += "请输入口令：\n\n"
+	}
+	s += m.passwordInput.View() + "\n\n"
+
+	if m.passwordError != "" {
+		s += errorStyle.Render("✗ "+m.passwordError) + "\n"
+	}
+
+	if m.operation == "encrypt" {
+		s += infoStyle.Render("口令不会被保存，遗失后将无法解密") + "\n"
+	}
+	s += "\n" + infoStyle.Render("ESC: 返回上级  回车: 确认")
+	return s
+}
+
+// renderKeyPassphraseInput 渲染受保护密钥文件的口令输入视图
+func (r *ViewRendererStruct) renderKeyPassphraseInput(m Model) string {
+	s := titleStyle.Render("🔑 输入私钥口令") + "\n\n"
+
+	s += infoStyle.Render(fmt.Sprintf("密钥文件: %s", protectedKeyPath(m))) + "\n"
+	s += infoStyle.Render(fmt.Sprintf("算法: %s", strings.ToUpper(m.algorithm))) + "\n\n"
+
+	s += "请输入私钥口令：\n\n"
+	s += m.passwordInput.View() + "\n\n"
+
+	if m.passwordError != "" {
+		s += errorStyle.Render("✗ "+m.passwordError) + "\n"
+	}
+
+	s += "\n" + infoStyle.Render("ESC: 返回上级  回车: 确认")
+	return s
+}
+
 // RenderProcessing 渲染处理中视图
 func (r *ViewRendererStruct) RenderProcessing(m Model) string {
 	// 使用状态管理器获取正确的处理标题
@@ -233,7 +281,7 @@ func (r *ViewRendererStruct) RenderProcessing(m Model) string {
 	s += stateManager.GetProcessingDescription() + "\n\n"
 
 	// 添加操作详情
-	s += "密钥来源: 本地密钥文件\n"
+	s += keySourceText(m) + "\n"
 	if m.inputType == "file" {
 		s += fmt.Sprintf("输入文件: %s\n", filepath.Base(m.pathInput.Value()))
 	} else {
@@ -313,15 +361,23 @@ func (r *ViewRendererStruct) RenderComplete(m Model) string {
 
 // RenderHexOutputComplete 渲染十六进制输出完成视图
 func (r *ViewRendererStruct) RenderHexOutputComplete(m Model) string {
-	// 解析存储在OutputPath中的数据（格式：hexData|originalText|algorithm）
-	parts := strings.Split(m.resultInfo.OutputPath, "|")
-	if len(parts) == 3 {
-		hexData := parts[0]
-		originalText := parts[1]
-		algorithm := parts[2]
-
-		// 根据算法类型判断是加密还是解密
+	// 解析存储在OutputPath中的数据（格式：hexData|originalText|algorithm，JWE 输出时 hexData 为 JWE 文本）
+	// 十六进制和 JWE 文本不含 "|"，明文可能包含，因此从编码数据一侧切分
+	rest, algorithm, ok := cutLast(m.resultInfo.OutputPath, "|")
+	if ok {
+		// 根据算法类型判断是加密还是解密，FileName 区分十六进制与 JWE
 		isEncryption := !strings.Contains(algorithm, "解密")
+		isJWE := m.resultInfo.FileName == "JWE_OUTPUT"
+
+		var hexData, originalText string
+		if isEncryption {
+			hexData, originalText, ok = strings.Cut(rest, "|")
+		} else {
+			hexData, originalText, ok = cutLast(rest, "|")
+		}
+		if !ok {
+			return "输出错误：无法解析结果数据"
+		}
 
 		// 构建完整的输出字符串
 		var result strings.Builder
@@ -336,25 +392,36 @@ func (r *ViewRendererStruct) RenderHexOutputComplete(m Model) string {
 			result.WriteString(strings.Repeat("=", 70) + "\n\n")
 			result.WriteString("原始文本:\n")
 			result.WriteString(originalText + "\n\n")
-			result.WriteString("加密结果 (十六进制):\n")
-			result.WriteString(strings.Repeat("-", 70) + "\n")
-
-			// 格式化十六进制输出，每行显示64个字符
-			for i := 0; i < len(hexData); i += 64 {
-				end := i + 64
-				if end > len(hexData) {
-					end = len(hexData)
+			if isJWE {
+				// JWE 不折行，便于整段复制（JSON 序列化中插入换行会破坏格式）
+				result.WriteString("加密结果 (JWE):\n")
+				result.WriteString(strings.Repeat("-", 70) + "\n")
+				result.WriteString(hexData + "\n")
+			} else {
+				result.WriteString("加密结果 (十六进制):\n")
+				result.WriteString(strings.Repeat("-", 70) + "\n")
+
+				// 格式化十六进制输出，每行显示64个字符
+				for i := 0; i < len(hexData); i += 64 {
+					end := i + 64
+					if end > len(hexData) {
+						end = len(hexData)
+					}
+					result.WriteString(hexData[i:end] + "\n")
 				}
-				result.WriteString(hexData[i:end] + "\n")
 			}
 
 			result.WriteString(strings.Repeat("-", 70) + "\n")
 		} else {
 			// 解密结果输出
 			result.WriteString(strings.Repeat("=", 70) + "\n")
-			result.WriteString("🔓 十六进制解密完成 (" + algorithm + ")\n")
+			title, inputLabel := "🔓 十六进制解密完成", "输入的十六进制数据:"
+			if isJWE {
+				title, inputLabel = "🔓 JWE 解密完成", "输入的 JWE 数据:"
+			}
+			result.WriteString(title + " (" + algorithm + ")\n")
 			result.WriteString(strings.Repeat("=", 70) + "\n\n")
-			result.WriteString("输入的十六进制数据:\n")
+			result.WriteString(inputLabel + "\n")
 			result.WriteString(originalText + "\n\n")
 			result.WriteString("解密结果:\n")
 			result.WriteString(strings.Repeat("-", 70) + "\n")
@@ -372,6 +439,14 @@ func (r *ViewRendererStruct) RenderHexOutputComplete(m Model) string {
 	return "输出错误：无法解析结果数据"
 }
 
+// cutLast 在最后一个分隔符处切分字符串
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
 // RenderKeyGeneration 渲染密钥生成视图
 func (r *ViewRendererStruct) RenderKeyGeneration(m Model) string {
 	s := titleStyle.Render("🔑 密钥管理") + "\n\n"
@@ -392,6 +467,122 @@ func (r *ViewRendererStruct) RenderKeyGeneration(m Model) string {
 	return s
 }
 
+// RenderShareTarget 渲染要拆分的密钥文件选择视图
+func (r *ViewRendererStruct) RenderShareTarget(m Model) string {
+	s := titleStyle.Render("🧩 步骤 1/3: 选择要拆分的密钥") + "\n\n"
+
+	for i, choice := range m.choices {
+		cursor := " "
+		if m.cursor == i {
+			cursor = ">"
+			choice = selectedStyle.Render(choice)
+		} else {
+			choice = choiceStyle.Render(choice)
+		}
+		s += fmt.Sprintf("%s %s\n", cursor, choice)
+	}
+
+	s += "\n" + infoStyle.Render("受口令保护的密钥拆分后仍受保护，恢复后需使用原口令") + "\n"
+	s += "\n" + infoStyle.Render("ESC: 返回上级  ↑/↓: 选择  回车: 确认")
+	return s
+}
+
+// RenderShareParams 渲染分片门限与份数输入视图
+func (r *ViewRendererStruct) RenderShareParams(m Model) string {
+	s := titleStyle.Render("🧩 步骤 2/3: 设置门限与份数") + "\n\n"
+	s += infoStyle.Render(fmt.Sprintf("密钥文件: %s", m.shareKeyPath)) + "\n\n"
+
+	s += "请输入 门限/份数（任意“门限”个分片即可恢复密钥）：\n\n"
+	s += m.pathInput.View() + "\n\n"
+
+	if m.inputError != "" {
+		s += errorStyle.Render("✗ "+m.inputError) + "\n"
+	}
+
+	s += "\n" + infoStyle.Render("ESC: 返回上级  回车: 确认")
+	return s
+}
+
+// RenderShareOutput 渲染分片输出目录视图
+func (r *ViewRendererStruct) RenderShareOutput(m Model) string {
+	s := titleStyle.Render("🧩 步骤 3/3: 设置输出目录") + "\n\n"
+	s += infoStyle.Render(fmt.Sprintf("密钥文件: %s", m.shareKeyPath)) + "\n"
+	s += infoStyle.Render(fmt.Sprintf("分片: %d 份，任意 %d 份可恢复", m.shareTotal, m.shareThreshold)) + "\n"
+	s += infoStyle.Render("分片文件将保存到：") + "\n\n"
+
+	s += m.outputInput.View() + "\n\n"
+
+	if m.outputInput.Value() == "" {
+		if dir, err := os.Getwd(); err == nil {
+			s += infoStyle.Render(fmt.Sprintf("默认输出目录: %s", dir)) + "\n"
+		}
+	}
+
+	s += infoStyle.Render("留空使用当前目录") + "\n"
+	s += "\n" + infoStyle.Render("ESC: 返回上级  回车: 开始拆分")
+	return s
+}
+
+// RenderShareCombine 渲染分片输入视图
+func (r *ViewRendererStruct) RenderShareCombine(m Model) string {
+	s := titleStyle.Render("🧩 从分片恢复密钥") + "\n\n"
+
+	s += "请粘贴分片内容，或每行输入一个分片文件路径：\n\n"
+	s += m.textArea.View() + "\n\n"
+
+	s += infoStyle.Render("密钥文件将恢复到配置中的对应位置，已存在的文件不会被覆盖") + "\n"
+	s += "\n" + infoStyle.Render("ESC: 返回上级  Ctrl+D: 开始恢复  回车: 换行")
+	return s
+}
+
+// RenderMnemonicPassphrase 渲染导出助记词前的 KMAC 密钥口令输入视图
+func (r *ViewRendererStruct) RenderMnemonicPassphrase(m Model) string {
+	s := titleStyle.Render("🔑 输入 KMAC 密钥口令") + "\n\n"
+	s += infoStyle.Render(fmt.Sprintf("密钥文件: %s", m.config.GetKMACKeyPath())) + "\n\n"
+
+	s += "请输入密钥口令：\n\n"
+	s += m.passwordInput.View() + "\n\n"
+
+	if m.passwordError != "" {
+		s += errorStyle.Render("✗ "+m.passwordError) + "\n"
+	}
+
+	s += "\n" + infoStyle.Render("ESC: 返回上级  回车: 确认")
+	return s
+}
+
+// RenderMnemonicShow 渲染助记词视图
+func (r *ViewRendererStruct) RenderMnemonicShow(m Model) string {
+	s := titleStyle.Render("📝 KMAC 密钥助记词") + "\n\n"
+	s += infoStyle.Render(fmt.Sprintf("密钥文件: %s", m.config.GetKMACKeyPath())) + "\n\n"
+
+	s += selectedStyle.Render(mnemonic.Format(m.mnemonic, 4)) + "\n"
+
+	s += errorStyle.Render("⚠️  助记词即密钥本身，请抄写在纸上离线保存，不要拍照或存入联网设备") + "\n"
+	s += infoStyle.Render("恢复时可只写每个词的前 4 个字母") + "\n"
+	s += "\n" + infoStyle.Render("按任意键清除并返回")
+	return s
+}
+
+// RenderMnemonicInput 渲染助记词输入视图
+func (r *ViewRendererStruct) RenderMnemonicInput(m Model) string {
+	s := titleStyle.Render("📝 从助记词恢复 KMAC 密钥") + "\n\n"
+	s += infoStyle.Render(fmt.Sprintf("密钥文件: %s", m.config.GetKMACKeyPath())) + "\n\n"
+
+	s += "请输入助记词（以空格或换行分隔，可只写每个词的前 4 个字母）：\n\n"
+	s += m.textArea.View() + "\n\n"
+
+	if words := len(strings.Fields(m.textArea.Value())); words > 0 {
+		s += infoStyle.Render(fmt.Sprintf("已输入 %d 个词", words)) + "\n"
+	}
+	if m.inputError != "" {
+		s += errorStyle.Render("✗ "+m.inputError) + "\n"
+	}
+
+	s += "\n" + infoStyle.Render("ESC: 返回上级  Ctrl+D: 校验并恢复  回车: 换行")
+	return s
+}
+
 // 辅助方法
 
 func (r *ViewRendererStruct) renderProgressBar(m Model) string {
@@ -462,6 +653,29 @@ func (r *ViewRendererStruct) RenderAlgorithm(m Model) string {
 
 	s += "\n" + infoStyle.Render("RSA: 公钥加密，适合小文件和混合加密") + "\n"
 	s += infoStyle.Render("KMAC: 对称加密，高性能，适合大文件") + "\n"
+	s += infoStyle.Render("PASSWORD: 口令加密，无需密钥文件，适合助记词等") + "\n"
+	s += "\n" + infoStyle.Render("ESC: 返回上级  ↑/↓: 选择  回车: 确认")
+	return s
+}
+
+// RenderKeySelection 渲染密钥选择视图
+func (r *ViewRendererStruct) RenderKeySelection(m Model) string {
+	s := titleStyle.Render("🔑 步骤 1/5: 选择密钥") + "\n\n"
+	s += infoStyle.Render(fmt.Sprintf("算法: %s", strings.ToUpper(m.algorithm))) + "\n"
+	s += infoStyle.Render("密钥环中有多个密钥，请选择用于加密的密钥：") + "\n\n"
+
+	for i, choice := range m.choices {
+		cursor := " "
+		if m.cursor == i {
+			cursor = ">"
+			choice = selectedStyle.Render(choice)
+		} else {
+			choice = choiceStyle.Render(choice)
+		}
+		s += fmt.Sprintf("%s %s\n", cursor, choice)
+	}
+
+	s += "\n" + infoStyle.Render("解密时按文件头部记录的指纹自动选择密钥") + "\n"
 	s += "\n" + infoStyle.Render("ESC: 返回上级  ↑/↓: 选择  回车: 确认")
 	return s
 }
@@ -483,7 +697,7 @@ func (r *ViewRendererStruct) RenderInputType(m Model) string {
 	}
 
 	s := titleStyle.Render(title) + "\n\n"
-	s += infoStyle.Render("密钥来源: 本地密钥文件") + "\n"
+	s += infoStyle.Render(keySourceText(m)) + "\n"
 	s += infoStyle.Render(fmt.Sprintf("算法: %s", strings.ToUpper(m.algorithm))) + "\n\n"
 
 	for i, choice := range m.choices {
@@ -0,0 +1,408 @@
+// Package jwe 实现 JSON Web Encryption（RFC 7516）的紧凑与 JSON 序列化，
+// 支持 RSA-OAEP-256 与 dir 密钥管理、A256GCM 内容加密，用于与 JOSE 服务互通
+package jwe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// 支持的算法
+const (
+	AlgRSAOAEP256 = "RSA-OAEP-256"
+	AlgDirect     = "dir"
+	EncA256GCM    = "A256GCM"
+)
+
+const (
+	cekSize = 32
+	ivSize  = 12
+	tagSize = 16
+)
+
+var b64 = base64.RawURLEncoding.Strict()
+
+// ErrNoMatchingRecipient 没有接收方能用给定密钥解开
+var ErrNoMatchingRecipient = errors.New("no recipient matches the given key")
+
+// Header JOSE 头部中本实现关心的字段
+type Header struct {
+	Alg  string   `json:"alg,omitempty"`
+	Enc  string   `json:"enc,omitempty"`
+	Kid  string   `json:"kid,omitempty"`
+	Zip  string   `json:"zip,omitempty"`
+	Crit []string `json:"crit,omitempty"`
+}
+
+func (h Header) isZero() bool {
+	return h.Alg == "" && h.Enc == "" && h.Kid == "" && h.Zip == "" && len(h.Crit) == 0
+}
+
+// merge 按 RFC 7516 合并受保护头部、共享头部与接收方头部
+// crit 必须受完整性保护（RFC 7516 §4.1.13），只取自受保护头部，其他位置的 crit 在解析时拒绝
+func merge(protected Header, unprotected ...Header) Header {
+	merged := Header{Crit: protected.Crit}
+	for _, h := range append([]Header{protected}, unprotected...) {
+		if h.Alg != "" {
+			merged.Alg = h.Alg
+		}
+		if h.Enc != "" {
+			merged.Enc = h.Enc
+		}
+		if h.Kid != "" {
+			merged.Kid = h.Kid
+		}
+		if h.Zip != "" {
+			merged.Zip = h.Zip
+		}
+	}
+	return merged
+}
+
+// Recipient 接收方：接收方头部与封装后的内容加密密钥
+type Recipient struct {
+	Header       Header
+	EncryptedKey []byte
+}
+
+// Object 一个 JWE 对象
+type Object struct {
+	Protected   Header
+	Unprotected Header
+	Recipients  []Recipient
+	IV          []byte
+	Ciphertext  []byte
+	Tag         []byte
+
+	// protectedRaw 受保护头部的 base64url 编码，作为 AAD 必须按原文使用
+	protectedRaw string
+}
+
+// EncryptRSA 使用 RSA-OAEP-256 为每个公钥封装内容加密密钥
+// 单个接收方时 alg 与 kid 放在受保护头部，可紧凑序列化
+func EncryptRSA(plaintext []byte, keys []*rsa.PublicKey) (*Object, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no recipients specified")
+	}
+
+	cek := make([]byte, cekSize)
+	if _, err := rand.Read(cek); err != nil {
+		return nil, err
+	}
+	defer clear(cek)
+
+	object := &Object{Protected: Header{Enc: EncA256GCM}}
+	for _, key := range keys {
+		encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, cek, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap key: %w", err)
+		}
+		object.Recipients = append(object.Recipients, Recipient{
+			Header:       Header{Alg: AlgRSAOAEP256, Kid: Thumbprint(key)},
+			EncryptedKey: encryptedKey,
+		})
+	}
+	if len(keys) == 1 {
+		object.Protected.Alg = AlgRSAOAEP256
+		object.Protected.Kid = object.Recipients[0].Header.Kid
+		object.Recipients[0].Header = Header{}
+	}
+
+	return object, object.seal(cek, plaintext)
+}
+
+// EncryptDirect 使用共享对称密钥直接作为内容加密密钥（alg=dir）
+func EncryptDirect(plaintext, key []byte, kid string) (*Object, error) {
+	if len(key) != cekSize {
+		return nil, fmt.Errorf("dir key must be %d bytes for %s", cekSize, EncA256GCM)
+	}
+
+	object := &Object{
+		Protected:  Header{Alg: AlgDirect, Enc: EncA256GCM, Kid: kid},
+		Recipients: []Recipient{{}},
+	}
+	return object, object.seal(key, plaintext)
+}
+
+// DecryptRSA 用 RSA 私钥解开匹配的接收方并解密
+func (o *Object) DecryptRSA(key *rsa.PrivateKey) ([]byte, error) {
+	kid := Thumbprint(&key.PublicKey)
+	for _, recipient := range o.Recipients {
+		header := o.recipientHeader(recipient)
+		if header.Alg != AlgRSAOAEP256 || (header.Kid != "" && header.Kid != kid) {
+			continue
+		}
+		cek, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, recipient.EncryptedKey, nil)
+		if err != nil {
+			continue
+		}
+		defer clear(cek)
+		return o.open(cek)
+	}
+	return nil, ErrNoMatchingRecipient
+}
+
+// DecryptDirect 用共享对称密钥解密 alg=dir 的对象
+func (o *Object) DecryptDirect(key []byte) ([]byte, error) {
+	for _, recipient := range o.Recipients {
+		if o.recipientHeader(recipient).Alg == AlgDirect {
+			return o.open(key)
+		}
+	}
+	return nil, ErrNoMatchingRecipient
+}
+
+// Algorithm 返回第一个接收方的密钥管理算法
+func (o *Object) Algorithm() string {
+	if len(o.Recipients) == 0 {
+		return o.Protected.Alg
+	}
+	return o.recipientHeader(o.Recipients[0]).Alg
+}
+
+func (o *Object) recipientHeader(recipient Recipient) Header {
+	return merge(o.Protected, o.Unprotected, recipient.Header)
+}
+
+// seal 用 A256GCM 加密，AAD 为受保护头部的 base64url 编码
+func (o *Object) seal(cek, plaintext []byte) error {
+	encoded, err := json.Marshal(o.Protected)
+	if err != nil {
+		return err
+	}
+	o.protectedRaw = b64.EncodeToString(encoded)
+
+	aead, err := newGCM(cek)
+	if err != nil {
+		return err
+	}
+	o.IV = make([]byte, ivSize)
+	if _, err := rand.Read(o.IV); err != nil {
+		return err
+	}
+
+	sealed := aead.Seal(nil, o.IV, plaintext, []byte(o.protectedRaw))
+	o.Ciphertext = sealed[:len(sealed)-tagSize]
+	o.Tag = sealed[len(sealed)-tagSize:]
+	return nil
+}
+
+func (o *Object) open(cek []byte) ([]byte, error) {
+	header := o.recipientHeader(Recipient{})
+	if header.Enc != EncA256GCM {
+		return nil, fmt.Errorf("unsupported enc: %q", header.Enc)
+	}
+	if header.Zip != "" {
+		return nil, fmt.Errorf("unsupported zip: %q", header.Zip)
+	}
+	if len(header.Crit) > 0 {
+		return nil, fmt.Errorf("unsupported critical headers: %v", header.Crit)
+	}
+	if len(o.IV) != ivSize || len(o.Tag) != tagSize {
+		return nil, errors.New("invalid iv or tag length")
+	}
+
+	aead, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	sealed := append(append([]byte{}, o.Ciphertext...), o.Tag...)
+	plaintext, err := aead.Open(nil, o.IV, sealed, []byte(o.protectedRaw))
+	if err != nil {
+		return nil, errors.New("failed to decrypt and authenticate content")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != cekSize {
+		return nil, fmt.Errorf("invalid content encryption key length: %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Compact 紧凑序列化: header.encrypted_key.iv.ciphertext.tag，只支持单个接收方
+func (o *Object) Compact() (string, error) {
+	if len(o.Recipients) != 1 || !o.Recipients[0].Header.isZero() || o.Unprotected.Alg != "" {
+		return "", errors.New("compact serialization requires a single recipient with a protected header")
+	}
+	return strings.Join([]string{
+		o.protectedRaw,
+		b64.EncodeToString(o.Recipients[0].EncryptedKey),
+		b64.EncodeToString(o.IV),
+		b64.EncodeToString(o.Ciphertext),
+		b64.EncodeToString(o.Tag),
+	}, "."), nil
+}
+
+type jsonRecipient struct {
+	Header       *Header `json:"header,omitempty"`
+	EncryptedKey string  `json:"encrypted_key,omitempty"`
+}
+
+type jsonObject struct {
+	Protected    string          `json:"protected,omitempty"`
+	Unprotected  *Header         `json:"unprotected,omitempty"`
+	Header       *Header         `json:"header,omitempty"`
+	EncryptedKey string          `json:"encrypted_key,omitempty"`
+	Recipients   []jsonRecipient `json:"recipients,omitempty"`
+	IV           string          `json:"iv"`
+	Ciphertext   string          `json:"ciphertext"`
+	Tag          string          `json:"tag"`
+}
+
+// JSON JSON 序列化：单个接收方使用扁平格式，多个接收方使用通用格式
+func (o *Object) JSON() ([]byte, error) {
+	out := jsonObject{
+		Protected:  o.protectedRaw,
+		IV:         b64.EncodeToString(o.IV),
+		Ciphertext: b64.EncodeToString(o.Ciphertext),
+		Tag:        b64.EncodeToString(o.Tag),
+	}
+	if !o.Unprotected.isZero() {
+		unprotected := o.Unprotected
+		out.Unprotected = &unprotected
+	}
+
+	if len(o.Recipients) == 1 {
+		recipient := o.Recipients[0]
+		if !recipient.Header.isZero() {
+			out.Header = &recipient.Header
+		}
+		out.EncryptedKey = b64.EncodeToString(recipient.EncryptedKey)
+	} else {
+		for _, recipient := range o.Recipients {
+			entry := jsonRecipient{EncryptedKey: b64.EncodeToString(recipient.EncryptedKey)}
+			if !recipient.Header.isZero() {
+				header := recipient.Header
+				entry.Header = &header
+			}
+			out.Recipients = append(out.Recipients, entry)
+		}
+	}
+	return json.Marshal(out)
+}
+
+// IsJWE 粗略判断文本是否为 JWE（紧凑格式的五段或 JSON 格式）
+func IsJWE(s string) bool {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{") {
+		return strings.Contains(s, `"ciphertext"`)
+	}
+	return strings.Count(s, ".") == 4 && strings.HasPrefix(s, "eyJ")
+}
+
+// Parse 解析紧凑或 JSON 序列化的 JWE
+func Parse(s string) (*Object, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{") {
+		return parseJSON(s)
+	}
+	return parseCompact(s)
+}
+
+func parseCompact(s string) (*Object, error) {
+	// 紧凑格式不含空白，复制粘贴时的换行一并去除
+	s = strings.Join(strings.Fields(s), "")
+	parts := strings.Split(s, ".")
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("compact JWE must have 5 parts, got %d", len(parts))
+	}
+
+	decoded := make([][]byte, 5)
+	for i, part := range parts {
+		value, err := b64.DecodeString(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64url in part %d: %w", i+1, err)
+		}
+		decoded[i] = value
+	}
+
+	object := &Object{
+		Recipients:   []Recipient{{EncryptedKey: decoded[1]}},
+		IV:           decoded[2],
+		Ciphertext:   decoded[3],
+		Tag:          decoded[4],
+		protectedRaw: parts[0],
+	}
+	if err := json.Unmarshal(decoded[0], &object.Protected); err != nil {
+		return nil, fmt.Errorf("invalid protected header: %w", err)
+	}
+	return object, nil
+}
+
+func parseJSON(s string) (*Object, error) {
+	var in jsonObject
+	if err := json.Unmarshal([]byte(s), &in); err != nil {
+		return nil, fmt.Errorf("invalid JWE JSON: %w", err)
+	}
+
+	object := &Object{protectedRaw: in.Protected}
+	if in.Protected != "" {
+		raw, err := b64.DecodeString(in.Protected)
+		if err != nil {
+			return nil, fmt.Errorf("invalid protected header: %w", err)
+		}
+		if err := json.Unmarshal(raw, &object.Protected); err != nil {
+			return nil, fmt.Errorf("invalid protected header: %w", err)
+		}
+	}
+	if in.Unprotected != nil {
+		object.Unprotected = *in.Unprotected
+	}
+	if len(object.Unprotected.Crit) > 0 {
+		return nil, errors.New("crit must be in the protected header")
+	}
+
+	recipients := in.Recipients
+	if len(recipients) == 0 {
+		recipients = []jsonRecipient{{Header: in.Header, EncryptedKey: in.EncryptedKey}}
+	}
+	for _, entry := range recipients {
+		encryptedKey, err := b64.DecodeString(entry.EncryptedKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encrypted_key: %w", err)
+		}
+		recipient := Recipient{EncryptedKey: encryptedKey}
+		if entry.Header != nil {
+			recipient.Header = *entry.Header
+		}
+		if len(recipient.Header.Crit) > 0 {
+			return nil, errors.New("crit must be in the protected header")
+		}
+		object.Recipients = append(object.Recipients, recipient)
+	}
+
+	var err error
+	if object.IV, err = b64.DecodeString(in.IV); err != nil {
+		return nil, fmt.Errorf("invalid iv: %w", err)
+	}
+	if object.Ciphertext, err = b64.DecodeString(in.Ciphertext); err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	if object.Tag, err = b64.DecodeString(in.Tag); err != nil {
+		return nil, fmt.Errorf("invalid tag: %w", err)
+	}
+	return object, nil
+}
+
+// Thumbprint RSA 公钥的 JWK 指纹（RFC 7638），用作 kid
+func Thumbprint(key *rsa.PublicKey) string {
+	e := big.NewInt(int64(key.E)).Bytes()
+	canonical := fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, b64.EncodeToString(e), b64.EncodeToString(key.N.Bytes()))
+	sum := sha256.Sum256([]byte(canonical))
+	return b64.EncodeToString(sum[:])
+}
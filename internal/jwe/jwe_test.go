@@ -0,0 +1,151 @@
+package jwe
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	dirKey := bytes.Repeat([]byte{0x42}, cekSize)
+	plaintext := []byte("hello jose")
+
+	testCases := []struct {
+		name    string
+		encrypt func() (*Object, error)
+		decrypt func(*Object) ([]byte, error)
+		compact bool
+	}{
+		{"rsa", func() (*Object, error) { return EncryptRSA(plaintext, []*rsa.PublicKey{&key.PublicKey}) },
+			func(o *Object) ([]byte, error) { return o.DecryptRSA(key) }, true},
+		{"rsa multi", func() (*Object, error) {
+			return EncryptRSA(plaintext, []*rsa.PublicKey{&other.PublicKey, &key.PublicKey})
+		},
+			func(o *Object) ([]byte, error) { return o.DecryptRSA(key) }, false},
+		{"dir", func() (*Object, error) { return EncryptDirect(plaintext, dirKey, "k1") },
+			func(o *Object) ([]byte, error) { return o.DecryptDirect(dirKey) }, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			object, err := tc.encrypt()
+			if err != nil {
+				t.Fatalf("encrypt failed: %v", err)
+			}
+
+			var serialized []string
+			compact, err := object.Compact()
+			if tc.compact {
+				if err != nil {
+					t.Fatalf("Compact failed: %v", err)
+				}
+				serialized = append(serialized, compact)
+			} else if err == nil {
+				t.Fatal("multi-recipient object serialized as compact")
+			}
+			encoded, err := object.JSON()
+			if err != nil {
+				t.Fatalf("JSON failed: %v", err)
+			}
+			serialized = append(serialized, string(encoded))
+
+			for _, s := range serialized {
+				if !IsJWE(s) {
+					t.Errorf("IsJWE(%.20q) = false", s)
+				}
+				parsed, err := Parse(s)
+				if err != nil {
+					t.Fatalf("Parse failed: %v", err)
+				}
+				decrypted, err := tc.decrypt(parsed)
+				if err != nil {
+					t.Fatalf("decrypt failed: %v", err)
+				}
+				if !bytes.Equal(decrypted, plaintext) {
+					t.Errorf("round trip mismatch: %q", decrypted)
+				}
+			}
+		})
+	}
+}
+
+func TestDecryptRejects(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	stranger, _ := rsa.GenerateKey(rand.Reader, 2048)
+	object, _ := EncryptRSA([]byte("secret"), []*rsa.PublicKey{&key.PublicKey})
+	compact, _ := object.Compact()
+	parts := strings.Split(compact, ".")
+
+	// 修改受保护头部会改变 AAD，认证必须失败
+	tamperedHeader := strings.Join(append([]string{b64.EncodeToString([]byte(`{"alg":"RSA-OAEP-256","enc":"A256GCM"}`))}, parts[1:]...), ".")
+	tamperedTag := strings.Join(append(parts[:4:4], b64.EncodeToString(make([]byte, tagSize))), ".")
+
+	// crit 只能出现在受保护头部
+	withCrit := func(change func(o *Object)) string {
+		parsed, _ := Parse(compact)
+		change(parsed)
+		encoded, _ := parsed.JSON()
+		return string(encoded)
+	}
+	unprotectedCrit := withCrit(func(o *Object) { o.Unprotected.Crit = []string{"exp"} })
+	recipientCrit := withCrit(func(o *Object) { o.Recipients[0].Header.Crit = []string{"exp"} })
+
+	testCases := []struct {
+		name    string
+		input   string
+		key     *rsa.PrivateKey
+		wantErr error
+	}{
+		{"wrong key", compact, stranger, ErrNoMatchingRecipient},
+		{"tampered header", tamperedHeader, key, nil},
+		{"tampered tag", tamperedTag, key, nil},
+		{"four parts", strings.Join(parts[:4], "."), key, nil},
+		{"unprotected crit", unprotectedCrit, key, nil},
+		{"recipient crit", recipientCrit, key, nil},
+	}
+	for _, tc := range testCases {
+		parsed, err := Parse(tc.input)
+		if err == nil {
+			_, err = parsed.DecryptRSA(tc.key)
+		}
+		if err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		} else if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: got %v, expected %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestParseToleratesLineBreaks(t *testing.T) {
+	dirKey := bytes.Repeat([]byte{0x07}, cekSize)
+	object, _ := EncryptDirect([]byte("wrapped"), dirKey, "")
+	compact, _ := object.Compact()
+
+	wrapped := compact[:20] + "\n" + compact[20:40] + "\r\n " + compact[40:] + "\n"
+	parsed, err := Parse(wrapped)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if decrypted, err := parsed.DecryptDirect(dirKey); err != nil || string(decrypted) != "wrapped" {
+		t.Fatalf("decrypt failed: %v", err)
+	}
+}
+
+func TestThumbprint(t *testing.T) {
+	// RFC 7638 第 3.1 节示例
+	n, _ := b64.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}
+
+	if got := Thumbprint(key); got != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Errorf("Thumbprint = %s", got)
+	}
+}
@@ -0,0 +1,151 @@
+// Package mnemonic 按 BIP39 方案把对称密钥编码为带校验和的英文助记词，便于抄写到纸上备份
+package mnemonic
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 支持的密钥长度（字节），须为 4 的倍数：16 字节 12 个词，32 字节 24 个词，64 字节 48 个词
+const (
+	MinKeySize = 16
+	MaxKeySize = 64
+)
+
+var (
+	// ErrInvalidWordCount 助记词数量不对应任何支持的密钥长度
+	ErrInvalidWordCount = errors.New("mnemonic: invalid number of words")
+	// ErrUnknownWord 助记词不在词表中
+	ErrUnknownWord = errors.New("mnemonic: unknown word")
+	// ErrChecksumMismatch 助记词校验和不符（抄写错误或顺序错误）
+	ErrChecksumMismatch = errors.New("mnemonic: checksum mismatch")
+)
+
+// english BIP39 英文词表，2048 个词，前 4 个字母互不相同
+//
+//go:embed english.txt
+var english string
+
+var (
+	wordList   = strings.Fields(english)
+	wordIndex  = make(map[string]int, len(wordList))
+	prefixWord = make(map[string]string, len(wordList))
+)
+
+func init() {
+	for i, word := range wordList {
+		wordIndex[word] = i
+		if len(word) >= 4 {
+			prefixWord[word[:4]] = word
+		}
+	}
+}
+
+// Encode 将密钥编码为助记词：密钥后附加 SHA-256 前 len(key)/4 位作为校验和，每 11 位对应一个词
+func Encode(key []byte) (string, error) {
+	if len(key) < MinKeySize || len(key) > MaxKeySize || len(key)%4 != 0 {
+		return "", fmt.Errorf("mnemonic: unsupported key size %d bytes (need a multiple of 4 between %d and %d)", len(key), MinKeySize, MaxKeySize)
+	}
+
+	digest := sha256.Sum256(key)
+	data := append(append([]byte(nil), key...), digest[:2]...)
+	defer clear(data)
+
+	words := make([]string, len(key)*3/4)
+	for i := range words {
+		index := 0
+		for bit := i * 11; bit < (i+1)*11; bit++ {
+			index = index<<1 | int(data[bit/8]>>(7-bit%8)&1)
+		}
+		words[i] = wordList[index]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// Decode 解析助记词并校验校验和，返回密钥
+// 词之间以任意空白分隔，大小写不敏感；打印时附带的序号（如 "1."）被忽略；
+// 可只写每个词的前 4 个字母
+func Decode(mnemonic string) ([]byte, error) {
+	var indices []int
+	for _, field := range strings.Fields(strings.ToLower(mnemonic)) {
+		if isPosition(field) {
+			continue
+		}
+		index, ok := lookup(field)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q (word %d)", ErrUnknownWord, field, len(indices)+1)
+		}
+		indices = append(indices, index)
+	}
+
+	keySize := len(indices) * 4 / 3
+	if len(indices)%3 != 0 || keySize < MinKeySize || keySize > MaxKeySize {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidWordCount, len(indices))
+	}
+
+	data := make([]byte, (len(indices)*11+7)/8)
+	defer clear(data)
+	for i, index := range indices {
+		for j := 0; j < 11; j++ {
+			bit := i*11 + j
+			data[bit/8] |= byte(index>>(10-j)&1) << (7 - bit%8)
+		}
+	}
+
+	key := append([]byte(nil), data[:keySize]...)
+	digest := sha256.Sum256(key)
+	for bit := 0; bit < keySize/4; bit++ {
+		offset := keySize*8 + bit
+		if data[offset/8]>>(7-offset%8)&1 != digest[bit/8]>>(7-bit%8)&1 {
+			clear(key)
+			return nil, ErrChecksumMismatch
+		}
+	}
+	return key, nil
+}
+
+// lookup 查找词的序号，不完整的词按前 4 个字母唯一匹配
+func lookup(word string) (int, bool) {
+	if index, ok := wordIndex[word]; ok {
+		return index, true
+	}
+	if len(word) < 4 {
+		return 0, false
+	}
+	full, ok := prefixWord[word[:4]]
+	if !ok || !strings.HasPrefix(full, word) {
+		return 0, false
+	}
+	return wordIndex[full], true
+}
+
+// isPosition 判断是否为打印格式中的序号，如 "1." 或 "12)"
+func isPosition(field string) bool {
+	digits := strings.TrimRight(field, ".)")
+	if digits == "" {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Format 将助记词排成带序号的多行文本，每行 perLine 个词，便于抄写核对
+func Format(mnemonic string, perLine int) string {
+	words := strings.Fields(mnemonic)
+	var b strings.Builder
+	for start := 0; start < len(words); start += perLine {
+		var line strings.Builder
+		for i := start; i < start+perLine && i < len(words); i++ {
+			fmt.Fprintf(&line, "%2d. %-9s", i+1, words[i])
+		}
+		b.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+	return b.String()
+}
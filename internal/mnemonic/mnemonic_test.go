@@ -0,0 +1,84 @@
+package mnemonic
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// BIP39 参考实现的测试向量
+func TestEncodeVectors(t *testing.T) {
+	testCases := []struct {
+		key      string
+		mnemonic string
+	}{
+		{strings.Repeat("00", 16), strings.Repeat("abandon ", 11) + "about"},
+		{strings.Repeat("7f", 16), "legal winner thank year wave sausage worth useful legal winner thank yellow"},
+		{strings.Repeat("80", 16), "letter advice cage absurd amount doctor acoustic avoid letter advice cage above"},
+		{strings.Repeat("ff", 16), strings.Repeat("zoo ", 11) + "wrong"},
+		{strings.Repeat("00", 32), strings.Repeat("abandon ", 23) + "art"},
+		{strings.Repeat("ff", 32), strings.Repeat("zoo ", 23) + "vote"},
+	}
+
+	for _, tc := range testCases {
+		key, _ := hex.DecodeString(tc.key)
+		got, err := Encode(key)
+		if err != nil {
+			t.Fatalf("Encode(%s) failed: %v", tc.key, err)
+		}
+		if got != tc.mnemonic {
+			t.Errorf("Encode(%s) = %q, expected %q", tc.key, got, tc.mnemonic)
+		}
+
+		decoded, err := Decode(tc.mnemonic)
+		if err != nil || !bytes.Equal(decoded, key) {
+			t.Errorf("Decode(%q) = %x, %v", tc.mnemonic, decoded, err)
+		}
+	}
+}
+
+func TestDecodeFormatted(t *testing.T) {
+	key := bytes.Repeat([]byte{0x5a, 0x13, 0xc7, 0x01}, 16)
+	words, err := Encode(key)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	// 打印格式、大小写与 4 字母缩写都能解析
+	formatted := strings.ToUpper(Format(words, 6))
+	decoded, err := Decode(formatted)
+	if err != nil || !bytes.Equal(decoded, key) {
+		t.Fatalf("Decode(Format) = %x, %v", decoded, err)
+	}
+
+	var abbreviated []string
+	for _, word := range strings.Fields(words) {
+		if len(word) > 4 {
+			word = word[:4]
+		}
+		abbreviated = append(abbreviated, word)
+	}
+	if decoded, err := Decode(strings.Join(abbreviated, " ")); err != nil || !bytes.Equal(decoded, key) {
+		t.Errorf("Decode(abbreviated) = %x, %v", decoded, err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	testCases := []struct {
+		mnemonic string
+		expected error
+	}{
+		{strings.Repeat("abandon ", 12), ErrChecksumMismatch},
+		{"legal winner thank year wave sausage worth useful legal winner yellow thank", ErrChecksumMismatch},
+		{strings.Repeat("abandon ", 11) + "abut", ErrUnknownWord},
+		{strings.Repeat("abandon ", 8) + "about", ErrInvalidWordCount},
+	}
+
+	for _, tc := range testCases {
+		if _, err := Decode(tc.mnemonic); !errors.Is(err, tc.expected) {
+			t.Errorf("Decode(%q): got %v, expected %v", tc.mnemonic, err, tc.expected)
+		}
+	}
+}
@@ -3,12 +3,41 @@ package naming
 import (
 	"crypto/rand"
 	"fmt"
+	"hycrypt/internal/constants"
 	"math/big"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 )
 
+var (
+	methodsMu sync.Mutex
+	methods   []string
+	// encryptedNamePattern 解析格式：name-hash-date-method，登记新算法后重新构建
+	encryptedNamePattern *regexp.Regexp
+)
+
+// RegisterMethod 登记可出现在加密文件名中的算法名称，由算法注册表调用
+func RegisterMethod(method string) {
+	methodsMu.Lock()
+	defer methodsMu.Unlock()
+	methods = append(methods, regexp.QuoteMeta(method))
+	encryptedNamePattern = nil
+}
+
+// namePattern 返回当前登记算法对应的文件名正则
+// 从后往前匹配最后的 hash-date-method 部分
+func namePattern() *regexp.Regexp {
+	methodsMu.Lock()
+	defer methodsMu.Unlock()
+	if encryptedNamePattern == nil {
+		encryptedNamePattern = regexp.MustCompile(
+			`^(.+)-([a-z0-9]{6})-(\d{8})-(` + strings.Join(methods, "|") + `)$`)
+	}
+	return encryptedNamePattern
+}
+
 // DefaultStrategyInterface 默认文件命名策略
 type DefaultStrategyInterface struct {
 	dateFormat string
@@ -54,11 +83,7 @@ func (s *DefaultStrategyInterface) ParseEncryptedName(encryptedName string) (ori
 		nameWithoutExt = strings.TrimSuffix(encryptedName, ".zip"+s.extension)
 	}
 
-	// 解析格式：name-hash-date-method
-	// 使用非贪婪匹配，从后往前匹配最后的 hash-date-method 部分
-	pattern := `^(.+)-([a-z0-9]{6})-(\d{8})-(rsa|kmac)$`
-	re := regexp.MustCompile(pattern)
-	matches := re.FindStringSubmatch(nameWithoutExt)
+	matches := namePattern().FindStringSubmatch(nameWithoutExt)
 
 	if len(matches) == 5 {
 		originalName = matches[1]
@@ -83,13 +108,14 @@ func (s *DefaultStrategyInterface) IsEncryptedFile(fileName string) bool {
 		return false
 	}
 
-	// 检查是否包含加密算法标识
-	nameWithoutExt := strings.TrimSuffix(fileName, s.extension)
-	return strings.Contains(nameWithoutExt, "-rsa-") ||
-		strings.Contains(nameWithoutExt, "-kmac-") ||
-		// 兼容旧格式
-		strings.Contains(fileName, ".rsa.") ||
-		strings.Contains(fileName, ".kmac.")
+	// 检查是否包含已登记的加密算法标识
+	if _, method, _, _ := s.ParseEncryptedName(fileName); method != "" {
+		return true
+	}
+
+	// 兼容旧格式
+	return strings.Contains(fileName, "."+constants.AlgorithmRSA+".") ||
+		strings.Contains(fileName, "."+constants.AlgorithmKMAC+".")
 }
 
 // 辅助方法
@@ -41,11 +41,13 @@ type OperationResult struct {
 // ResultDetails 结果详情
 type ResultDetails struct {
 	// 基本信息
-	FileName   string
-	FilePath   string
-	FileSize   int64
-	Algorithm  string
-	OutputPath string
+	FileName    string
+	FilePath    string
+	FileSize    int64
+	Algorithm   string
+	OutputPath  string
+	Signer      string // 已验证的签名者公钥
+	Compression string // 加密前使用的压缩编码
 
 	// 特殊数据
 	HexData       string // 十六进制数据
@@ -329,6 +331,22 @@ func (r *UnifiedRenderer) renderResultDetails(operation string, details *ResultD
 		builder.WriteString(fmt.Sprintf("算法: %s\n", details.Algorithm))
 	}
 
+	// 签名信息
+	if details.Signer != "" {
+		if r.config.UseEmoji {
+			builder.WriteString("✍️  ")
+		}
+		builder.WriteString(fmt.Sprintf("签名者: %s（已验证）\n", details.Signer))
+	}
+
+	// 压缩信息
+	if details.Compression != "" {
+		if r.config.UseEmoji {
+			builder.WriteString("🗜️  ")
+		}
+		builder.WriteString(fmt.Sprintf("压缩: %s\n", details.Compression))
+	}
+
 	// 处理时间
 	if r.config.UseEmoji {
 		builder.WriteString("⏱️  ")
@@ -0,0 +1,310 @@
+// Package pkcs8 实现口令加密的私钥封装（RFC 5958 EncryptedPrivateKeyInfo），
+// 使用 PBES2（RFC 8018）：scrypt（RFC 7914）或 PBKDF2 派生密钥，AES-CBC 或 AES-GCM 加密
+package pkcs8
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrIncorrectPassphrase 口令错误或数据被篡改
+var ErrIncorrectPassphrase = errors.New("pkcs8: incorrect passphrase")
+
+// Cipher 内容加密算法
+type Cipher int
+
+const (
+	// AES256CBC 与 OpenSSL 等工具兼容
+	AES256CBC Cipher = iota
+	// AES256GCM 带认证，可可靠识别错误口令（RFC 5084）
+	AES256GCM
+)
+
+// scrypt 参数：N=2^14, r=8, p=1，约 16 MiB 内存
+// 与 OpenSSL 默认值一致，更大的 N 会超出 OpenSSL 的 32 MiB 内存限制导致无法读取
+const (
+	scryptN      = 1 << 14
+	scryptR      = 8
+	scryptP      = 1
+	saltSize     = 16
+	gcmNonceSize = 12
+	gcmTagSize   = 16
+)
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidScrypt         = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11591, 4, 11}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidAES256GCM      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 46}
+)
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	EncryptionAlgorithm algorithmIdentifier
+	EncryptedData       []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc algorithmIdentifier
+	EncryptionScheme  algorithmIdentifier
+}
+
+type scryptParams struct {
+	Salt            []byte
+	CostParameter   int
+	BlockSize       int
+	Parallelization int
+	KeyLength       int `asn1:"optional"`
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                 `asn1:"optional"`
+	PRF            algorithmIdentifier `asn1:"optional"`
+}
+
+type gcmParams struct {
+	Nonce  []byte
+	ICVLen int `asn1:"default:12"`
+}
+
+// Encrypt 用口令加密数据（通常是 PKCS#8 PrivateKeyInfo），返回 EncryptedPrivateKeyInfo DER
+func Encrypt(data, passphrase []byte, c Cipher) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	kdfParams, err := asn1.Marshal(scryptParams{
+		Salt:            salt,
+		CostParameter:   scryptN,
+		BlockSize:       scryptR,
+		Parallelization: scryptP,
+		KeyLength:       32,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var scheme algorithmIdentifier
+	var encrypted []byte
+	switch c {
+	case AES256CBC:
+		iv := make([]byte, aes.BlockSize)
+		if _, err := rand.Read(iv); err != nil {
+			return nil, err
+		}
+		ivParams, err := asn1.Marshal(iv)
+		if err != nil {
+			return nil, err
+		}
+		scheme = algorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParams}}
+
+		// PKCS#7 填充
+		padding := aes.BlockSize - len(data)%aes.BlockSize
+		encrypted = make([]byte, len(data)+padding)
+		copy(encrypted, data)
+		for i := len(data); i < len(encrypted); i++ {
+			encrypted[i] = byte(padding)
+		}
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+	case AES256GCM:
+		nonce := make([]byte, gcmNonceSize)
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		nonceParams, err := asn1.Marshal(gcmParams{Nonce: nonce, ICVLen: gcmTagSize})
+		if err != nil {
+			return nil, err
+		}
+		scheme = algorithmIdentifier{Algorithm: oidAES256GCM, Parameters: asn1.RawValue{FullBytes: nonceParams}}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		encrypted = aead.Seal(nil, nonce, data, nil)
+	default:
+		return nil, fmt.Errorf("pkcs8: unsupported cipher %d", c)
+	}
+
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: algorithmIdentifier{Algorithm: oidScrypt, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  scheme,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		EncryptionAlgorithm: algorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData:       encrypted,
+	})
+}
+
+// Decrypt 用口令解密 EncryptedPrivateKeyInfo DER，支持 scrypt、PBKDF2 与 AES-CBC、AES-256-GCM
+func Decrypt(der, passphrase []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) > 0 {
+		return nil, errors.New("pkcs8: invalid encrypted private key")
+	}
+	if !info.EncryptionAlgorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("pkcs8: unsupported encryption algorithm %v", info.EncryptionAlgorithm.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.EncryptionAlgorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, errors.New("pkcs8: invalid PBES2 parameters")
+	}
+
+	scheme := params.EncryptionScheme
+	var keySize int
+	switch {
+	case scheme.Algorithm.Equal(oidAES128CBC):
+		keySize = 16
+	case scheme.Algorithm.Equal(oidAES192CBC):
+		keySize = 24
+	case scheme.Algorithm.Equal(oidAES256CBC), scheme.Algorithm.Equal(oidAES256GCM):
+		keySize = 32
+	default:
+		return nil, fmt.Errorf("pkcs8: unsupported encryption scheme %v", scheme.Algorithm)
+	}
+
+	key, err := deriveKey(params.KeyDerivationFunc, passphrase, keySize)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if scheme.Algorithm.Equal(oidAES256GCM) {
+		var gcm gcmParams
+		if _, err := asn1.Unmarshal(scheme.Parameters.FullBytes, &gcm); err != nil {
+			return nil, errors.New("pkcs8: invalid GCM parameters")
+		}
+		aead, err := cipher.NewGCMWithNonceSize(block, len(gcm.Nonce))
+		if err != nil || gcm.ICVLen != gcmTagSize {
+			return nil, errors.New("pkcs8: unsupported GCM parameters")
+		}
+		plaintext, err := aead.Open(nil, gcm.Nonce, info.EncryptedData, nil)
+		if err != nil {
+			return nil, ErrIncorrectPassphrase
+		}
+		return plaintext, nil
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(scheme.Parameters.FullBytes, &iv); err != nil || len(iv) != aes.BlockSize {
+		return nil, errors.New("pkcs8: invalid CBC parameters")
+	}
+	encrypted := info.EncryptedData
+	if len(encrypted) == 0 || len(encrypted)%aes.BlockSize != 0 {
+		return nil, errors.New("pkcs8: invalid encrypted data length")
+	}
+
+	plaintext := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, encrypted)
+
+	// 校验 PKCS#7 填充，错误口令时几乎总是失败
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, ErrIncorrectPassphrase
+	}
+	valid := 1
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		valid &= subtle.ConstantTimeByteEq(b, byte(padding))
+	}
+	if valid != 1 {
+		return nil, ErrIncorrectPassphrase
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
+
+// IsEncrypted 检查 DER 是否为 PBES2 加密的 EncryptedPrivateKeyInfo
+func IsEncrypted(der []byte) bool {
+	var info encryptedPrivateKeyInfo
+	_, err := asn1.Unmarshal(der, &info)
+	return err == nil && info.EncryptionAlgorithm.Algorithm.Equal(oidPBES2)
+}
+
+// deriveKey 按 PBES2 密钥派生参数派生加密密钥
+func deriveKey(kdf algorithmIdentifier, passphrase []byte, keySize int) ([]byte, error) {
+	switch {
+	case kdf.Algorithm.Equal(oidScrypt):
+		var params scryptParams
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &params); err != nil {
+			return nil, errors.New("pkcs8: invalid scrypt parameters")
+		}
+		if params.KeyLength != 0 && params.KeyLength != keySize {
+			return nil, errors.New("pkcs8: scrypt key length mismatch")
+		}
+		// 限制参数，防止恶意文件耗尽内存
+		if params.CostParameter > 1<<20 || params.BlockSize > 32 || params.Parallelization > 16 {
+			return nil, errors.New("pkcs8: scrypt parameters too large")
+		}
+		return scrypt.Key(passphrase, params.Salt, params.CostParameter, params.BlockSize, params.Parallelization, keySize)
+	case kdf.Algorithm.Equal(oidPBKDF2):
+		var params pbkdf2Params
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &params); err != nil {
+			return nil, errors.New("pkcs8: invalid PBKDF2 parameters")
+		}
+		if params.KeyLength != 0 && params.KeyLength != keySize {
+			return nil, errors.New("pkcs8: PBKDF2 key length mismatch")
+		}
+		if params.IterationCount <= 0 || params.IterationCount > 10_000_000 {
+			return nil, errors.New("pkcs8: invalid PBKDF2 iteration count")
+		}
+
+		var prf func() hash.Hash
+		switch {
+		case len(params.PRF.Algorithm) == 0, params.PRF.Algorithm.Equal(oidHMACWithSHA1):
+			prf = sha1.New
+		case params.PRF.Algorithm.Equal(oidHMACWithSHA256):
+			prf = sha256.New
+		case params.PRF.Algorithm.Equal(oidHMACWithSHA512):
+			prf = sha512.New
+		default:
+			return nil, fmt.Errorf("pkcs8: unsupported PBKDF2 PRF %v", params.PRF.Algorithm)
+		}
+		return pbkdf2.Key(passphrase, params.Salt, params.IterationCount, keySize, prf), nil
+	default:
+		return nil, fmt.Errorf("pkcs8: unsupported key derivation function %v", kdf.Algorithm)
+	}
+}
@@ -0,0 +1,124 @@
+// Package shamir 实现 GF(2^8) 上的 Shamir 秘密共享：任意 threshold 个分片可恢复秘密，
+// 少于 threshold 个分片不泄露秘密的任何信息
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// MaxShares 分片横坐标为 1..255
+const MaxShares = 255
+
+var (
+	// ErrTooFewShares 分片数量少于门限
+	ErrTooFewShares = errors.New("shamir: not enough shares")
+	// ErrDuplicateShare 分片序号重复
+	ErrDuplicateShare = errors.New("shamir: duplicate share index")
+)
+
+// Split 将 secret 拆分为 total 个分片，任意 threshold 个可恢复；返回的第 i 个分片序号为 i+1
+func Split(secret []byte, threshold, total int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("shamir: empty secret")
+	}
+	if threshold < 2 || threshold > total || total > MaxShares {
+		return nil, fmt.Errorf("shamir: invalid threshold %d of %d shares (need 2 <= threshold <= shares <= %d)", threshold, total, MaxShares)
+	}
+
+	// 每个字节一个 threshold-1 次随机多项式，常数项为秘密字节
+	coefficients := make([]byte, threshold)
+	defer clear(coefficients)
+
+	shares := make([][]byte, total)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret))
+	}
+	for j, b := range secret {
+		coefficients[0] = b
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		for i := range shares {
+			shares[i][j] = evaluate(coefficients, byte(i+1))
+		}
+	}
+	return shares, nil
+}
+
+// Combine 用拉格朗日插值在 x=0 处恢复秘密，indices[i] 为 shares[i] 的序号
+// 调用方需保证分片数量不少于拆分时的门限，否则结果是错误的秘密
+func Combine(indices []byte, shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 || len(indices) != len(shares) {
+		return nil, ErrTooFewShares
+	}
+	size := len(shares[0])
+	seen := make(map[byte]bool, len(indices))
+	for i, x := range indices {
+		if x == 0 {
+			return nil, fmt.Errorf("shamir: invalid share index 0")
+		}
+		if seen[x] {
+			return nil, ErrDuplicateShare
+		}
+		seen[x] = true
+		if len(shares[i]) != size {
+			return nil, fmt.Errorf("shamir: share length mismatch")
+		}
+	}
+
+	// 拉格朗日基在 x=0 处的值: l_i = Π x_m / (x_m - x_i)，GF(2^8) 中减法即异或
+	basis := make([]byte, len(indices))
+	for i, xi := range indices {
+		numerator, denominator := byte(1), byte(1)
+		for m, xm := range indices {
+			if m == i {
+				continue
+			}
+			numerator = mul(numerator, xm)
+			denominator = mul(denominator, xm^xi)
+		}
+		basis[i] = mul(numerator, inverse(denominator))
+	}
+
+	secret := make([]byte, size)
+	for j := range secret {
+		var value byte
+		for i := range shares {
+			value ^= mul(shares[i][j], basis[i])
+		}
+		secret[j] = value
+	}
+	return secret, nil
+}
+
+// evaluate 霍纳法求多项式在 x 处的值
+func evaluate(coefficients []byte, x byte) byte {
+	var result byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = mul(result, x) ^ coefficients[i]
+	}
+	return result
+}
+
+// mul GF(2^8) 乘法（AES 多项式 x^8+x^4+x^3+x+1），不依赖秘密数据分支或查表
+func mul(a, b byte) byte {
+	var product byte
+	for i := 0; i < 8; i++ {
+		product ^= a & -(b & 1)
+		a = a<<1 ^ (0x1b & -(a >> 7))
+		b >>= 1
+	}
+	return product
+}
+
+// inverse GF(2^8) 乘法逆元: a^254
+func inverse(a byte) byte {
+	result := byte(1)
+	for i := 0; i < 7; i++ {
+		a = mul(a, a)
+		result = mul(result, a)
+	}
+	return result
+}
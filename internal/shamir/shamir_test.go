@@ -0,0 +1,99 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	testCases := []struct {
+		threshold int
+		total     int
+	}{
+		{2, 2},
+		{2, 3},
+		{3, 5},
+		{5, 5},
+	}
+
+	for _, tc := range testCases {
+		shares, err := Split(secret, tc.threshold, tc.total)
+		if err != nil {
+			t.Fatalf("Split(%d, %d) failed: %v", tc.threshold, tc.total, err)
+		}
+
+		// 任意 threshold 个连续分片（含回绕）都能恢复
+		for start := 0; start < tc.total; start++ {
+			indices := make([]byte, tc.threshold)
+			parts := make([][]byte, tc.threshold)
+			for i := range indices {
+				n := (start + i) % tc.total
+				indices[i] = byte(n + 1)
+				parts[i] = shares[n]
+			}
+			recovered, err := Combine(indices, parts)
+			if err != nil {
+				t.Fatalf("Combine failed: %v", err)
+			}
+			if !bytes.Equal(recovered, secret) {
+				t.Errorf("%d-of-%d from share %d: got %q", tc.threshold, tc.total, start+1, recovered)
+			}
+		}
+	}
+}
+
+func TestSplitRejectsInvalidParameters(t *testing.T) {
+	for _, tc := range [][2]int{{1, 3}, {4, 3}, {2, 256}} {
+		if _, err := Split([]byte("x"), tc[0], tc[1]); err == nil {
+			t.Errorf("Split(%d, %d) accepted", tc[0], tc[1])
+		}
+	}
+}
+
+func TestInverse(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		if got := mul(byte(a), inverse(byte(a))); got != 1 {
+			t.Fatalf("%d * inverse(%d) = %d", a, a, got)
+		}
+	}
+}
+
+func TestShareEncoding(t *testing.T) {
+	secret := []byte("kmac key material")
+	shares, err := SplitShares(secret, "kmac", "kmac.key", 2, 3)
+	if err != nil {
+		t.Fatalf("SplitShares failed: %v", err)
+	}
+
+	var text []byte
+	for _, share := range shares[1:] {
+		text = append(text, share.Encode()...)
+	}
+	parsed, err := ParseShares(text)
+	if err != nil {
+		t.Fatalf("ParseShares failed: %v", err)
+	}
+	recovered, err := CombineShares(parsed)
+	if err != nil || !bytes.Equal(recovered, secret) {
+		t.Fatalf("CombineShares failed: %v", err)
+	}
+
+	if _, err := CombineShares(parsed[:1]); !errors.Is(err, ErrTooFewShares) {
+		t.Errorf("single share: got %v, expected ErrTooFewShares", err)
+	}
+
+	// 修改头部中的序号必须被校验和发现
+	tampered := strings.Replace(string(shares[0].Encode()), "Share: 1/3", "Share: 2/3", 1)
+	if _, err := ParseShares([]byte(tampered)); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("tampered share: got %v, expected ErrChecksumMismatch", err)
+	}
+
+	other, _ := SplitShares(secret, "kmac", "kmac.key", 2, 3)
+	if _, err := CombineShares([]*Share{shares[0], other[1]}); err == nil {
+		t.Error("shares from different splits combined")
+	}
+}
@@ -0,0 +1,169 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SharePEMType 可打印分片的 PEM 类型
+const SharePEMType = "HYCRYPT KEY SHARE"
+
+// ErrChecksumMismatch 分片内容与校验和不符（抄写错误或被篡改）
+var ErrChecksumMismatch = errors.New("shamir: share checksum mismatch")
+
+// Share 带元数据的分片，同一次拆分的分片 ID 相同
+type Share struct {
+	ID        string
+	Kind      string // 秘密类型，如 kmac、rsa
+	Name      string // 原始文件名，仅供参考
+	Index     int
+	Threshold int
+	Total     int
+	Data      []byte
+}
+
+// SplitShares 拆分秘密并为分片生成随机 ID
+func SplitShares(secret []byte, kind, name string, threshold, total int) ([]*Share, error) {
+	parts, err := Split(secret, threshold, total)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	shares := make([]*Share, total)
+	for i, data := range parts {
+		shares[i] = &Share{
+			ID:        hex.EncodeToString(id),
+			Kind:      kind,
+			Name:      name,
+			Index:     i + 1,
+			Threshold: threshold,
+			Total:     total,
+			Data:      data,
+		}
+	}
+	return shares, nil
+}
+
+// CombineShares 校验分片属于同一次拆分且数量达到门限后恢复秘密
+func CombineShares(shares []*Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrTooFewShares
+	}
+
+	first := shares[0]
+	indices := make([]byte, 0, len(shares))
+	parts := make([][]byte, 0, len(shares))
+	seen := make(map[int]bool, len(shares))
+	for _, share := range shares {
+		if share.ID != first.ID || share.Kind != first.Kind || share.Threshold != first.Threshold || share.Total != first.Total {
+			return nil, fmt.Errorf("shamir: share %d belongs to a different split (id %s, expected %s)", share.Index, share.ID, first.ID)
+		}
+		// 同一分片重复提供时只使用一次
+		if seen[share.Index] {
+			continue
+		}
+		seen[share.Index] = true
+		indices = append(indices, byte(share.Index))
+		parts = append(parts, share.Data)
+	}
+
+	if len(parts) < first.Threshold {
+		return nil, fmt.Errorf("%w: have %d, need %d", ErrTooFewShares, len(parts), first.Threshold)
+	}
+	return Combine(indices, parts)
+}
+
+// Encode 编码为带校验和的 PEM 文本，便于打印和抄写
+func (s *Share) Encode() []byte {
+	block := &pem.Block{
+		Type: SharePEMType,
+		Headers: map[string]string{
+			"Id":        s.ID,
+			"Kind":      s.Kind,
+			"Share":     fmt.Sprintf("%d/%d", s.Index, s.Total),
+			"Threshold": strconv.Itoa(s.Threshold),
+			"Checksum":  s.checksum(),
+		},
+		Bytes: s.Data,
+	}
+	if s.Name != "" {
+		block.Headers["Name"] = s.Name
+	}
+	return pem.EncodeToMemory(block)
+}
+
+// checksum 覆盖元数据与分片数据的 SHA-256 前 4 字节
+func (s *Share) checksum() string {
+	digest := sha256.New()
+	fmt.Fprintf(digest, "%s\n%s\n%s\n%d\n%d\n%d\n", s.ID, s.Kind, s.Name, s.Index, s.Threshold, s.Total)
+	digest.Write(s.Data)
+	return hex.EncodeToString(digest.Sum(nil)[:4])
+}
+
+// ParseShares 解析文本中的全部分片并校验校验和，非分片的 PEM 块与其他文本被忽略
+func ParseShares(data []byte) ([]*Share, error) {
+	var shares []*Share
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != SharePEMType {
+			continue
+		}
+		share, err := parseShare(block)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+
+	if len(shares) == 0 && len(bytes.TrimSpace(data)) > 0 {
+		return nil, fmt.Errorf("shamir: no %s block found", SharePEMType)
+	}
+	return shares, nil
+}
+
+// parseShare 解析单个分片块
+func parseShare(block *pem.Block) (*Share, error) {
+	share := &Share{
+		ID:   block.Headers["Id"],
+		Kind: block.Headers["Kind"],
+		Name: block.Headers["Name"],
+		Data: block.Bytes,
+	}
+
+	index, total, ok := strings.Cut(block.Headers["Share"], "/")
+	var err error
+	if ok {
+		share.Index, err = strconv.Atoi(index)
+		if err == nil {
+			share.Total, err = strconv.Atoi(total)
+		}
+		if err == nil {
+			share.Threshold, err = strconv.Atoi(block.Headers["Threshold"])
+		}
+	}
+	if !ok || err != nil || share.ID == "" || share.Index < 1 || share.Index > share.Total ||
+		share.Total > MaxShares || share.Threshold < 2 || share.Threshold > share.Total {
+		return nil, fmt.Errorf("shamir: invalid share header")
+	}
+
+	if share.checksum() != block.Headers["Checksum"] {
+		return nil, fmt.Errorf("%w (share %d)", ErrChecksumMismatch, share.Index)
+	}
+	return share, nil
+}
@@ -8,8 +8,8 @@ import (
 	"path/filepath"
 )
 
-// zipDirectory 将目录压缩为 zip 文件
-func ZipDirectory(sourceDir, zipFilePath string) error {
+// zipDirectory 将目录压缩为 zip 文件，method 为条目的压缩方式（zip.Deflate 或 zip.Store）
+func ZipDirectory(sourceDir, zipFilePath string, method uint16) error {
 	// 创建 zip 文件
 	zipFile, err := os.Create(zipFilePath)
 	if err != nil {
@@ -39,7 +39,10 @@ func ZipDirectory(sourceDir, zipFilePath string) error {
 		}
 
 		// 在 zip 中创建文件
-		zipFileWriter, err := zipWriter.Create(relPath)
+		zipFileWriter, err := zipWriter.CreateHeader(&zip.FileHeader{
+			Name:   filepath.ToSlash(relPath),
+			Method: method,
+		})
 		if err != nil {
 			return fmt.Errorf("在 zip 中创建文件失败: %w", err)
 		}
@@ -119,7 +122,7 @@ func IsDirectory(path string) bool {
 }
 
 // createTempZipFile 为目录创建临时 zip 文件
-func CreateTempZipFile(dirPath string) (string, error) {
+func CreateTempZipFile(dirPath string, method uint16) (string, error) {
 	// 获取目录名
 	dirName := filepath.Base(dirPath)
 
@@ -129,7 +132,7 @@ func CreateTempZipFile(dirPath string) (string, error) {
 	tempZipPath := filepath.Join(tempDir, fmt.Sprintf("crypto-zip-%s-%s.zip", dirName, timestamp))
 
 	// 压缩目录
-	if err := ZipDirectory(dirPath, tempZipPath); err != nil {
+	if err := ZipDirectory(dirPath, tempZipPath, method); err != nil {
 		return "", fmt.Errorf("压缩目录失败: %w", err)
 	}
 
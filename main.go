@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"hycrypt/internal/app"
 	"hycrypt/internal/config"
+	"hycrypt/internal/crypto"
 	"hycrypt/internal/errors"
 	"hycrypt/internal/utils"
 	"os"
+	"strings"
 )
 
 func main() {
@@ -37,9 +39,52 @@ func main() {
 		os.Exit(1)
 	}
 
+	// 命名密钥对应密钥环下的目录，名称需先校验
+	if opts.Key != "" {
+		if err := config.ValidateKeyName(opts.Key); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// 应用命令行覆盖
 	cfg.ApplyOverrides(opts)
 
+	// 输出 age 格式的 X25519 公钥，供 age 工具加密给本机
+	if opts.ShowAgeRecipient {
+		recipient, err := crypto.X25519AgeRecipient(cfg.GetX25519PublicKeyPath())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(recipient)
+		return
+	}
+
+	// 转换为app.Options
+	appOpts := &app.Options{
+		FilePath:     opts.FilePath,
+		TextMode:     opts.TextMode,
+		OutputDir:    opts.OutputDir,
+		OutputFormat: opts.OutputFormat,
+		InputFormat:  opts.InputFormat,
+		Method:       opts.Method,
+		Key:          opts.Key,
+		Decrypt:      opts.Decrypt,
+		Verbose:      opts.Verbose,
+		Offset:       opts.Offset,
+		Length:       opts.Length,
+	}
+
+	// 子命令（如 keys）不进入加解密流程
+	if len(opts.Command) > 0 {
+		if err := app.RunCommand(cfg, appOpts, opts.Command); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// 显示ASCII艺术
 	if !opts.NoArt {
 		showASCII(opts.Interactive)
@@ -58,17 +103,6 @@ func main() {
 	if opts.Interactive {
 		err = application.RunInteractive(ctx)
 	} else {
-		// 转换为app.Options
-		appOpts := &app.Options{
-			FilePath:     opts.FilePath,
-			TextMode:     opts.TextMode,
-			OutputDir:    opts.OutputDir,
-			OutputFormat: opts.OutputFormat,
-			InputFormat:  opts.InputFormat,
-			Method:       opts.Method,
-			Decrypt:      opts.Decrypt,
-			Verbose:      opts.Verbose,
-		}
 		err = application.RunCLI(ctx, appOpts)
 	}
 
@@ -83,20 +117,34 @@ func main() {
 }
 
 type Options struct {
-	ConfigPath     string
-	FilePath       string
-	TextMode       bool
-	OutputDir      string
-	OutputFormat   string
-	InputFormat    string
-	KeyDir         string
-	Method         string
-	Decrypt        bool
-	Verbose        bool
-	GenerateConfig bool
-	ShowHelp       bool
-	NoArt          bool
-	Interactive    bool
+	ConfigPath       string
+	FilePath         string
+	TextMode         bool
+	OutputDir        string
+	OutputFormat     string
+	InputFormat      string
+	KeyDir           string
+	Method           string
+	Key              string
+	AEAD             string
+	Padding          string
+	Compression      string
+	CompressionLevel int
+	Workers          int
+	Recipients       stringList
+	AgeRecipients    stringList
+	AgeIdentities    stringList
+	Sign             bool
+	Decrypt          bool
+	Verbose          bool
+	Offset           int64
+	Length           int64
+	GenerateConfig   bool
+	ShowHelp         bool
+	ShowAgeRecipient bool
+	NoArt            bool
+	Interactive      bool
+	Command          []string // 子命令及其参数，如 keys protect rsa
 }
 
 // GetKeyDir implements config.CLIOptions interface
@@ -109,11 +157,73 @@ func (o *Options) GetMethod() string {
 	return o.Method
 }
 
+// GetKey implements config.CLIOptions interface
+func (o *Options) GetKey() string {
+	return o.Key
+}
+
+// GetAEAD implements config.CLIOptions interface
+func (o *Options) GetAEAD() string {
+	return o.AEAD
+}
+
+// GetPadding implements config.CLIOptions interface
+func (o *Options) GetPadding() string {
+	return o.Padding
+}
+
+// GetCompression implements config.CLIOptions interface
+func (o *Options) GetCompression() string {
+	return o.Compression
+}
+
+// GetCompressionLevel implements config.CLIOptions interface
+func (o *Options) GetCompressionLevel() int {
+	return o.CompressionLevel
+}
+
+// GetWorkers implements config.CLIOptions interface
+func (o *Options) GetWorkers() int {
+	return o.Workers
+}
+
+// GetRecipients implements config.CLIOptions interface
+func (o *Options) GetRecipients() []string {
+	return o.Recipients
+}
+
+// GetAgeRecipients implements config.CLIOptions interface
+func (o *Options) GetAgeRecipients() []string {
+	return o.AgeRecipients
+}
+
+// GetAgeIdentities implements config.CLIOptions interface
+func (o *Options) GetAgeIdentities() []string {
+	return o.AgeIdentities
+}
+
+// GetSign implements config.CLIOptions interface
+func (o *Options) GetSign() bool {
+	return o.Sign
+}
+
 // GetVerbose implements config.CLIOptions interface
 func (o *Options) GetVerbose() bool {
 	return o.Verbose
 }
 
+// stringList 可重复指定的字符串参数
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 func parseFlags() *Options {
 	opts := &Options{}
 
@@ -121,33 +231,61 @@ func parseFlags() *Options {
 	flag.StringVar(&opts.FilePath, "f", "", "要处理的文件或文件夹路径")
 	flag.BoolVar(&opts.TextMode, "t", false, "文本输入模式")
 	flag.StringVar(&opts.OutputDir, "output", "", "输出目录")
-	flag.StringVar(&opts.OutputFormat, "output-format", "file", "输出格式: file 或 hex")
-	flag.StringVar(&opts.InputFormat, "input-format", "file", "输入格式: file 或 hex")
+	flag.StringVar(&opts.OutputFormat, "output-format", "file", "输出格式: file、hex、age、jwe 或 jwe-json（age 仅支持 x25519 与 password，jwe 仅支持 rsa 与 kmac 的文本加密）")
+	flag.StringVar(&opts.InputFormat, "input-format", "file", "输入格式: file、hex 或 jwe")
 	flag.StringVar(&opts.KeyDir, "key-dir", "", "密钥文件夹路径")
-	flag.StringVar(&opts.Method, "method", "", "加密方法: rsa 或 kmac")
+	flag.StringVar(&opts.Method, "method", "", "加密方法: "+strings.Join(crypto.AlgorithmNames(), "、"))
 	methodShort := flag.String("m", "", "加密方法（简写）")
+	flag.StringVar(&opts.Key, "key", "", "使用密钥环中的命名密钥（default 为密钥目录顶层的密钥），解密时默认按文件头部指纹自动选择")
+	flag.StringVar(&opts.AEAD, "aead", "", "数据加密算法: aes-gcm、chacha20-poly1305 或 xchacha20-poly1305（解密时自动识别）")
+	flag.StringVar(&opts.Padding, "padding", "", "长度隐藏填充: none、padme 或 bucket（解密时自动去除）")
+	flag.StringVar(&opts.Compression, "compress", "", "加密前压缩: none、gzip 或 zstd（已压缩的文件自动跳过，解密时自动解压）")
+	flag.IntVar(&opts.CompressionLevel, "compress-level", 0, "压缩级别: gzip 1-9，zstd 1-22（0 为默认级别）")
+	flag.IntVar(&opts.Workers, "workers", 0, "分块并发加解密的线程数（0 为 CPU 核数）")
+	flag.Var(&opts.Recipients, "recipient", "额外的 RSA 接收方公钥文件（PEM 或 ssh-rsa 公钥行），可重复指定（仅 rsa 方法）")
+	flag.Var(&opts.AgeRecipients, "age-recipient", "额外的 age 接收方（age1... 公钥或接收方文件），可重复指定")
+	flag.Var(&opts.AgeIdentities, "age-identity", "age 身份文件（AGE-SECRET-KEY-1...），解密 age 文件时使用，可重复指定")
+	flag.BoolVar(&opts.ShowAgeRecipient, "show-age-recipient", false, "输出本机 X25519 公钥的 age1... 形式")
+	flag.BoolVar(&opts.Sign, "sign", false, "使用 Ed25519 签名密钥对加密内容签名")
 	flag.BoolVar(&opts.Decrypt, "d", false, "解密模式")
 	flag.BoolVar(&opts.Verbose, "verbose", false, "详细输出模式")
+	flag.Int64Var(&opts.Offset, "offset", 0, "cat 命令读取的明文起始偏移（字节）")
+	flag.Int64Var(&opts.Length, "length", -1, "cat 命令读取的明文字节数（-1 表示读到结尾）")
 	flag.BoolVar(&opts.GenerateConfig, "gen-config", false, "生成默认配置文件")
 	flag.BoolVar(&opts.ShowHelp, "help", false, "显示帮助信息")
 	flag.BoolVar(&opts.NoArt, "no-art", false, "跳过ASCII动画")
 
 	flag.Parse()
 
+	// 子命令参数之间仍可出现选项，如 hycrypt keys protect rsa -key-dir=keys
+	for args := flag.Args(); len(args) > 0; args = flag.Args() {
+		opts.Command = append(opts.Command, args[0])
+		flag.CommandLine.Parse(args[1:])
+	}
+
 	// 处理方法简写
 	if *methodShort != "" {
 		opts.Method = *methodShort
 	}
 
 	// 判断是否为交互模式
-	opts.Interactive = !opts.GenerateConfig && opts.FilePath == "" && !opts.TextMode && !opts.Decrypt
+	opts.Interactive = len(opts.Command) == 0 && !opts.GenerateConfig && !opts.ShowAgeRecipient && opts.FilePath == "" && !opts.TextMode && !opts.Decrypt
 
 	return opts
 }
 
 func showUsage() {
-	fmt.Fprintf(os.Stderr, "用法: %s [选项]\n\n", os.Args[0])
-	fmt.Fprintf(os.Stderr, "HyCrypt - 混合加密程序，支持 RSA & KMAC 算法\n\n")
+	fmt.Fprintf(os.Stderr, "用法: %s [选项]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "      %s keys list\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "      %s keys generate <rsa|kmac|x25519|mlkem> <名称>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "      %s keys <protect|passwd|unprotect> <rsa|kmac|密钥文件路径>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "      %s keys split <rsa|kmac|密钥文件路径> <门限> <份数>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "      %s keys combine <rsa|kmac|密钥文件路径> [分片文件...]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "      %s keys <export-mnemonic|import-mnemonic> kmac\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "      %s rekey <文件或目录...>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "      %s verify <文件或目录...>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "      %s cat <文件> [-offset=字节] [-length=字节]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "HyCrypt - 混合加密程序，支持 RSA、KMAC、X25519、ML-KEM 与口令加密\n\n")
 	fmt.Fprintf(os.Stderr, "选项:\n")
 	flag.PrintDefaults()
 
@@ -158,13 +296,49 @@ func showUsage() {
 		"\n文件加密:",
 		"  hycrypt -f=myfile.txt                 # RSA 加密文件",
 		"  hycrypt -m=kmac -f=myfile.txt         # KMAC 加密文件",
+		"  hycrypt -m=x25519 -f=myfile.txt       # X25519 加密文件",
+		"  hycrypt -m=mlkem -f=wallet.txt        # ML-KEM-768 + X25519 抗量子加密",
+		"  hycrypt -m=password -f=seed.txt       # 口令加密（Argon2id，无需密钥文件）",
+		"  hycrypt -aead=xchacha20-poly1305 -f=myfile.txt  # 使用 XChaCha20-Poly1305",
+		"  hycrypt -m=kmac -padding=bucket -t      # 填充到 1 KiB 起的 2 的幂次，隐藏助记词长度",
+		"  hycrypt -compress=zstd -f=logs.tar       # 先用 zstd 压缩再加密",
+		"  hycrypt -workers=8 -f=disk.img          # 限制为 8 个线程并发加密分块",
+		"  hycrypt -f=backup.tar -recipient=bob.pem -recipient=carol.pem  # 加密给多个 RSA 接收方",
+		"  hycrypt -sign -f=report.pdf           # 签名后加密，解密时验证签名者",
+		"  hycrypt -m=x25519 -output-format=age -age-recipient=age1... -f=photo.jpg  # 输出 age 文件",
+		"  hycrypt -m=password -output-format=age -f=notes.txt  # age 口令加密（scrypt）",
 		"  hycrypt -f=myfolder                   # 加密文件夹",
 		"\n文本加密:",
 		"  echo \"secret\" | hycrypt -t           # 文本加密",
 		"  hycrypt -t --output-format=hex        # 输出十六进制",
+		"  echo \"hello\" | hycrypt -t -m=rsa -output-format=jwe  # 输出 JWE 紧凑格式",
+		"\n密钥指纹:",
+		"  hycrypt keys list                     # 列出密钥文件及其指纹",
+		"\n密钥环:",
+		"  hycrypt keys generate x25519 work     # 在密钥环中生成名为 work 的 X25519 密钥",
+		"  hycrypt -m=x25519 -key=work -f=plan.txt  # 使用命名密钥加密",
+		"  hycrypt -d -f=plan.hycrypt            # 按文件头部指纹自动选择密钥解密",
+		"\n密钥轮换:",
+		"  hycrypt keys generate rsa 2027 && hycrypt rekey -key=2027 encrypted/  # 为新 RSA 密钥重新封装数据密钥，载荷不变",
+		"\n完整性校验:",
+		"  hycrypt verify encrypted/             # 认证目录中所有加密文件，不写出明文",
+		"\n随机访问解密:",
+		"  hycrypt cat video.hycrypt -offset=1048576 -length=4096  # 只解密覆盖该范围的分块，输出到标准输出",
+		"\n密钥口令:",
+		"  hycrypt keys protect rsa              # 为 RSA 私钥设置口令（加密 PKCS#8，scrypt）",
+		"  hycrypt keys passwd kmac              # 修改 KMAC 密钥口令",
+		"  hycrypt keys unprotect rsa            # 移除私钥口令",
+		"\n密钥分片（Shamir）:",
+		"  hycrypt keys split kmac 3 5 -output=shares  # 拆分为 5 个分片，任意 3 个可恢复",
+		"  hycrypt keys combine kmac a.txt b.txt c.txt  # 合并分片恢复 KMAC 密钥",
+		"\n助记词备份:",
+		"  hycrypt keys export-mnemonic kmac     # 以 BIP39 助记词显示 KMAC 密钥",
+		"  hycrypt keys import-mnemonic kmac < words.txt  # 校验助记词后恢复 KMAC 密钥",
 		"\n解密:",
 		"  hycrypt -d -f=file.encrypted          # 解密文件",
+		"  hycrypt -d -f=photo.jpg.age -age-identity=key.txt  # 解密 age 文件（自动识别）",
 		"  echo \"hex...\" | hycrypt -d -t --input-format=hex  # 十六进制解密",
+		"  echo \"eyJ...\" | hycrypt -d -t -input-format=jwe  # JWE 解密（根据 alg 选择方法）",
 	}
 
 	for _, example := range examples {